@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	// Incluye la base de zonas horarias para no depender de la del sistema
+	_ "time/tzdata"
+
+	"github.com/joho/godotenv"
+)
+
+// ArchivoPorDefecto es el archivo de configuración que se lee si CONFIG_FILE no indica otro
+const ArchivoPorDefecto = ".env"
+
+// Drivers de base de datos soportados
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Entornos en los que se ejecuta la aplicación
+const (
+	EntornoDesarrollo = "development"
+	EntornoPruebas    = "test"
+	EntornoStaging    = "staging"
+	EntornoProduccion = "production"
+)
+
+// SQLiteEnMemoria es la ruta de SQLite que crea una base de datos en memoria
+const SQLiteEnMemoria = ":memory:"
+
+// ErrJWTSecretoFaltante indica que no se configuró JWT_SECRET
+var ErrJWTSecretoFaltante = errors.New("la variable JWT_SECRET es obligatoria")
+
+// Config reúne toda la configuración de la aplicación
+type Config struct {
+	Entorno            string // development, test, staging o production
+	EntornoDefinido    bool   // APP_ENV está definida; si no, Entorno es development por defecto
+	Puerto             string
+	OrigenesPermitidos []string
+	BaseDatos          BaseDatos
+	JWT                JWT
+	Arbitros           Arbitros
+	Liga               Liga
+}
+
+// BaseDatos contiene los datos de conexión y el tamaño del pool de conexiones
+type BaseDatos struct {
+	Driver                 string // postgres o sqlite
+	RutaSQLite             string // Archivo de SQLite, o :memory: para una base en memoria
+	Host                   string
+	Puerto                 string
+	Usuario                string
+	Password               string
+	Nombre                 string
+	SSLMode                string
+	MaxConexionesAbiertas  int
+	MaxConexionesInactivas int
+	VidaMaximaConexion     time.Duration
+	DirectorioMigraciones  string // Carpeta con una subcarpeta de migraciones por driver
+	MigrarAlIniciar        bool   // Aplicar las migraciones pendientes al arrancar el servidor
+}
+
+// JWT contiene el secreto con el que se firman los tokens y su duración
+type JWT struct {
+	Secreto    []byte
+	Expiracion time.Duration
+}
+
+// Arbitros contiene las reglas de designación de árbitros que dependen del torneo
+type Arbitros struct {
+	RestringirCiudad bool // Un árbitro no puede dirigir partidos de equipos de su ciudad
+}
+
+// Liga contiene las reglas del calendario que dependen de dónde se juega el torneo
+type Liga struct {
+	ZonaHoraria *time.Location // Zona en la que se cuentan los días del calendario
+}
+
+// DSN devuelve la cadena de conexión a PostgreSQL
+func (b BaseDatos) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		b.Host, b.Puerto, b.Usuario, b.Password, b.Nombre, b.SSLMode)
+}
+
+// Cargar lee la configuración de las variables de entorno. Antes se carga el archivo indicado
+// en CONFIG_FILE (o .env), pero las variables ya definidas en el entorno tienen prioridad.
+func Cargar() (*Config, error) {
+	archivo := getEnv("CONFIG_FILE", ArchivoPorDefecto)
+	if err := godotenv.Load(archivo); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error al leer %s: %w", archivo, err)
+		}
+		log.Printf("No se encontró el archivo %s, usando variables de entorno y valores por defecto", archivo)
+	}
+
+	cfg := &Config{
+		Entorno:            getEnv("APP_ENV", EntornoDesarrollo),
+		EntornoDefinido:    os.Getenv("APP_ENV") != "",
+		Puerto:             getEnv("PORT", "8080"),
+		OrigenesPermitidos: getLista("ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+		BaseDatos: BaseDatos{
+			Driver:                getEnv("DB_DRIVER", DriverPostgres),
+			RutaSQLite:            getEnv("DB_PATH", "tornea.db"),
+			Host:                  getEnv("DB_HOST", "localhost"),
+			Puerto:                getEnv("DB_PORT", "5432"),
+			Usuario:               getEnv("DB_USER", "postgres"),
+			Password:              getEnv("DB_PASSWORD", "postgres"),
+			Nombre:                getEnv("DB_NAME", "tornea"),
+			SSLMode:               getEnv("DB_SSL_MODE", "disable"),
+			DirectorioMigraciones: getEnv("MIGRATIONS_DIR", "migraciones"),
+		},
+		JWT: JWT{
+			Secreto: []byte(os.Getenv("JWT_SECRET")),
+		},
+	}
+
+	switch cfg.Entorno {
+	case EntornoDesarrollo, EntornoPruebas, EntornoStaging, EntornoProduccion:
+	default:
+		return nil, fmt.Errorf("APP_ENV debe ser %s, %s, %s o %s: %q", EntornoDesarrollo, EntornoPruebas, EntornoStaging, EntornoProduccion, cfg.Entorno)
+	}
+
+	if cfg.BaseDatos.Driver != DriverPostgres && cfg.BaseDatos.Driver != DriverSQLite {
+		return nil, fmt.Errorf("DB_DRIVER debe ser %s o %s: %q", DriverPostgres, DriverSQLite, cfg.BaseDatos.Driver)
+	}
+
+	var err error
+	if cfg.BaseDatos.MaxConexionesAbiertas, err = getEntero("DB_MAX_OPEN_CONNS", 25); err != nil {
+		return nil, err
+	}
+	if cfg.BaseDatos.MaxConexionesInactivas, err = getEntero("DB_MAX_IDLE_CONNS", 5); err != nil {
+		return nil, err
+	}
+	if cfg.BaseDatos.VidaMaximaConexion, err = getDuracion("DB_CONN_MAX_LIFETIME", 30*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.BaseDatos.MigrarAlIniciar, err = getBooleano("DB_AUTO_MIGRATE", true); err != nil {
+		return nil, err
+	}
+	if cfg.JWT.Expiracion, err = getDuracion("JWT_EXPIRATION", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Arbitros.RestringirCiudad, err = getBooleano("REFEREE_CITY_RESTRICTION", false); err != nil {
+		return nil, err
+	}
+	zona := getEnv("LEAGUE_TIMEZONE", "America/Bogota")
+	if cfg.Liga.ZonaHoraria, err = time.LoadLocation(zona); err != nil {
+		return nil, fmt.Errorf("LEAGUE_TIMEZONE no es una zona horaria válida: %q", zona)
+	}
+
+	return cfg, nil
+}
+
+// EsProduccion indica si la aplicación se ejecuta en producción, donde no se permiten
+// operaciones destructivas como vaciar la base de datos
+func (c *Config) EsProduccion() bool {
+	return c.Entorno == EntornoProduccion
+}
+
+// PermiteVaciarDatos indica si se puede borrar todo el torneo. Solo se permite si APP_ENV
+// indica de forma explícita desarrollo o pruebas, para que una base de producción en la que
+// nunca se definió APP_ENV no se vacíe por el entorno por defecto.
+func (c *Config) PermiteVaciarDatos() bool {
+	return c.EntornoDefinido && (c.Entorno == EntornoDesarrollo || c.Entorno == EntornoPruebas)
+}
+
+// Validar comprueba la configuración que necesita el servidor HTTP para arrancar
+func (c *Config) Validar() error {
+	if len(c.JWT.Secreto) == 0 {
+		return ErrJWTSecretoFaltante
+	}
+	if len(c.OrigenesPermitidos) == 0 {
+		return errors.New("ALLOWED_ORIGINS debe contener al menos un origen")
+	}
+	return nil
+}
+
+// getEnv obtiene una variable de entorno o devuelve un valor por defecto
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// getLista lee una variable con valores separados por comas
+func getLista(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var lista []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			lista = append(lista, item)
+		}
+	}
+	return lista
+}
+
+// getEntero lee una variable numérica; un valor mal formado es un error y no se ignora
+func getEntero(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s debe ser un entero no negativo: %q", key, value)
+	}
+	return n, nil
+}
+
+// getBooleano lee una variable con un valor como true, false, 1 o 0
+func getBooleano(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s debe ser true o false: %q", key, value)
+	}
+	return b, nil
+}
+
+// getDuracion lee una duración con el formato de Go (por ejemplo 24h o 90m)
+func getDuracion(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s debe ser una duración positiva (por ejemplo 24h): %q", key, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// ArbitroInput contiene los campos de un árbitro nuevo
+type ArbitroInput struct {
+	Nombre    string `json:"nombre" binding:"required"`
+	Ciudad    string `json:"ciudad"`
+	Categoria string `json:"categoria" binding:"max=50"`
+}
+
+// ArbitroPatch contiene los campos de un árbitro que se pueden modificar con PATCH
+type ArbitroPatch struct {
+	Nombre    *string `json:"nombre" binding:"omitempty,min=1"`
+	Ciudad    *string `json:"ciudad"`
+	Categoria *string `json:"categoria" binding:"omitempty,max=50"`
+}
+
+// camposPatchArbitro son los campos que acepta PATCH /arbitros/:id; ninguno admite null
+var camposPatchArbitro = []string{"nombre", "ciudad", "categoria"}
+
+// aplicar copia en el árbitro los campos enviados en el patch
+func (patch ArbitroPatch) aplicar(arbitro *models.Arbitro) {
+	if patch.Nombre != nil {
+		arbitro.Nombre = *patch.Nombre
+	}
+	if patch.Ciudad != nil {
+		arbitro.Ciudad = *patch.Ciudad
+	}
+	if patch.Categoria != nil {
+		arbitro.Categoria = *patch.Categoria
+	}
+}
+
+// DesignacionInput indica el árbitro de cada rol del partido. Los roles que se omiten o se
+// envían en null quedan sin árbitro.
+type DesignacionInput struct {
+	Principal  *uint `json:"principal" binding:"omitempty,min=1"`
+	Asistente1 *uint `json:"asistente1" binding:"omitempty,min=1"`
+	Asistente2 *uint `json:"asistente2" binding:"omitempty,min=1"`
+	VAR        *uint `json:"var" binding:"omitempty,min=1"`
+}
+
+// camposRolArbitro relaciona cada rol con el campo de DesignacionInput que lo indica
+var camposRolArbitro = map[string]string{
+	models.RolArbitroPrincipal:  "principal",
+	models.RolArbitroAsistente1: "asistente1",
+	models.RolArbitroAsistente2: "asistente2",
+	models.RolArbitroVAR:        "var",
+}
+
+// roles devuelve el árbitro indicado para cada rol
+func (input DesignacionInput) roles() map[string]uint {
+	roles := make(map[string]uint)
+	for rol, id := range map[string]*uint{
+		models.RolArbitroPrincipal:  input.Principal,
+		models.RolArbitroAsistente1: input.Asistente1,
+		models.RolArbitroAsistente2: input.Asistente2,
+		models.RolArbitroVAR:        input.VAR,
+	} {
+		if id != nil {
+			roles[rol] = *id
+		}
+	}
+	return roles
+}
+
+// ObtenerArbitros retorna la lista de todos los árbitros
+func ObtenerArbitros(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewArbitroService(db)
+		arbitros, err := service.GetArbitros()
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorObtenerArbitros)
+			return
+		}
+
+		c.JSON(http.StatusOK, arbitros)
+	}
+}
+
+// ObtenerArbitro retorna un árbitro específico por su ID
+func ObtenerArbitro(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDArbitroInvalido)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		arbitro, err := service.GetArbitro(uint(id))
+		if err != nil {
+			responderError(c, http.StatusNotFound, ErrorArbitroNoEncontrado)
+			return
+		}
+
+		escribirETag(c, arbitro.Version)
+		c.JSON(http.StatusOK, arbitro)
+	}
+}
+
+// CrearArbitro maneja la creación de un nuevo árbitro
+func CrearArbitro(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input ArbitroInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		arbitro, err := service.CrearArbitro(models.Arbitro{
+			Nombre:    input.Nombre,
+			Ciudad:    input.Ciudad,
+			Categoria: input.Categoria,
+		})
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorCrearArbitro)
+			return
+		}
+
+		escribirETag(c, arbitro.Version)
+		c.JSON(http.StatusCreated, gin.H{
+			"mensaje": "Árbitro creado exitosamente",
+			"arbitro": arbitro,
+		})
+	}
+}
+
+// ModificarArbitro aplica un JSON Merge Patch sobre un árbitro existente
+func ModificarArbitro(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDArbitroInvalido)
+			return
+		}
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		arbitro, err := service.GetArbitro(uint(id))
+		if err != nil {
+			responderError(c, http.StatusNotFound, ErrorArbitroNoEncontrado)
+			return
+		}
+
+		if esperada != nil && *esperada != arbitro.Version {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionArbitro)
+			return
+		}
+
+		var patch ArbitroPatch
+		if _, err := leerMergePatch(c, &patch, camposPatchArbitro, nil); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		patch.aplicar(&arbitro)
+
+		arbitro, err = service.ActualizarArbitro(arbitro)
+		if errors.Is(err, services.ErrVersionObsoleta) {
+			responderError(c, http.StatusConflict, ErrorConflictoArbitro)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorActualizarArbitro)
+			return
+		}
+
+		escribirETag(c, arbitro.Version)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Árbitro actualizado exitosamente",
+			"arbitro": arbitro,
+		})
+	}
+}
+
+// EliminarArbitro elimina un árbitro que no está designado en ningún partido
+func EliminarArbitro(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDArbitroInvalido)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		err = service.EliminarArbitro(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorArbitroNoEncontrado)
+			return
+		}
+		if errors.Is(err, services.ErrArbitroConDesignaciones) {
+			responderError(c, http.StatusConflict, ErrorArbitroDesignado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorEliminarArbitro)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"mensaje": "Árbitro eliminado exitosamente"})
+	}
+}
+
+// ObtenerEstadisticasArbitro retorna los partidos designados de un árbitro y las tarjetas y
+// penales por partido de los que dirigió como árbitro principal
+func ObtenerEstadisticasArbitro(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDArbitroInvalido)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		estadisticas, err := service.GetEstadisticasArbitro(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorArbitroNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorEstadisticasArbitro)
+			return
+		}
+
+		c.JSON(http.StatusOK, estadisticas)
+	}
+}
+
+// ObtenerArbitrosPartido retorna los árbitros designados para un partido
+func ObtenerArbitrosPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		designados, err := service.GetDesignacion(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorObtenerDesignacion)
+			return
+		}
+
+		c.JSON(http.StatusOK, designados)
+	}
+}
+
+// DesignarArbitros reemplaza los árbitros designados para un partido. Un árbitro solo puede
+// dirigir un partido por jornada y, según la configuración, ninguno de equipos de su ciudad.
+func DesignarArbitros(db *gorm.DB, reglas config.Arbitros) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input DesignacionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		service := services.NewArbitroService(db)
+		service.RestringirCiudad = reglas.RestringirCiudad
+		designados, err := service.DesignarArbitros(uint(id), input.roles())
+		var rechazo *services.ErrDesignacionArbitro
+		if errors.As(err, &rechazo) {
+			campo := ErrorCampo{Campo: camposRolArbitro[rechazo.Rol], Regla: rechazo.Regla}
+			switch rechazo.Regla {
+			case services.ReglaArbitroInexistente:
+				campo.Regla = "valor"
+				responderError(c, http.StatusBadRequest, ErrorArbitroInexistente, campo)
+			case services.ReglaArbitroRepetido:
+				responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, campo)
+			default:
+				responderError(c, http.StatusConflict, ErrorArbitroNoDisponible, campo)
+			}
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorDesignarArbitros)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje":  "Árbitros designados exitosamente",
+			"arbitros": designados,
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// ObtenerAsistenciaEquipos retorna el público de cada equipo como local y como visitante
+func ObtenerAsistenciaEquipos(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewCalendarioService(db)
+		asistencia, err := service.GetAsistenciaEquipos()
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorAsistencia)
+			return
+		}
+
+		c.JSON(http.StatusOK, asistencia)
+	}
+}
+
+// ObtenerAsistenciaEstadios retorna el público y la ocupación de cada estadio
+func ObtenerAsistenciaEstadios(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewCalendarioService(db)
+		asistencia, err := service.GetAsistenciaEstadios()
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorAsistencia)
+			return
+		}
+
+		c.JSON(http.StatusOK, asistencia)
+	}
+}
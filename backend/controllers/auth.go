@@ -1,11 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
-	"os"
+	"slices"
+	"strconv"
 	"time"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/noisk8/torneas/backend/config"
 	"github.com/noisk8/torneas/backend/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -15,7 +18,11 @@ type RegisterInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	Nombre   string `json:"nombre" binding:"required"`
-	Rol      string `json:"rol" binding:"required"`
+}
+
+// RolInput es el rol que un administrador da a un usuario
+type RolInput struct {
+	Rol string `json:"rol" binding:"required,oneof=admin editor lector"`
 }
 
 type LoginInput struct {
@@ -23,25 +30,27 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// Register crea un usuario con el rol lector. Si la petición trae un rol se ignora: los roles
+// con permisos de escritura solo los puede dar un administrador con AsignarRol.
 func Register(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input RegisterInput
 		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			responderErrorValidacion(c, err)
 			return
 		}
 
 		// Verificar si el usuario ya existe
 		var usuario models.Usuario
 		if err := db.Where("email = ?", input.Email).First(&usuario).Error; err == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "El correo electrónico ya está registrado"})
+			responderError(c, http.StatusBadRequest, ErrorEmailRegistrado)
 			return
 		}
 
 		// Crear el nuevo usuario
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al procesar la contraseña"})
+			responderError(c, http.StatusInternalServerError, ErrorProcesarContrasena)
 			return
 		}
 
@@ -49,12 +58,12 @@ func Register(db *gorm.DB) gin.HandlerFunc {
 			Email:    input.Email,
 			Password: string(hashedPassword),
 			Nombre:   input.Nombre,
-			Rol:      input.Rol,
+			Rol:      models.RolLector,
 			Activo:   true,
 		}
 
 		if err := db.Create(&nuevoUsuario).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear el usuario"})
+			responderError(c, http.StatusInternalServerError, ErrorCrearUsuario)
 			return
 		}
 
@@ -62,22 +71,22 @@ func Register(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func Login(db *gorm.DB) gin.HandlerFunc {
+func Login(db *gorm.DB, jwtConfig config.JWT) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input LoginInput
 		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			responderErrorValidacion(c, err)
 			return
 		}
 
 		var usuario models.Usuario
 		if err := db.Where("email = ?", input.Email).First(&usuario).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
+			responderError(c, http.StatusUnauthorized, ErrorCredencialesInvalidas)
 			return
 		}
 
 		if err := bcrypt.CompareHashAndPassword([]byte(usuario.Password), []byte(input.Password)); err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
+			responderError(c, http.StatusUnauthorized, ErrorCredencialesInvalidas)
 			return
 		}
 
@@ -86,12 +95,12 @@ func Login(db *gorm.DB) gin.HandlerFunc {
 			"sub": usuario.ID,
 			"email": usuario.Email,
 			"rol": usuario.Rol,
-			"exp": time.Now().Add(time.Hour * 24).Unix(),
+			"exp": time.Now().Add(jwtConfig.Expiracion).Unix(),
 		})
 
-		tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+		tokenString, err := token.SignedString(jwtConfig.Secreto)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al generar el token"})
+			responderError(c, http.StatusInternalServerError, ErrorGenerarToken)
 			return
 		}
 
@@ -106,11 +115,11 @@ func Login(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func VerifyToken() gin.HandlerFunc {
+func VerifyToken(jwtConfig config.JWT) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token no proporcionado"})
+			responderError(c, http.StatusUnauthorized, ErrorTokenNoProporcionado)
 			return
 		}
 
@@ -119,20 +128,110 @@ func VerifyToken() gin.HandlerFunc {
 			tokenString = tokenString[7:]
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
+		if _, err := validarToken(jwtConfig, tokenString); err != nil {
+			responderError(c, http.StatusUnauthorized, ErrorTokenInvalido)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Token válido"})
+	}
+}
+
+// AsignarRol cambia el rol de un usuario. Solo lo pueden usar los administradores. El rol va
+// dentro del token, así que el cambio se aplica cuando el usuario vuelve a iniciar sesión.
+func AsignarRol(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDUsuarioInvalido)
+			return
+		}
+
+		var input RolInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		var usuario models.Usuario
+		if err := db.First(&usuario, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorUsuarioNoEncontrado)
+			return
+		}
+
+		if err := db.Model(&usuario).Update("rol", input.Rol).Error; err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorActualizarUsuario)
+			return
+		}
+		usuario.Rol = input.Rol
+
+		c.JSON(http.StatusOK, gin.H{
+			"id":    usuario.ID,
+			"email": usuario.Email,
+			"rol":   usuario.Rol,
 		})
+	}
+}
+
+// RequerirAutenticacion exige un token JWT válido, enviado en el header Authorization, de un
+// usuario con alguno de los roles indicados
+func RequerirAutenticacion(jwtConfig config.JWT, roles ...string) gin.HandlerFunc {
+	return requerirAutenticacion(jwtConfig, false, roles)
+}
+
+// RequerirAutenticacionWebSocket es como RequerirAutenticacion pero, como el navegador no
+// permite enviar headers al abrir un WebSocket, también acepta el token en el parámetro
+// "token". Solo se usa en las rutas WebSocket para que los tokens no queden en los logs de
+// acceso de las demás rutas.
+func RequerirAutenticacionWebSocket(jwtConfig config.JWT, roles ...string) gin.HandlerFunc {
+	return requerirAutenticacion(jwtConfig, true, roles)
+}
+
+func requerirAutenticacion(jwtConfig config.JWT, tokenEnQuery bool, roles []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authorization")
+		if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
+			tokenString = tokenString[7:]
+		}
+		if tokenString == "" && tokenEnQuery {
+			tokenString = c.Query("token")
+		}
+		if tokenString == "" {
+			responderError(c, http.StatusUnauthorized, ErrorTokenNoProporcionado)
+			return
+		}
 
+		claims, err := validarToken(jwtConfig, tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+			responderError(c, http.StatusUnauthorized, ErrorTokenInvalido)
 			return
 		}
 
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+		rol, _ := claims["rol"].(string)
+		if len(roles) > 0 && !slices.Contains(roles, rol) {
+			responderError(c, http.StatusForbidden, ErrorSinPermisos)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"message": "Token válido"})
+		email, _ := claims["email"].(string)
+		c.Set("usuarioEmail", email)
+		c.Set("usuarioRol", rol)
+		c.Next()
+	}
+}
+
+// validarToken verifica la firma y vigencia de un token JWT y devuelve sus claims
+func validarToken(jwtConfig config.JWT, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return jwtConfig.Secreto, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("token inválido")
 	}
+	return claims, nil
 }
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// GenerarCalendarioInput son las opciones de generación del calendario; todas son opcionales
+type GenerarCalendarioInput struct {
+	FechaInicio *time.Time `json:"fechaInicio"`
+	Regenerar   bool       `json:"regenerar"` // Reemplazar las jornadas que aún no se han jugado
+	Simular     bool       `json:"simular"`   // Devolver el plan sin guardar cambios
+}
+
+// GenerarCalendario genera el calendario de ida y vuelta del torneo. Con "simular" devuelve
+// las jornadas que se crearían, regenerarían, conservarían o eliminarían sin guardar nada.
+func GenerarCalendario(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input GenerarCalendarioInput
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		plan, err := service.GenerarCalendario(services.OpcionesCalendario{
+			FechaInicio: input.FechaInicio,
+			Regenerar:   input.Regenerar,
+			Simular:     input.Simular,
+		})
+		if errors.Is(err, services.ErrEquiposInsuficientes) {
+			responderError(c, http.StatusUnprocessableEntity, ErrorEquiposInsuficientes)
+			return
+		}
+		if errors.Is(err, services.ErrVersionObsoleta) {
+			responderError(c, http.StatusConflict, ErrorConflictoJornada)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorGenerarCalendario)
+			return
+		}
+
+		mensaje := "Calendario generado exitosamente"
+		if plan.Simulacion {
+			mensaje = "Simulación del calendario, no se guardó ningún cambio"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": mensaje,
+			"plan":    plan,
+		})
+	}
+}
+
+// tipoContenidoICS es el tipo MIME de los calendarios en formato iCalendar
+const tipoContenidoICS = "text/calendar; charset=utf-8"
+
+// CalendarioICS publica el calendario completo del torneo en formato iCalendar para
+// suscribirse desde aplicaciones de calendario
+func CalendarioICS(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewCalendarioService(db)
+		ics, err := service.GetCalendarioICS()
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorCalendarioICS)
+			return
+		}
+
+		c.Header("Content-Disposition", `inline; filename="calendario.ics"`)
+		c.Data(http.StatusOK, tipoContenidoICS, ics)
+	}
+}
+
+// CalendarioEquipoICS publica en formato iCalendar los partidos de un equipo
+func CalendarioEquipoICS(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEquipoInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		ics, err := service.GetCalendarioEquipoICS(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorCalendarioICS)
+			return
+		}
+
+		c.Header("Content-Disposition", `inline; filename="calendario-equipo-`+strconv.FormatUint(id, 10)+`.ics"`)
+		c.Data(http.StatusOK, tipoContenidoICS, ics)
+	}
+}
@@ -1,25 +1,111 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
 )
 
+// EquipoInput contiene los campos de un equipo que se pueden crear o reemplazar
+type EquipoInput struct {
+	Nombre      string `json:"nombre" binding:"required"`
+	NombreCorto string `json:"nombreCorto" binding:"required"`
+	Ciudad      string `json:"ciudad" binding:"required"`
+	Estadio     string `json:"estadio" binding:"required"`
+	Fundacion   string `json:"fundacion" binding:"required"`
+	Escudo      string `json:"escudo" binding:"required"`
+	EstadioID   *uint  `json:"estadioId"` // Estadio registrado donde juega de local, opcional
+	Version     uint   `json:"version"`   // Versión esperada al actualizar, opcional
+}
+
+// aplicar copia los campos del input en el equipo
+func (input EquipoInput) aplicar(equipo *models.Equipo) {
+	equipo.Nombre = input.Nombre
+	equipo.NombreCorto = input.NombreCorto
+	equipo.Ciudad = input.Ciudad
+	equipo.Estadio = input.Estadio
+	equipo.Fundacion = input.Fundacion
+	equipo.Escudo = input.Escudo
+	equipo.EstadioID = input.EstadioID
+}
+
+// EquipoPatch contiene los campos de un equipo que se pueden modificar con PATCH
+type EquipoPatch struct {
+	Nombre      *string `json:"nombre" binding:"omitempty,min=1"`
+	NombreCorto *string `json:"nombreCorto" binding:"omitempty,min=1"`
+	Ciudad      *string `json:"ciudad" binding:"omitempty,min=1"`
+	Estadio     *string `json:"estadio" binding:"omitempty,min=1"`
+	Fundacion   *string `json:"fundacion" binding:"omitempty,min=1"`
+	Escudo      *string `json:"escudo" binding:"omitempty,min=1"`
+	EstadioID   *uint   `json:"estadioId" binding:"omitempty,min=1"`
+}
+
+// camposPatchEquipo son los campos que acepta PATCH /equipos/:id
+var camposPatchEquipo = []string{"nombre", "nombreCorto", "ciudad", "estadio", "fundacion", "escudo", "estadioId"}
+
+// camposAnulablesEquipo son los campos del equipo que se pueden borrar enviando null
+var camposAnulablesEquipo = []string{"estadioId"}
+
+// aplicar copia en el equipo los campos enviados en el patch
+func (patch EquipoPatch) aplicar(equipo *models.Equipo, nulos map[string]bool) {
+	if patch.Nombre != nil {
+		equipo.Nombre = *patch.Nombre
+	}
+	if patch.NombreCorto != nil {
+		equipo.NombreCorto = *patch.NombreCorto
+	}
+	if patch.Ciudad != nil {
+		equipo.Ciudad = *patch.Ciudad
+	}
+	if patch.Estadio != nil {
+		equipo.Estadio = *patch.Estadio
+	}
+	if patch.Fundacion != nil {
+		equipo.Fundacion = *patch.Fundacion
+	}
+	if patch.Escudo != nil {
+		equipo.Escudo = *patch.Escudo
+	}
+	if nulos["estadioId"] {
+		equipo.EstadioID = nil
+	} else if patch.EstadioID != nil {
+		equipo.EstadioID = patch.EstadioID
+	}
+}
+
+// asignarEstadio valida el estadio local del equipo y copia su nombre en el campo estadio.
+// Si el estadio no existe responde el error y devuelve false.
+func asignarEstadio(c *gin.Context, db *gorm.DB, equipo *models.Equipo) bool {
+	estadio, ok := validarEstadio(c, db, equipo.EstadioID, "estadioId")
+	if ok && equipo.EstadioID != nil {
+		equipo.Estadio = estadio.Nombre
+	}
+	return ok
+}
+
 // CrearEquipo maneja la creación de un nuevo equipo
 func CrearEquipo(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var input EquipoInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
 		var equipo models.Equipo
-		if err := c.ShouldBindJSON(&equipo); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		input.aplicar(&equipo)
+		if !asignarEstadio(c, db, &equipo) {
 			return
 		}
 
 		result := db.Create(&equipo)
 		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al crear el equipo"})
+			responderError(c, http.StatusInternalServerError, ErrorCrearEquipo)
 			return
 		}
 
@@ -30,142 +116,120 @@ func CrearEquipo(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// ActualizarEquipo maneja la actualización de un equipo existente
+// ActualizarEquipo maneja la actualización de un equipo existente.
+// La versión esperada se toma del header If-Match o del campo "version" del cuerpo.
 func ActualizarEquipo(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 		var equipo models.Equipo
 
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
 		if err := db.First(&equipo, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Equipo no encontrado"})
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
 			return
 		}
 
-		if err := c.ShouldBindJSON(&equipo); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos"})
+		if esperada != nil && *esperada != equipo.Version {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionEquipo)
 			return
 		}
 
-		if err := db.Save(&equipo).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar el equipo"})
+		var input EquipoInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		input.aplicar(&equipo)
+		if esperada == nil && input.Version != 0 {
+			equipo.Version = input.Version
+		}
+		if !asignarEstadio(c, db, &equipo) {
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"mensaje": "Equipo actualizado exitosamente",
-			"equipo":  equipo,
-		})
+		guardarEquipo(c, db, equipo)
+	}
+}
+
+// ModificarEquipo aplica un JSON Merge Patch sobre un equipo existente
+func ModificarEquipo(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var equipo models.Equipo
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		if err := db.First(&equipo, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
+			return
+		}
+
+		if esperada != nil && *esperada != equipo.Version {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionEquipo)
+			return
+		}
+
+		var patch EquipoPatch
+		nulos, err := leerMergePatch(c, &patch, camposPatchEquipo, camposAnulablesEquipo)
+		if err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		patch.aplicar(&equipo, nulos)
+		if !asignarEstadio(c, db, &equipo) {
+			return
+		}
+
+		guardarEquipo(c, db, equipo)
 	}
 }
 
+// guardarEquipo guarda el equipo respetando su versión y responde con el resultado
+func guardarEquipo(c *gin.Context, db *gorm.DB, equipo models.Equipo) {
+	service := services.NewEquipoService(db)
+	equipo, err := service.UpdateEquipo(equipo)
+	if errors.Is(err, services.ErrVersionObsoleta) {
+		responderError(c, http.StatusConflict, ErrorConflictoEquipo)
+		return
+	}
+	if err != nil {
+		responderError(c, http.StatusInternalServerError, ErrorActualizarEquipo)
+		return
+	}
+
+	escribirETag(c, equipo.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"mensaje": "Equipo actualizado exitosamente",
+		"equipo":  equipo,
+	})
+}
+
 // ObtenerEquipos retorna la lista de todos los equipos
 func ObtenerEquipos(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var equipos []models.Equipo
 		if err := db.Find(&equipos).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al obtener los equipos"})
+			responderError(c, http.StatusInternalServerError, ErrorObtenerEquipos)
 			return
 		}
 
 		// Calcular estadísticas para cada equipo
+		service := services.NewEquipoService(db)
+		partidosForma := longitudForma(c)
 		for i := range equipos {
-			// Partidos jugados
-			var pj int64
-			db.Model(&models.Partido{}).
-				Where("(equipo_local_id = ? OR equipo_visitante_id = ?) AND estado = ?", 
-					equipos[i].ID, equipos[i].ID, "finalizado").
-				Count(&pj)
-			equipos[i].PJ = int(pj)
-
-			// Partidos ganados
-			var pg int64
-			db.Model(&models.Partido{}).
-				Where("((equipo_local_id = ? AND goles_local > goles_visitante) OR "+
-					"(equipo_visitante_id = ? AND goles_visitante > goles_local)) AND estado = ?",
-					equipos[i].ID, equipos[i].ID, "finalizado").
-				Count(&pg)
-			equipos[i].PG = int(pg)
-
-			// Partidos empatados
-			var pe int64
-			db.Model(&models.Partido{}).
-				Where("((equipo_local_id = ? OR equipo_visitante_id = ?) AND "+
-					"goles_local = goles_visitante AND estado = ?)",
-					equipos[i].ID, equipos[i].ID, "finalizado").
-				Count(&pe)
-			equipos[i].PE = int(pe)
-
-			// Partidos perdidos
-			var pp int64
-			db.Model(&models.Partido{}).
-				Where("((equipo_local_id = ? AND goles_local < goles_visitante) OR "+
-					"(equipo_visitante_id = ? AND goles_visitante < goles_local)) AND estado = ?",
-					equipos[i].ID, equipos[i].ID, "finalizado").
-				Count(&pp)
-			equipos[i].PP = int(pp)
-
-			// Goles a favor y en contra
-			var golesLocal, golesVisitante int
-			type ResultadoPartido struct {
-				GolesLocal     int
-				GolesVisitante int
-				EquipoLocalID  uint
-			}
-			var partidos []ResultadoPartido
-			
-			if err := db.Model(&models.Partido{}).
-				Select("goles_local, goles_visitante, equipo_local_id").
-				Where("(equipo_local_id = ? OR equipo_visitante_id = ?) AND estado = ?",
-					equipos[i].ID, equipos[i].ID, "finalizado").
-				Find(&partidos).Error; err == nil {
-				
-				for _, partido := range partidos {
-					if partido.EquipoLocalID == equipos[i].ID {
-						// El equipo jugó como local
-						golesLocal += partido.GolesLocal
-						golesVisitante += partido.GolesVisitante
-					} else {
-						// El equipo jugó como visitante
-						golesLocal += partido.GolesVisitante
-						golesVisitante += partido.GolesLocal
-					}
-				}
+			if err := service.CalcularEstadisticas(&equipos[i], partidosForma); err != nil {
+				responderError(c, http.StatusInternalServerError, ErrorObtenerEquipos)
+				return
 			}
-
-			equipos[i].GF = golesLocal
-			equipos[i].GC = golesVisitante
-			equipos[i].DG = golesLocal - golesVisitante
-			equipos[i].Puntos = (equipos[i].PG * 3) + equipos[i].PE
-
-			// Últimos 5 juegos
-			var ultimosPartidos []models.Partido
-			db.Where("(equipo_local_id = ? OR equipo_visitante_id = ?) AND estado = ?",
-				equipos[i].ID, equipos[i].ID, "finalizado").
-				Order("fecha_hora DESC").
-				Limit(5).
-				Find(&ultimosPartidos)
-
-			ultimosJuegos := ""
-			for _, partido := range ultimosPartidos {
-				if partido.EquipoLocalID == equipos[i].ID {
-					if partido.GolesLocal > partido.GolesVisitante {
-						ultimosJuegos += "V"
-					} else if partido.GolesLocal < partido.GolesVisitante {
-						ultimosJuegos += "D"
-					} else {
-						ultimosJuegos += "E"
-					}
-				} else {
-					if partido.GolesVisitante > partido.GolesLocal {
-						ultimosJuegos += "V"
-					} else if partido.GolesVisitante < partido.GolesLocal {
-						ultimosJuegos += "D"
-					} else {
-						ultimosJuegos += "E"
-					}
-				}
-			}
-			equipos[i].UltimosJuegos = ultimosJuegos
 		}
 
 		c.JSON(http.StatusOK, equipos)
@@ -179,14 +243,35 @@ func ObtenerEquipo(db *gorm.DB) gin.HandlerFunc {
 		var equipo models.Equipo
 
 		if err := db.First(&equipo, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Equipo no encontrado"})
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
+			return
+		}
+
+		service := services.NewEquipoService(db)
+		if err := service.CalcularEstadisticas(&equipo, longitudForma(c)); err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorEstadisticasEquipo)
 			return
 		}
 
+		escribirETag(c, equipo.Version)
 		c.JSON(http.StatusOK, equipo)
 	}
 }
 
+// ObtenerPosiciones retorna la tabla de posiciones del torneo
+func ObtenerPosiciones(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewEquipoService(db)
+		equipos, err := service.GetTablaPosiciones(longitudForma(c))
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorPosiciones)
+			return
+		}
+
+		c.JSON(http.StatusOK, equipos)
+	}
+}
+
 // EliminarEquipo elimina un equipo por su ID
 func EliminarEquipo(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -194,15 +279,84 @@ func EliminarEquipo(db *gorm.DB) gin.HandlerFunc {
 		var equipo models.Equipo
 
 		if err := db.First(&equipo, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Equipo no encontrado"})
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
 			return
 		}
 
 		if err := db.Delete(&equipo).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al eliminar el equipo"})
+			responderError(c, http.StatusInternalServerError, ErrorEliminarEquipo)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"mensaje": "Equipo eliminado exitosamente"})
 	}
 }
+
+// ObtenerEnfrentamientos retorna el historial de partidos entre dos equipos
+func ObtenerEnfrentamientos(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		equipoAID, errA := strconv.ParseUint(c.Param("id"), 10, 64)
+		equipoBID, errB := strconv.ParseUint(c.Param("rivalId"), 10, 64)
+		if errA != nil || errB != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEquipoInvalido)
+			return
+		}
+		if equipoAID == equipoBID {
+			responderError(c, http.StatusBadRequest, ErrorEquiposIguales)
+			return
+		}
+
+		var equipos []models.Equipo
+		if err := db.Find(&equipos, []uint64{equipoAID, equipoBID}).Error; err != nil || len(equipos) != 2 {
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		enfrentamiento, err := service.GetEnfrentamientos(uint(equipoAID), uint(equipoBID))
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorEnfrentamientos)
+			return
+		}
+
+		c.JSON(http.StatusOK, enfrentamiento)
+	}
+}
+
+// ObtenerEstadisticasEquipo retorna las estadísticas avanzadas de un equipo
+func ObtenerEstadisticasEquipo(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEquipoInvalido)
+			return
+		}
+
+		service := services.NewEquipoService(db)
+		if _, err := service.GetEquipoByID(uint(id)); err != nil {
+			responderError(c, http.StatusNotFound, ErrorEquipoNoEncontrado)
+			return
+		}
+
+		estadisticas, err := service.GetEstadisticasEquipo(uint(id))
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorEstadisticasEquipo)
+			return
+		}
+
+		c.JSON(http.StatusOK, estadisticas)
+	}
+}
+
+// longitudForma obtiene del parámetro "forma" la cantidad de partidos
+// que se incluyen en la forma reciente de los equipos
+func longitudForma(c *gin.Context) int {
+	cantidad, err := strconv.Atoi(c.Query("forma"))
+	if err != nil || cantidad <= 0 {
+		return services.FormaPorDefecto
+	}
+	if cantidad > services.FormaMaxima {
+		return services.FormaMaxima
+	}
+	return cantidad
+}
@@ -0,0 +1,414 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Códigos de error que devuelve la API
+const (
+	ErrorDatosInvalidos          = "DATOS_INVALIDOS"
+	ErrorCuerpoInvalido          = "CUERPO_INVALIDO"
+	ErrorIfMatchInvalido         = "IF_MATCH_INVALIDO"
+	ErrorIDEquipoInvalido        = "ID_EQUIPO_INVALIDO"
+	ErrorIDJugadorInvalido       = "ID_JUGADOR_INVALIDO"
+	ErrorIDPartidoInvalido       = "ID_PARTIDO_INVALIDO"
+	ErrorIDEstadioInvalido       = "ID_ESTADIO_INVALIDO"
+	ErrorIDArbitroInvalido       = "ID_ARBITRO_INVALIDO"
+	ErrorIDUsuarioInvalido       = "ID_USUARIO_INVALIDO"
+	ErrorEquipoNoEncontrado      = "EQUIPO_NO_ENCONTRADO"
+	ErrorJugadorNoEncontrado     = "JUGADOR_NO_ENCONTRADO"
+	ErrorPartidoNoEncontrado     = "PARTIDO_NO_ENCONTRADO"
+	ErrorJornadaNoEncontrada     = "JORNADA_NO_ENCONTRADA"
+	ErrorEstadioNoEncontrado     = "ESTADIO_NO_ENCONTRADO"
+	ErrorArbitroNoEncontrado     = "ARBITRO_NO_ENCONTRADO"
+	ErrorUsuarioNoEncontrado     = "USUARIO_NO_ENCONTRADO"
+	ErrorSinJornadaEnJuego       = "SIN_JORNADA_EN_JUEGO"
+	ErrorEquipoInexistente       = "EQUIPO_INEXISTENTE"
+	ErrorEquiposInexistentes     = "EQUIPOS_INEXISTENTES"
+	ErrorJornadaInexistente      = "JORNADA_INEXISTENTE"
+	ErrorEstadioInexistente      = "ESTADIO_INEXISTENTE"
+	ErrorEstadioEnUso            = "ESTADIO_EN_USO"
+	ErrorArbitroInexistente      = "ARBITRO_INEXISTENTE"
+	ErrorArbitroNoDisponible     = "ARBITRO_NO_DISPONIBLE"
+	ErrorArbitroDesignado        = "ARBITRO_DESIGNADO"
+	ErrorEquiposIguales          = "EQUIPOS_IGUALES"
+	ErrorEquiposInsuficientes    = "EQUIPOS_INSUFICIENTES"
+	ErrorJugadorAjeno            = "JUGADOR_AJENO_AL_PARTIDO"
+	ErrorJornadaDuplicada        = "JORNADA_DUPLICADA"
+	ErrorEmailRegistrado         = "EMAIL_REGISTRADO"
+	ErrorCredencialesInvalidas   = "CREDENCIALES_INVALIDAS"
+	ErrorTokenNoProporcionado    = "TOKEN_NO_PROPORCIONADO"
+	ErrorTokenInvalido           = "TOKEN_INVALIDO"
+	ErrorSinPermisos             = "SIN_PERMISOS"
+	ErrorVersionEquipo           = "VERSION_EQUIPO_OBSOLETA"
+	ErrorVersionJugador          = "VERSION_JUGADOR_OBSOLETA"
+	ErrorVersionPartido          = "VERSION_PARTIDO_OBSOLETA"
+	ErrorVersionJornada          = "VERSION_JORNADA_OBSOLETA"
+	ErrorVersionEstadio          = "VERSION_ESTADIO_OBSOLETA"
+	ErrorVersionArbitro          = "VERSION_ARBITRO_OBSOLETA"
+	ErrorConflictoEquipo         = "CONFLICTO_EQUIPO"
+	ErrorConflictoJugador        = "CONFLICTO_JUGADOR"
+	ErrorConflictoPartido        = "CONFLICTO_PARTIDO"
+	ErrorConflictoJornada        = "CONFLICTO_JORNADA"
+	ErrorConflictoEstadio        = "CONFLICTO_ESTADIO"
+	ErrorConflictoArbitro        = "CONFLICTO_ARBITRO"
+	ErrorTransicionPeriodo       = "TRANSICION_PERIODO_INVALIDA"
+	ErrorPartidoNoReprogramable  = "PARTIDO_NO_REPROGRAMABLE"
+	ErrorFechaOcupada            = "FECHA_OCUPADA"
+	ErrorTipoImportacion         = "TIPO_IMPORTACION_INVALIDO"
+	ErrorArchivoImportacion      = "ARCHIVO_IMPORTACION_INVALIDO"
+	ErrorImportacionInvalida     = "IMPORTACION_INVALIDA"
+	ErrorTipoExportacion         = "TIPO_EXPORTACION_INVALIDO"
+	ErrorInterno                 = "ERROR_INTERNO"
+	ErrorProcesarContrasena      = "ERROR_PROCESAR_CONTRASENA"
+	ErrorCrearUsuario            = "ERROR_CREAR_USUARIO"
+	ErrorActualizarUsuario       = "ERROR_ACTUALIZAR_USUARIO"
+	ErrorGenerarToken            = "ERROR_GENERAR_TOKEN"
+	ErrorObtenerEquipos          = "ERROR_OBTENER_EQUIPOS"
+	ErrorCrearEquipo             = "ERROR_CREAR_EQUIPO"
+	ErrorActualizarEquipo        = "ERROR_ACTUALIZAR_EQUIPO"
+	ErrorEliminarEquipo          = "ERROR_ELIMINAR_EQUIPO"
+	ErrorEstadisticasEquipo      = "ERROR_ESTADISTICAS_EQUIPO"
+	ErrorPosiciones              = "ERROR_POSICIONES"
+	ErrorEnfrentamientos         = "ERROR_ENFRENTAMIENTOS"
+	ErrorActualizarJugador       = "ERROR_ACTUALIZAR_JUGADOR"
+	ErrorPartidosJugador         = "ERROR_PARTIDOS_JUGADOR"
+	ErrorActualizarJornada       = "ERROR_ACTUALIZAR_JORNADA"
+	ErrorActualizarPartido       = "ERROR_ACTUALIZAR_PARTIDO"
+	ErrorActualizarEstado        = "ERROR_ACTUALIZAR_ESTADO"
+	ErrorActualizarMarcador      = "ERROR_ACTUALIZAR_MARCADOR"
+	ErrorRegistrarIncidencia     = "ERROR_REGISTRAR_INCIDENCIA"
+	ErrorIniciarPeriodo          = "ERROR_INICIAR_PERIODO"
+	ErrorFinalizarPeriodo        = "ERROR_FINALIZAR_PERIODO"
+	ErrorTiempoAnadido           = "ERROR_TIEMPO_ANADIDO"
+	ErrorPartidosEnCurso         = "ERROR_PARTIDOS_EN_CURSO"
+	ErrorGenerarCalendario       = "ERROR_GENERAR_CALENDARIO"
+	ErrorAplazarPartido          = "ERROR_APLAZAR_PARTIDO"
+	ErrorReprogramarPartido      = "ERROR_REPROGRAMAR_PARTIDO"
+	ErrorObtenerPartido          = "ERROR_OBTENER_PARTIDO"
+	ErrorReprogramaciones        = "ERROR_REPROGRAMACIONES"
+	ErrorCalendarioICS           = "ERROR_CALENDARIO_ICS"
+	ErrorObtenerEstadios         = "ERROR_OBTENER_ESTADIOS"
+	ErrorCrearEstadio            = "ERROR_CREAR_ESTADIO"
+	ErrorActualizarEstadio       = "ERROR_ACTUALIZAR_ESTADIO"
+	ErrorEliminarEstadio         = "ERROR_ELIMINAR_ESTADIO"
+	ErrorAgendaEstadio           = "ERROR_AGENDA_ESTADIO"
+	ErrorObtenerArbitros         = "ERROR_OBTENER_ARBITROS"
+	ErrorCrearArbitro            = "ERROR_CREAR_ARBITRO"
+	ErrorActualizarArbitro       = "ERROR_ACTUALIZAR_ARBITRO"
+	ErrorEliminarArbitro         = "ERROR_ELIMINAR_ARBITRO"
+	ErrorEstadisticasArbitro     = "ERROR_ESTADISTICAS_ARBITRO"
+	ErrorObtenerDesignacion      = "ERROR_OBTENER_DESIGNACION"
+	ErrorDesignarArbitros        = "ERROR_DESIGNAR_ARBITROS"
+	ErrorAsistencia              = "ERROR_ASISTENCIA"
+	ErrorImportar                = "ERROR_IMPORTAR"
+	ErrorExportar                = "ERROR_EXPORTAR"
+	ErrorPrediccion              = "ERROR_PREDICCION"
+	ErrorProyeccion              = "ERROR_PROYECCION"
+)
+
+// mensajesError contiene el mensaje de cada código de error en los idiomas soportados
+var mensajesError = map[string]map[string]string{
+	ErrorDatosInvalidos:        {"es": "Datos inválidos", "en": "Invalid data"},
+	ErrorCuerpoInvalido:        {"es": "El cuerpo de la petición no es un JSON válido", "en": "The request body is not valid JSON"},
+	ErrorIfMatchInvalido:       {"es": "Header If-Match inválido", "en": "Invalid If-Match header"},
+	ErrorIDEquipoInvalido:      {"es": "ID de equipo inválido", "en": "Invalid team ID"},
+	ErrorIDJugadorInvalido:     {"es": "ID de jugador inválido", "en": "Invalid player ID"},
+	ErrorIDPartidoInvalido:     {"es": "ID de partido inválido", "en": "Invalid match ID"},
+	ErrorIDEstadioInvalido:     {"es": "ID de estadio inválido", "en": "Invalid venue ID"},
+	ErrorIDArbitroInvalido:     {"es": "ID de árbitro inválido", "en": "Invalid referee ID"},
+	ErrorIDUsuarioInvalido:     {"es": "ID de usuario inválido", "en": "Invalid user ID"},
+	ErrorEquipoNoEncontrado:    {"es": "Equipo no encontrado", "en": "Team not found"},
+	ErrorJugadorNoEncontrado:   {"es": "Jugador no encontrado", "en": "Player not found"},
+	ErrorPartidoNoEncontrado:   {"es": "Partido no encontrado", "en": "Match not found"},
+	ErrorJornadaNoEncontrada:   {"es": "Jornada no encontrada", "en": "Matchday not found"},
+	ErrorEstadioNoEncontrado:   {"es": "Estadio no encontrado", "en": "Venue not found"},
+	ErrorArbitroNoEncontrado:   {"es": "Árbitro no encontrado", "en": "Referee not found"},
+	ErrorUsuarioNoEncontrado:   {"es": "Usuario no encontrado", "en": "User not found"},
+	ErrorSinJornadaEnJuego:     {"es": "No hay una jornada en juego", "en": "There is no matchday in progress"},
+	ErrorEquipoInexistente:     {"es": "El equipo indicado no existe", "en": "The given team does not exist"},
+	ErrorEquiposInexistentes:   {"es": "Los equipos indicados no existen", "en": "The given teams do not exist"},
+	ErrorJornadaInexistente:    {"es": "La jornada indicada no existe", "en": "The given matchday does not exist"},
+	ErrorEstadioInexistente:    {"es": "El estadio indicado no existe", "en": "The given venue does not exist"},
+	ErrorEstadioEnUso:          {"es": "El estadio es la sede de equipos o partidos y no se puede eliminar", "en": "The venue is assigned to teams or matches and cannot be deleted"},
+	ErrorArbitroInexistente:    {"es": "El árbitro indicado no existe", "en": "The given referee does not exist"},
+	ErrorArbitroNoDisponible:   {"es": "El árbitro no puede ser designado para este partido", "en": "The referee cannot be assigned to this match"},
+	ErrorArbitroDesignado:      {"es": "El árbitro está designado en partidos y no se puede eliminar", "en": "The referee is assigned to matches and cannot be deleted"},
+	ErrorEquiposIguales:        {"es": "Los equipos deben ser distintos", "en": "The teams must be different"},
+	ErrorEquiposInsuficientes:  {"es": "Se necesitan al menos dos equipos para generar el calendario", "en": "At least two teams are needed to generate the fixtures"},
+	ErrorJugadorAjeno:          {"es": "El jugador no pertenece a ninguno de los equipos del partido", "en": "The player does not belong to either team in the match"},
+	ErrorJornadaDuplicada:      {"es": "Ya existe una jornada con ese número", "en": "A matchday with that number already exists"},
+	ErrorEmailRegistrado:       {"es": "El correo electrónico ya está registrado", "en": "The email address is already registered"},
+	ErrorCredencialesInvalidas: {"es": "Credenciales inválidas", "en": "Invalid credentials"},
+	ErrorTokenNoProporcionado:  {"es": "Token no proporcionado", "en": "Token not provided"},
+	ErrorTokenInvalido:         {"es": "Token inválido", "en": "Invalid token"},
+	ErrorSinPermisos:           {"es": "No tiene permisos para esta operación", "en": "You are not allowed to perform this operation"},
+	ErrorVersionEquipo:         {"es": "El equipo cambió desde la versión indicada en If-Match", "en": "The team changed since the version given in If-Match"},
+	ErrorVersionJugador:        {"es": "El jugador cambió desde la versión indicada en If-Match", "en": "The player changed since the version given in If-Match"},
+	ErrorVersionPartido:        {"es": "El partido cambió desde la versión indicada en If-Match", "en": "The match changed since the version given in If-Match"},
+	ErrorVersionJornada:        {"es": "La jornada cambió desde la versión indicada en If-Match", "en": "The matchday changed since the version given in If-Match"},
+	ErrorVersionEstadio:        {"es": "El estadio cambió desde la versión indicada en If-Match", "en": "The venue changed since the version given in If-Match"},
+	ErrorVersionArbitro:        {"es": "El árbitro cambió desde la versión indicada en If-Match", "en": "The referee changed since the version given in If-Match"},
+	ErrorConflictoEquipo:       {"es": "El equipo fue modificado por otro usuario, vuelva a cargarlo", "en": "The team was modified by another user, reload it"},
+	ErrorConflictoJugador:      {"es": "El jugador fue modificado por otro usuario, vuelva a cargarlo", "en": "The player was modified by another user, reload it"},
+	ErrorConflictoPartido:      {"es": "El partido fue modificado por otro operador, vuelva a intentarlo", "en": "The match was modified by another operator, try again"},
+	ErrorConflictoJornada:      {"es": "La jornada fue modificada por otro usuario, vuelva a cargarla", "en": "The matchday was modified by another user, reload it"},
+	ErrorConflictoEstadio:      {"es": "El estadio fue modificado por otro usuario, vuelva a cargarlo", "en": "The venue was modified by another user, reload it"},
+	ErrorConflictoArbitro:      {"es": "El árbitro fue modificado por otro usuario, vuelva a cargarlo", "en": "The referee was modified by another user, reload it"},
+	ErrorTransicionPeriodo:     {"es": "El periodo actual del partido no permite esta operación", "en": "The current period of the match does not allow this operation"},
+	ErrorPartidoNoReprogramable: {"es": "Solo se pueden aplazar o reprogramar partidos que no se han jugado", "en": "Only matches that have not been played can be postponed or rescheduled"},
+	ErrorFechaOcupada:          {"es": "Uno de los equipos ya juega otro partido ese día", "en": "One of the teams already plays another match that day"},
+	ErrorTipoImportacion:       {"es": "Solo se pueden importar estadios, equipos, jugadores, partidos o incidencias", "en": "Only stadiums, teams, players, matches or incidents can be imported"},
+	ErrorArchivoImportacion:    {"es": "Envíe en el campo archivo un CSV o XLSX de hasta 10 MB", "en": "Send a CSV or XLSX file of up to 10 MB in the archivo field"},
+	ErrorImportacionInvalida:   {"es": "El archivo tiene filas con errores y no se importó ningún dato", "en": "The file has rows with errors and no data was imported"},
+	ErrorTipoExportacion:       {"es": "Solo se pueden exportar estadios, equipos, jugadores, partidos o incidencias", "en": "Only stadiums, teams, players, matches or incidents can be exported"},
+	ErrorInterno:               {"es": "Error interno del servidor", "en": "Internal server error"},
+	ErrorProcesarContrasena:    {"es": "Error al procesar la contraseña", "en": "Error processing the password"},
+	ErrorCrearUsuario:          {"es": "Error al crear el usuario", "en": "Error creating the user"},
+	ErrorActualizarUsuario:     {"es": "Error al actualizar el usuario", "en": "Error updating the user"},
+	ErrorGenerarToken:          {"es": "Error al generar el token", "en": "Error generating the token"},
+	ErrorObtenerEquipos:        {"es": "Error al obtener los equipos", "en": "Error fetching the teams"},
+	ErrorCrearEquipo:           {"es": "Error al crear el equipo", "en": "Error creating the team"},
+	ErrorActualizarEquipo:      {"es": "Error al actualizar el equipo", "en": "Error updating the team"},
+	ErrorEliminarEquipo:        {"es": "Error al eliminar el equipo", "en": "Error deleting the team"},
+	ErrorEstadisticasEquipo:    {"es": "Error al calcular las estadísticas del equipo", "en": "Error computing the team statistics"},
+	ErrorPosiciones:            {"es": "Error al obtener la tabla de posiciones", "en": "Error fetching the standings"},
+	ErrorEnfrentamientos:       {"es": "Error al obtener los enfrentamientos", "en": "Error fetching the head-to-head record"},
+	ErrorActualizarJugador:     {"es": "Error al actualizar el jugador", "en": "Error updating the player"},
+	ErrorPartidosJugador:       {"es": "Error al obtener los partidos del jugador", "en": "Error fetching the player's matches"},
+	ErrorActualizarJornada:     {"es": "Error al actualizar la jornada", "en": "Error updating the matchday"},
+	ErrorActualizarPartido:     {"es": "Error al actualizar el partido", "en": "Error updating the match"},
+	ErrorActualizarEstado:      {"es": "Error al actualizar el estado del partido", "en": "Error updating the match status"},
+	ErrorActualizarMarcador:    {"es": "Error al actualizar el marcador", "en": "Error updating the score"},
+	ErrorRegistrarIncidencia:   {"es": "Error al registrar la incidencia", "en": "Error recording the incident"},
+	ErrorIniciarPeriodo:        {"es": "Error al iniciar el periodo", "en": "Error starting the period"},
+	ErrorFinalizarPeriodo:      {"es": "Error al finalizar el periodo", "en": "Error ending the period"},
+	ErrorTiempoAnadido:         {"es": "Error al registrar el tiempo añadido", "en": "Error recording the added time"},
+	ErrorPartidosEnCurso:       {"es": "Error al obtener los partidos en curso", "en": "Error fetching the matches in progress"},
+	ErrorGenerarCalendario:     {"es": "Error al generar el calendario", "en": "Error generating the fixtures"},
+	ErrorAplazarPartido:        {"es": "Error al aplazar el partido", "en": "Error postponing the match"},
+	ErrorReprogramarPartido:    {"es": "Error al reprogramar el partido", "en": "Error rescheduling the match"},
+	ErrorObtenerPartido:        {"es": "Error al obtener el partido", "en": "Error fetching the match"},
+	ErrorReprogramaciones:      {"es": "Error al obtener el historial de reprogramaciones", "en": "Error fetching the rescheduling history"},
+	ErrorCalendarioICS:         {"es": "Error al generar el calendario en formato iCalendar", "en": "Error generating the iCalendar feed"},
+	ErrorObtenerEstadios:       {"es": "Error al obtener los estadios", "en": "Error fetching the venues"},
+	ErrorCrearEstadio:          {"es": "Error al crear el estadio", "en": "Error creating the venue"},
+	ErrorActualizarEstadio:     {"es": "Error al actualizar el estadio", "en": "Error updating the venue"},
+	ErrorEliminarEstadio:       {"es": "Error al eliminar el estadio", "en": "Error deleting the venue"},
+	ErrorAgendaEstadio:         {"es": "Error al obtener la agenda del estadio", "en": "Error fetching the venue schedule"},
+	ErrorObtenerArbitros:       {"es": "Error al obtener los árbitros", "en": "Error fetching the referees"},
+	ErrorCrearArbitro:          {"es": "Error al crear el árbitro", "en": "Error creating the referee"},
+	ErrorActualizarArbitro:     {"es": "Error al actualizar el árbitro", "en": "Error updating the referee"},
+	ErrorEliminarArbitro:       {"es": "Error al eliminar el árbitro", "en": "Error deleting the referee"},
+	ErrorEstadisticasArbitro:   {"es": "Error al calcular las estadísticas del árbitro", "en": "Error computing the referee statistics"},
+	ErrorObtenerDesignacion:    {"es": "Error al obtener los árbitros del partido", "en": "Error fetching the match officials"},
+	ErrorDesignarArbitros:      {"es": "Error al designar los árbitros del partido", "en": "Error assigning the match officials"},
+	ErrorAsistencia:            {"es": "Error al calcular la asistencia", "en": "Error computing the attendance"},
+	ErrorImportar:              {"es": "Error al importar los datos", "en": "Error importing the data"},
+	ErrorExportar:              {"es": "Error al exportar los datos", "en": "Error exporting the data"},
+	ErrorPrediccion:            {"es": "Error al calcular la predicción de los partidos", "en": "Error computing the match predictions"},
+	ErrorProyeccion:            {"es": "Error al calcular la proyección de la tabla", "en": "Error computing the standings projection"},
+}
+
+// mensajesRegla describe en cada idioma las reglas de validación de un campo
+var mensajesRegla = map[string]map[string]string{
+	"required":        {"es": "es obligatorio", "en": "is required"},
+	"email":           {"es": "debe ser un correo electrónico válido", "en": "must be a valid email address"},
+	"min":             {"es": "debe ser al menos %s", "en": "must be at least %s"},
+	"max":             {"es": "debe ser como máximo %s", "en": "must be at most %s"},
+	"min_texto":       {"es": "debe tener al menos %s caracteres", "en": "must be at least %s characters long"},
+	"max_texto":       {"es": "debe tener como máximo %s caracteres", "en": "must be at most %s characters long"},
+	"url":             {"es": "debe ser una URL válida", "en": "must be a valid URL"},
+	"gt":              {"es": "debe ser mayor que %s", "en": "must be greater than %s"},
+	"oneof":           {"es": "debe ser uno de: %s", "en": "must be one of: %s"},
+	"tipo":            {"es": "tiene un tipo de dato inválido", "en": "has an invalid data type"},
+	"no_permitido":    {"es": "no se puede modificar", "en": "cannot be modified"},
+	"no_anulable":     {"es": "no puede ser null", "en": "cannot be null"},
+	"valor":           {"es": "tiene un valor inválido", "en": "has an invalid value"},
+	"dia_ocupado":     {"es": "coincide con otro partido de uno de los equipos ese día", "en": "clashes with another match of one of the teams that day"},
+	"repetido":        {"es": "ya está designado con otro rol en el partido", "en": "is already assigned another role in the match"},
+	"jornada_ocupada": {"es": "ya está designado en otro partido de la jornada", "en": "is already assigned to another match of the matchday"},
+	"misma_ciudad":    {"es": "es de la misma ciudad que uno de los equipos", "en": "is from the same city as one of the teams"},
+	// Reglas de las filas importadas desde una hoja de cálculo
+	"fecha":               {"es": "debe ser una fecha como 2024-08-31 o 31/08/2024", "en": "must be a date such as 2024-08-31 or 31/08/2024"},
+	"duplicado":           {"es": "está repetido en otra fila del archivo", "en": "is repeated in another row of the file"},
+	"columna_faltante":    {"es": "falta en el encabezado", "en": "is missing from the header"},
+	"columna_desconocida": {"es": "no es una columna que se pueda importar", "en": "is not a column that can be imported"},
+	"sin_filas":           {"es": "el archivo no tiene filas de datos", "en": "the file has no data rows"},
+	"equipo_inexistente":  {"es": "no es un equipo registrado", "en": "is not a registered team"},
+	"estadio_inexistente": {"es": "no es un estadio registrado", "en": "is not a registered venue"},
+	"numero_repetido":     {"es": "ya lo usa otro jugador del equipo", "en": "is already used by another player of the team"},
+	"equipos_iguales":     {"es": "debe ser distinto del equipo local", "en": "must be different from the home team"},
+	"equipo_en_jornada":   {"es": "ya juega otro partido en la jornada", "en": "already plays another match in the matchday"},
+	"partido_inexistente": {"es": "no hay un partido entre estos equipos en la jornada", "en": "there is no match between these teams in the matchday"},
+	"jugador_inexistente": {"es": "no es un jugador registrado del equipo", "en": "is not a registered player of the team"},
+	"equipo_ajeno":        {"es": "no juega el partido", "en": "does not play the match"},
+	"solo_sustitucion":    {"es": "solo se indica en una sustitución", "en": "only applies to a substitution"},
+}
+
+// ErrorCampo describe por qué no es válido un campo de la petición
+type ErrorCampo struct {
+	Campo     string `json:"campo"`
+	Regla     string `json:"regla"`
+	Mensaje   string `json:"mensaje"`
+	parametro string
+}
+
+// ErrorRespuesta es el formato uniforme de las respuestas de error de la API.
+// El campo "error" contiene el mensaje para mostrar, por compatibilidad con los clientes existentes.
+type ErrorRespuesta struct {
+	Error     string       `json:"error"`
+	Codigo    string       `json:"codigo"`
+	Campos    []ErrorCampo `json:"campos,omitempty"`
+	Detalle   any          `json:"detalle,omitempty"`
+	RequestID string       `json:"requestId"`
+}
+
+// errorValidacion agrupa los errores por campo detectados al leer una petición
+type errorValidacion struct {
+	codigo string
+	campos []ErrorCampo
+}
+
+func (e *errorValidacion) Error() string {
+	return e.codigo
+}
+
+func init() {
+	// Reportar los campos con su nombre JSON en lugar del nombre del struct
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(campo reflect.StructField) string {
+			nombre := strings.SplitN(campo.Tag.Get("json"), ",", 2)[0]
+			if nombre == "-" {
+				return ""
+			}
+			return nombre
+		})
+	}
+}
+
+// IdentificarPeticion asigna a cada petición un identificador, tomado del header
+// X-Request-ID si el cliente lo envía, y lo devuelve en la respuesta
+func IdentificarPeticion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+		if requestID == "" || len(requestID) > 64 {
+			bytes := make([]byte, 8)
+			rand.Read(bytes)
+			requestID = hex.EncodeToString(bytes)
+		}
+
+		c.Set("requestId", requestID)
+		c.Header("X-Request-ID", requestID)
+		c.Next()
+	}
+}
+
+// idioma elige el idioma de los mensajes según el header Accept-Language. Por defecto, español.
+func idioma(c *gin.Context) string {
+	for _, parte := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		etiqueta := strings.ToLower(strings.TrimSpace(strings.SplitN(parte, ";", 2)[0]))
+		if strings.HasPrefix(etiqueta, "es") {
+			return "es"
+		}
+		if strings.HasPrefix(etiqueta, "en") {
+			return "en"
+		}
+	}
+	return "es"
+}
+
+// responderError termina la petición con el formato uniforme de error
+func responderError(c *gin.Context, status int, codigo string, campos ...ErrorCampo) {
+	lang := idioma(c)
+
+	mensaje, ok := mensajesError[codigo][lang]
+	if !ok {
+		mensaje = mensajesError[ErrorInterno][lang]
+	}
+
+	for i := range campos {
+		campos[i].Mensaje = MensajeRegla(lang, campos[i].Regla, campos[i].parametro)
+	}
+
+	c.AbortWithStatusJSON(status, ErrorRespuesta{
+		Error:     mensaje,
+		Codigo:    codigo,
+		Campos:    campos,
+		RequestID: c.GetString("requestId"),
+	})
+}
+
+// responderErrorConDetalle termina la petición con el formato uniforme de error y un detalle
+// propio del código, como el informe de una importación
+func responderErrorConDetalle(c *gin.Context, status int, codigo string, detalle any) {
+	mensaje, ok := mensajesError[codigo][idioma(c)]
+	if !ok {
+		mensaje = mensajesError[ErrorInterno][idioma(c)]
+	}
+
+	c.AbortWithStatusJSON(status, ErrorRespuesta{
+		Error:     mensaje,
+		Codigo:    codigo,
+		Detalle:   detalle,
+		RequestID: c.GetString("requestId"),
+	})
+}
+
+// MensajeRegla describe en el idioma indicado ("es" o "en") una regla de validación;
+// parametro completa el mensaje de las reglas que lo necesitan, como "min"
+func MensajeRegla(lang, regla, parametro string) string {
+	if _, ok := mensajesRegla["valor"][lang]; !ok {
+		lang = "es"
+	}
+	plantilla := mensajesRegla[regla][lang]
+	if plantilla == "" {
+		plantilla = mensajesRegla["valor"][lang]
+	}
+	if strings.Contains(plantilla, "%s") {
+		return fmt.Sprintf(plantilla, parametro)
+	}
+	return plantilla
+}
+
+// responderErrorValidacion responde 400 con el detalle por campo de un error de lectura o validación
+func responderErrorValidacion(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	var validacion *errorValidacion
+	var erroresValidador validator.ValidationErrors
+	var errorTipo *json.UnmarshalTypeError
+	var errorSintaxis *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validacion):
+		responderError(c, status, validacion.codigo, validacion.campos...)
+	case errors.As(err, &erroresValidador):
+		campos := make([]ErrorCampo, 0, len(erroresValidador))
+		for _, fe := range erroresValidador {
+			regla := fe.Tag()
+			if (regla == "min" || regla == "max") && fe.Kind() == reflect.String {
+				regla += "_texto"
+			}
+			campos = append(campos, ErrorCampo{Campo: fe.Field(), Regla: regla, parametro: fe.Param()})
+		}
+		responderError(c, status, ErrorDatosInvalidos, campos...)
+	case errors.As(err, &errorTipo):
+		responderError(c, status, ErrorDatosInvalidos, ErrorCampo{Campo: errorTipo.Field, Regla: "tipo"})
+	case errors.As(err, &errorSintaxis), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		responderError(c, status, ErrorCuerpoInvalido)
+	default:
+		responderError(c, status, ErrorDatosInvalidos)
+	}
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// EstadioInput contiene los campos de un estadio nuevo
+type EstadioInput struct {
+	Nombre     string   `json:"nombre" binding:"required"`
+	Ciudad     string   `json:"ciudad"`
+	Capacidad  int      `json:"capacidad" binding:"min=0"`
+	Latitud    *float64 `json:"latitud" binding:"omitempty,min=-90,max=90"`
+	Longitud   *float64 `json:"longitud" binding:"omitempty,min=-180,max=180"`
+	Superficie string   `json:"superficie" binding:"omitempty,oneof=natural artificial hibrida"`
+}
+
+// EstadioPatch contiene los campos de un estadio que se pueden modificar con PATCH
+type EstadioPatch struct {
+	Nombre     *string  `json:"nombre" binding:"omitempty,min=1"`
+	Ciudad     *string  `json:"ciudad"`
+	Capacidad  *int     `json:"capacidad" binding:"omitempty,min=0"`
+	Latitud    *float64 `json:"latitud" binding:"omitempty,min=-90,max=90"`
+	Longitud   *float64 `json:"longitud" binding:"omitempty,min=-180,max=180"`
+	Superficie *string  `json:"superficie" binding:"omitempty,oneof=natural artificial hibrida"`
+}
+
+// camposPatchEstadio son los campos que acepta PATCH /estadios/:id
+var camposPatchEstadio = []string{"nombre", "ciudad", "capacidad", "latitud", "longitud", "superficie"}
+
+// camposAnulablesEstadio son los campos del estadio que se pueden borrar enviando null
+var camposAnulablesEstadio = []string{"latitud", "longitud", "superficie"}
+
+// aplicar copia en el estadio los campos enviados en el patch
+func (patch EstadioPatch) aplicar(estadio *models.Estadio, nulos map[string]bool) {
+	if patch.Nombre != nil {
+		estadio.Nombre = *patch.Nombre
+	}
+	if patch.Ciudad != nil {
+		estadio.Ciudad = *patch.Ciudad
+	}
+	if patch.Capacidad != nil {
+		estadio.Capacidad = *patch.Capacidad
+	}
+	if nulos["latitud"] {
+		estadio.Latitud = nil
+	} else if patch.Latitud != nil {
+		estadio.Latitud = patch.Latitud
+	}
+	if nulos["longitud"] {
+		estadio.Longitud = nil
+	} else if patch.Longitud != nil {
+		estadio.Longitud = patch.Longitud
+	}
+	if nulos["superficie"] {
+		estadio.Superficie = ""
+	} else if patch.Superficie != nil {
+		estadio.Superficie = *patch.Superficie
+	}
+}
+
+// ObtenerEstadios retorna la lista de todos los estadios
+func ObtenerEstadios(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewEstadioService(db)
+		estadios, err := service.GetEstadios()
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorObtenerEstadios)
+			return
+		}
+
+		c.JSON(http.StatusOK, estadios)
+	}
+}
+
+// ObtenerEstadio retorna un estadio específico por su ID
+func ObtenerEstadio(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEstadioInvalido)
+			return
+		}
+
+		service := services.NewEstadioService(db)
+		estadio, err := service.GetEstadio(uint(id))
+		if err != nil {
+			responderError(c, http.StatusNotFound, ErrorEstadioNoEncontrado)
+			return
+		}
+
+		escribirETag(c, estadio.Version)
+		c.JSON(http.StatusOK, estadio)
+	}
+}
+
+// CrearEstadio maneja la creación de un nuevo estadio
+func CrearEstadio(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input EstadioInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		service := services.NewEstadioService(db)
+		estadio, err := service.CrearEstadio(models.Estadio{
+			Nombre:     input.Nombre,
+			Ciudad:     input.Ciudad,
+			Capacidad:  input.Capacidad,
+			Latitud:    input.Latitud,
+			Longitud:   input.Longitud,
+			Superficie: input.Superficie,
+		})
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorCrearEstadio)
+			return
+		}
+
+		escribirETag(c, estadio.Version)
+		c.JSON(http.StatusCreated, gin.H{
+			"mensaje": "Estadio creado exitosamente",
+			"estadio": estadio,
+		})
+	}
+}
+
+// ModificarEstadio aplica un JSON Merge Patch sobre un estadio existente
+func ModificarEstadio(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEstadioInvalido)
+			return
+		}
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewEstadioService(db)
+		estadio, err := service.GetEstadio(uint(id))
+		if err != nil {
+			responderError(c, http.StatusNotFound, ErrorEstadioNoEncontrado)
+			return
+		}
+
+		if esperada != nil && *esperada != estadio.Version {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionEstadio)
+			return
+		}
+
+		var patch EstadioPatch
+		nulos, err := leerMergePatch(c, &patch, camposPatchEstadio, camposAnulablesEstadio)
+		if err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		patch.aplicar(&estadio, nulos)
+
+		estadio, err = service.ActualizarEstadio(estadio)
+		if errors.Is(err, services.ErrVersionObsoleta) {
+			responderError(c, http.StatusConflict, ErrorConflictoEstadio)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorActualizarEstadio)
+			return
+		}
+
+		escribirETag(c, estadio.Version)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Estadio actualizado exitosamente",
+			"estadio": estadio,
+		})
+	}
+}
+
+// EliminarEstadio elimina un estadio que no es la sede de ningún equipo ni partido
+func EliminarEstadio(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEstadioInvalido)
+			return
+		}
+
+		service := services.NewEstadioService(db)
+		err = service.EliminarEstadio(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorEstadioNoEncontrado)
+			return
+		}
+		if errors.Is(err, services.ErrEstadioEnUso) {
+			responderError(c, http.StatusConflict, ErrorEstadioEnUso)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorEliminarEstadio)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"mensaje": "Estadio eliminado exitosamente"})
+	}
+}
+
+// ObtenerAgendaEstadio retorna los partidos que se juegan en un estadio y los que coinciden
+// en el tiempo. Los parámetros "desde" y "hasta" aceptan una fecha (2006-01-02) o una fecha
+// y hora RFC 3339; "hasta" no se incluye.
+func ObtenerAgendaEstadio(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDEstadioInvalido)
+			return
+		}
+
+		var campos []ErrorCampo
+		desde, err := fechaQuery(c, "desde")
+		if err != nil {
+			campos = append(campos, ErrorCampo{Campo: "desde", Regla: "tipo"})
+		}
+		hasta, err := fechaQuery(c, "hasta")
+		if err != nil {
+			campos = append(campos, ErrorCampo{Campo: "hasta", Regla: "tipo"})
+		}
+		if len(campos) > 0 {
+			responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, campos...)
+			return
+		}
+
+		service := services.NewEstadioService(db)
+		agenda, err := service.GetAgendaEstadio(uint(id), desde, hasta)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorEstadioNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorAgendaEstadio)
+			return
+		}
+
+		c.JSON(http.StatusOK, agenda)
+	}
+}
+
+// fechaQuery lee un parámetro de la URL con una fecha o una fecha y hora RFC 3339.
+// Devuelve nil si el parámetro no se envió.
+func fechaQuery(c *gin.Context, nombre string) (*time.Time, error) {
+	valor := c.Query(nombre)
+	if valor == "" {
+		return nil, nil
+	}
+	fecha, err := time.Parse(time.RFC3339, valor)
+	if err != nil {
+		if fecha, err = time.Parse(time.DateOnly, valor); err != nil {
+			return nil, err
+		}
+	}
+	return &fecha, nil
+}
+
+// validarEstadio comprueba que exista el estadio indicado en el campo de la petición.
+// Si no existe responde el error y devuelve false.
+func validarEstadio(c *gin.Context, db *gorm.DB, id *uint, campo string) (models.Estadio, bool) {
+	var estadio models.Estadio
+	if id == nil {
+		return estadio, true
+	}
+	if err := db.First(&estadio, *id).Error; err != nil {
+		responderError(c, http.StatusBadRequest, ErrorEstadioInexistente, ErrorCampo{Campo: campo, Regla: "valor"})
+		return estadio, false
+	}
+	return estadio, true
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// tiposContenidoExportacion es el tipo MIME de cada formato de exportación
+var tiposContenidoExportacion = map[string]string{
+	services.FormatoCSV:    "text/csv; charset=utf-8",
+	services.FormatoNDJSON: "application/x-ndjson",
+}
+
+// ExportarDatos descarga todos los estadios, equipos, jugadores, partidos o incidencias en formato CSV
+// (por defecto) o NDJSON con ?formato=ndjson. Los CSV se pueden volver a importar. La base de
+// datos tiene un solo torneo, así que se exportan todos los datos, sin filtrar por temporada.
+func ExportarDatos(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tipo := c.Param("tipo")
+		formato := c.DefaultQuery("formato", services.FormatoCSV)
+		err := services.ValidarExportacion(tipo, formato)
+		if errors.Is(err, services.ErrTipoExportacion) {
+			responderError(c, http.StatusBadRequest, ErrorTipoExportacion)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, ErrorCampo{Campo: "formato", Regla: "oneof", parametro: "csv ndjson"})
+			return
+		}
+
+		c.Header("Content-Type", tiposContenidoExportacion[formato])
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, tipo, services.ExtensionFormato(formato)))
+
+		service := services.NewExportacionService(db)
+		if err := service.Exportar(c.Writer, tipo, formato); err != nil {
+			// Si ya se enviaron registros no se puede cambiar la respuesta; el cliente recibe un archivo incompleto
+			if !c.Writer.Written() {
+				// El error se responde en JSON y no como un archivo para descargar
+				c.Writer.Header().Del("Content-Type")
+				c.Writer.Header().Del("Content-Disposition")
+				responderError(c, http.StatusInternalServerError, ErrorExportar)
+				return
+			}
+			c.Error(err)
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// ErrorFilaImportada describe un valor inválido de una fila del archivo importado
+type ErrorFilaImportada struct {
+	Linea   int    `json:"linea"`
+	Campo   string `json:"campo,omitempty"`
+	Regla   string `json:"regla"`
+	Mensaje string `json:"mensaje"`
+	Valor   string `json:"valor,omitempty"`
+}
+
+// InformeImportacion es el resultado de una importación con sus errores descritos en el idioma de la petición
+type InformeImportacion struct {
+	services.ResultadoImportacion
+	Errores []ErrorFilaImportada `json:"errores"`
+}
+
+// NewInformeImportacion describe en el idioma indicado los errores de una importación
+func NewInformeImportacion(lang string, resultado services.ResultadoImportacion) InformeImportacion {
+	informe := InformeImportacion{
+		ResultadoImportacion: resultado,
+		Errores:              make([]ErrorFilaImportada, 0, len(resultado.Errores)),
+	}
+	for _, e := range resultado.Errores {
+		informe.Errores = append(informe.Errores, ErrorFilaImportada{
+			Linea:   e.Linea,
+			Campo:   e.Campo,
+			Regla:   e.Regla,
+			Mensaje: MensajeRegla(lang, e.Regla, e.Parametro),
+			Valor:   e.Valor,
+		})
+	}
+	return informe
+}
+
+// ImportarDatos importa estadios, equipos, jugadores, partidos o incidencias desde un archivo
+// CSV o XLSX enviado en el campo "archivo" de un formulario multipart. Por defecto solo valida
+// el archivo y devuelve lo que se importaría; con ?simular=false guarda todas las filas o ninguna.
+func ImportarDatos(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tipo := c.Param("tipo")
+		simular := true
+		if valor := c.Query("simular"); valor != "" {
+			var err error
+			if simular, err = strconv.ParseBool(valor); err != nil {
+				responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, ErrorCampo{Campo: "simular", Regla: "tipo"})
+				return
+			}
+		}
+
+		archivo, err := c.FormFile("archivo")
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorArchivoImportacion, ErrorCampo{Campo: "archivo", Regla: "required"})
+			return
+		}
+		contenido, err := archivo.Open()
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorArchivoImportacion)
+			return
+		}
+		defer contenido.Close()
+
+		filas, err := services.LeerTabla(archivo.Filename, contenido)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorArchivoImportacion)
+			return
+		}
+
+		service := services.NewImportacionService(db)
+		resultado, err := service.Importar(tipo, filas, simular)
+		if errors.Is(err, services.ErrTipoImportacion) {
+			responderError(c, http.StatusBadRequest, ErrorTipoImportacion)
+			return
+		}
+		if errors.Is(err, services.ErrImportacionInvalida) {
+			responderErrorConDetalle(c, http.StatusUnprocessableEntity, ErrorImportacionInvalida, NewInformeImportacion(idioma(c), resultado))
+			return
+		}
+		if errors.Is(err, services.ErrVersionObsoleta) || errors.Is(err, services.ErrConflictoPartido) {
+			responderError(c, http.StatusConflict, ErrorImportar)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorImportar)
+			return
+		}
+
+		mensaje := "Datos importados exitosamente"
+		if resultado.Simulacion {
+			mensaje = "Simulación de la importación, no se guardó ningún cambio"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje":   mensaje,
+			"resultado": NewInformeImportacion(idioma(c), resultado),
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// JornadaPatch contiene los campos de una jornada que se pueden modificar con PATCH
+type JornadaPatch struct {
+	Numero     *int       `json:"numero" binding:"omitempty,min=1"`
+	Fecha      *time.Time `json:"fecha"`
+	Completada *bool      `json:"completada"`
+}
+
+// camposPatchJornada son los campos que acepta PATCH /jornadas/:id; ninguno admite null
+var camposPatchJornada = []string{"numero", "fecha", "completada"}
+
+// aplicar copia en la jornada los campos enviados en el patch
+func (patch JornadaPatch) aplicar(jornada *models.Jornada) {
+	if patch.Numero != nil {
+		jornada.Numero = *patch.Numero
+	}
+	if patch.Fecha != nil {
+		jornada.Fecha = *patch.Fecha
+	}
+	if patch.Completada != nil {
+		jornada.Completada = *patch.Completada
+	}
+}
+
+// ModificarJornada aplica un JSON Merge Patch sobre una jornada existente
+func ModificarJornada(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var jornada models.Jornada
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		if err := db.First(&jornada, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorJornadaNoEncontrada)
+			return
+		}
+
+		if esperada != nil && *esperada != jornada.Version {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionJornada)
+			return
+		}
+
+		var patch JornadaPatch
+		if _, err := leerMergePatch(c, &patch, camposPatchJornada, nil); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		if patch.Numero != nil && *patch.Numero != jornada.Numero {
+			var existentes int64
+			if err := db.Model(&models.Jornada{}).Where("numero = ?", *patch.Numero).Count(&existentes).Error; err != nil || existentes > 0 {
+				responderError(c, http.StatusConflict, ErrorJornadaDuplicada)
+				return
+			}
+		}
+		patch.aplicar(&jornada)
+
+		service := services.NewCalendarioService(db)
+		jornada, err = service.ActualizarJornada(jornada)
+		if errors.Is(err, services.ErrVersionObsoleta) {
+			responderError(c, http.StatusConflict, ErrorConflictoJornada)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorActualizarJornada)
+			return
+		}
+
+		escribirETag(c, jornada.Version)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Jornada actualizada exitosamente",
+			"jornada": jornada,
+		})
+	}
+}
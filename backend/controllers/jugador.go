@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// JugadorPatch contiene los campos de un jugador que se pueden modificar con PATCH
+type JugadorPatch struct {
+	Nombre          *string    `json:"nombre" binding:"omitempty,min=1,max=100"`
+	Apellido        *string    `json:"apellido" binding:"omitempty,min=1,max=100"`
+	FechaNacimiento *time.Time `json:"fechaNacimiento"`
+	Nacionalidad    *string    `json:"nacionalidad" binding:"omitempty,max=50"`
+	Posicion        *string    `json:"posicion" binding:"omitempty,max=50"`
+	Numero          *int       `json:"numero" binding:"omitempty,min=1,max=99"`
+	Altura          *float64   `json:"altura" binding:"omitempty,gt=0"`
+	Peso            *float64   `json:"peso" binding:"omitempty,gt=0"`
+	Foto            *string    `json:"foto" binding:"omitempty,max=255"`
+	EquipoID        *uint      `json:"equipoId" binding:"omitempty,min=1"`
+}
+
+// camposPatchJugador son los campos que acepta PATCH /jugadores/:id
+var camposPatchJugador = []string{"nombre", "apellido", "fechaNacimiento", "nacionalidad", "posicion", "numero", "altura", "peso", "foto", "equipoId"}
+
+// camposAnulablesJugador son los campos del jugador que se pueden borrar enviando null
+var camposAnulablesJugador = []string{"fechaNacimiento", "nacionalidad", "posicion", "numero", "altura", "peso", "foto"}
+
+// aplicar copia en el jugador los campos enviados en el patch y borra los enviados con null
+func (patch JugadorPatch) aplicar(jugador *models.Jugador, nulos map[string]bool) {
+	if patch.Nombre != nil {
+		jugador.Nombre = *patch.Nombre
+	}
+	if patch.Apellido != nil {
+		jugador.Apellido = *patch.Apellido
+	}
+	if nulos["fechaNacimiento"] {
+		jugador.FechaNacimiento = time.Time{}
+	} else if patch.FechaNacimiento != nil {
+		jugador.FechaNacimiento = *patch.FechaNacimiento
+	}
+	if nulos["nacionalidad"] {
+		jugador.Nacionalidad = ""
+	} else if patch.Nacionalidad != nil {
+		jugador.Nacionalidad = *patch.Nacionalidad
+	}
+	if nulos["posicion"] {
+		jugador.Posicion = ""
+	} else if patch.Posicion != nil {
+		jugador.Posicion = *patch.Posicion
+	}
+	if nulos["numero"] {
+		jugador.Numero = 0
+	} else if patch.Numero != nil {
+		jugador.Numero = *patch.Numero
+	}
+	if nulos["altura"] {
+		jugador.Altura = 0
+	} else if patch.Altura != nil {
+		jugador.Altura = *patch.Altura
+	}
+	if nulos["peso"] {
+		jugador.Peso = 0
+	} else if patch.Peso != nil {
+		jugador.Peso = *patch.Peso
+	}
+	if nulos["foto"] {
+		jugador.Foto = ""
+	} else if patch.Foto != nil {
+		jugador.Foto = *patch.Foto
+	}
+	if patch.EquipoID != nil {
+		jugador.EquipoID = *patch.EquipoID
+	}
+}
+
+// ModificarJugador aplica un JSON Merge Patch sobre un jugador existente
+func ModificarJugador(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDJugadorInvalido)
+			return
+		}
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewJugadorService(db)
+		jugador, err := service.GetJugadorByID(uint(id))
+		if err != nil {
+			responderError(c, http.StatusNotFound, ErrorJugadorNoEncontrado)
+			return
+		}
+
+		if esperada != nil && *esperada != jugador.Version {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionJugador)
+			return
+		}
+
+		var patch JugadorPatch
+		nulos, err := leerMergePatch(c, &patch, camposPatchJugador, camposAnulablesJugador)
+		if err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		if patch.EquipoID != nil {
+			var equipo models.Equipo
+			if err := db.First(&equipo, *patch.EquipoID).Error; err != nil {
+				responderError(c, http.StatusBadRequest, ErrorEquipoInexistente)
+				return
+			}
+		}
+		patch.aplicar(&jugador, nulos)
+
+		jugador, err = service.UpdateJugador(jugador)
+		if errors.Is(err, services.ErrVersionObsoleta) {
+			responderError(c, http.StatusConflict, ErrorConflictoJugador)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorActualizarJugador)
+			return
+		}
+
+		escribirETag(c, jugador.Version)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Jugador actualizado exitosamente",
+			"jugador": jugador,
+		})
+	}
+}
+
+// ObtenerPartidosJugador retorna el historial de partidos de un jugador con sus splits.
+// Solo incluye los partidos en los que el jugador tiene alguna incidencia o entró en una
+// sustitución; los titulares sin incidencias no suman partidos ni minutos.
+func ObtenerPartidosJugador(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDJugadorInvalido)
+			return
+		}
+
+		service := services.NewJugadorService(db)
+		if _, err := service.GetJugadorByID(uint(id)); err != nil {
+			responderError(c, http.StatusNotFound, ErrorJugadorNoEncontrado)
+			return
+		}
+
+		registro, err := service.GetPartidosJugador(uint(id))
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorPartidosJugador)
+			return
+		}
+
+		c.JSON(http.StatusOK, registro)
+	}
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// Tipos de mensajes del canal de operadores
+const (
+	MensajeIncidencia = "incidencia"
+	MensajeMarcador   = "marcador"
+	MensajeEstado     = "estado"
+	MensajeConflicto  = "conflicto"
+	MensajeError      = "error"
+)
+
+// upgrader acepta conexiones de cualquier origen: la autenticación se hace con el token
+// JWT del query string y no con cookies, así que otro sitio no puede suplantar al operador
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// MensajeOperador es el formato de los mensajes que se intercambian por el canal de operadores.
+// Secuencia es la última secuencia del partido que conoce el operador al enviar el mensaje.
+type MensajeOperador struct {
+	Tipo           string                  `json:"tipo"`
+	Secuencia      uint                    `json:"secuencia"`
+	Operador       string                  `json:"operador,omitempty"`
+	Mensaje        string                  `json:"mensaje,omitempty"`
+	Incidencia     *IncidenciaInput        `json:"incidencia,omitempty"`
+	GolesLocal     int                     `json:"golesLocal"`
+	GolesVisitante int                     `json:"golesVisitante"`
+	Evento         *services.EventoPartido `json:"evento,omitempty"`
+	Partido        *models.Partido         `json:"partido,omitempty"`
+}
+
+// CanalOperadores abre un WebSocket por partido para que los operadores registren incidencias
+// y reciban en tiempo real los cambios hechos por los demás
+func CanalOperadores(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		var partido models.Partido
+		if err := db.First(&partido, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		eventos := services.EventosEnVivo.Suscribir()
+		defer services.EventosEnVivo.Cancelar(eventos)
+
+		// Enviar el estado actual y, si el operador se reconecta, las incidencias que se perdió
+		if err := conn.WriteJSON(MensajeOperador{Tipo: MensajeEstado, Secuencia: partido.Secuencia, Partido: &partido}); err != nil {
+			return
+		}
+		if desde, err := strconv.ParseUint(c.Query("desde"), 10, 64); err == nil {
+			incidencias, err := service.GetIncidenciasDesde(partido.ID, uint(desde))
+			if err != nil {
+				return
+			}
+			for _, incidencia := range incidencias {
+				evento := services.EventoPartido{
+					Tipo:       services.EventoIncidencia,
+					PartidoID:  partido.ID,
+					JornadaID:  partido.JornadaID,
+					Secuencia:  incidencia.Secuencia,
+					Incidencia: &incidencia,
+				}
+				if err := conn.WriteJSON(MensajeOperador{Tipo: MensajeIncidencia, Secuencia: evento.Secuencia, Evento: &evento}); err != nil {
+					return
+				}
+			}
+		}
+
+		operador := c.GetString("usuarioEmail")
+		respuestas := make(chan MensajeOperador, 16)
+		cerrado := make(chan struct{})
+		terminado := make(chan struct{})
+		defer close(terminado)
+
+		// Leer los mensajes del operador en una goroutine aparte; solo el bucle principal escribe
+		go func() {
+			defer close(cerrado)
+			for {
+				var mensaje MensajeOperador
+				if err := conn.ReadJSON(&mensaje); err != nil {
+					return
+				}
+
+				respuesta, ok := procesarMensajeOperador(service, partido.ID, mensaje)
+				if !ok {
+					continue
+				}
+				respuesta.Operador = operador
+
+				select {
+				case respuestas <- respuesta:
+				case <-terminado:
+					return
+				}
+			}
+		}()
+
+		ping := time.NewTicker(intervaloPing)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-cerrado:
+				return
+			case evento, ok := <-eventos:
+				if !ok {
+					return
+				}
+				if evento.PartidoID != partido.ID {
+					continue
+				}
+				if err := conn.WriteJSON(MensajeOperador{Tipo: evento.Tipo, Secuencia: evento.Secuencia, Evento: &evento}); err != nil {
+					return
+				}
+			case respuesta := <-respuestas:
+				if err := conn.WriteJSON(respuesta); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// procesarMensajeOperador aplica un mensaje del operador. Los cambios aceptados llegan a todos
+// los operadores como eventos, así que solo se responde directamente en caso de conflicto o error.
+func procesarMensajeOperador(service *services.CalendarioService, partidoID uint, mensaje MensajeOperador) (MensajeOperador, bool) {
+	var err error
+	switch mensaje.Tipo {
+	case MensajeIncidencia:
+		if mensaje.Incidencia == nil || mensaje.Incidencia.JugadorID == 0 || !mensaje.Incidencia.Tipo.Valido() ||
+			(mensaje.Incidencia.JugadorEntraID != 0 && mensaje.Incidencia.Tipo != models.Sustitucion) {
+			return MensajeOperador{Tipo: MensajeError, Mensaje: "Datos de la incidencia inválidos"}, true
+		}
+		_, err = service.RegistrarIncidenciaEnSecuencia(models.Incidencia{
+			PartidoID:      partidoID,
+			JugadorID:      mensaje.Incidencia.JugadorID,
+			JugadorEntraID: mensaje.Incidencia.jugadorEntra(),
+			Tipo:           mensaje.Incidencia.Tipo,
+			Minuto:         mensaje.Incidencia.Minuto,
+			MinutoAnadido:  mensaje.Incidencia.MinutoAnadido,
+			Descripcion:    mensaje.Incidencia.Descripcion,
+			Timestamp:      time.Now(),
+		}, mensaje.Secuencia)
+	case MensajeMarcador:
+		if mensaje.GolesLocal < 0 || mensaje.GolesVisitante < 0 {
+			return MensajeOperador{Tipo: MensajeError, Mensaje: "Marcador inválido"}, true
+		}
+		_, err = service.ActualizarMarcador(partidoID, &mensaje.Secuencia, mensaje.GolesLocal, mensaje.GolesVisitante)
+	default:
+		return MensajeOperador{Tipo: MensajeError, Mensaje: "Tipo de mensaje desconocido"}, true
+	}
+
+	if err == nil {
+		return MensajeOperador{}, false
+	}
+
+	if errors.Is(err, services.ErrConflictoPartido) {
+		// Devolver el estado actual para que el operador revise antes de reenviar
+		partido, err := service.Calendario.ObtenerPartido(partidoID)
+		if err != nil {
+			return MensajeOperador{Tipo: MensajeError, Mensaje: "Error al obtener el partido"}, true
+		}
+		return MensajeOperador{
+			Tipo:           MensajeConflicto,
+			Secuencia:      partido.Secuencia,
+			Mensaje:        "El partido fue modificado por otro operador desde la secuencia enviada",
+			Partido:        &partido,
+			Incidencia:     mensaje.Incidencia,
+			GolesLocal:     mensaje.GolesLocal,
+			GolesVisitante: mensaje.GolesVisitante,
+		}, true
+	}
+
+	if errors.Is(err, services.ErrJugadorAjeno) {
+		return MensajeOperador{Tipo: MensajeError, Mensaje: "El jugador no pertenece a ninguno de los equipos del partido"}, true
+	}
+
+	return MensajeOperador{Tipo: MensajeError, Mensaje: "Error al registrar el cambio"}, true
+}
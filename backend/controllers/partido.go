@@ -0,0 +1,734 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// intervaloPing es el tiempo entre mensajes de keep-alive en las transmisiones en vivo
+const intervaloPing = 15 * time.Second
+
+type EstadoInput struct {
+	Estado string `json:"estado" binding:"required,oneof=pendiente en_curso finalizado"`
+}
+
+type MarcadorInput struct {
+	GolesLocal     int `json:"golesLocal" binding:"min=0"`
+	GolesVisitante int `json:"golesVisitante" binding:"min=0"`
+}
+
+type TiempoAnadidoInput struct {
+	Minutos int `json:"minutos" binding:"min=0,max=30"`
+}
+
+type IncidenciaInput struct {
+	JugadorID      uint                  `json:"jugadorId" binding:"required"`
+	Tipo           models.TipoIncidencia `json:"tipo" binding:"required"`
+	Minuto         int                   `json:"minuto" binding:"min=0"` // 0 para usar el minuto del reloj
+	MinutoAnadido  int                   `json:"minutoAnadido" binding:"min=0"`
+	Descripcion    string                `json:"descripcion" binding:"max=255"`
+	JugadorEntraID uint                  `json:"jugadorEntraId"` // Jugador que entra al campo en una sustitución
+}
+
+// jugadorEntra retorna el jugador que entra en la sustitución, o nil si no se indicó
+func (input IncidenciaInput) jugadorEntra() *uint {
+	if input.JugadorEntraID == 0 {
+		return nil
+	}
+	return &input.JugadorEntraID
+}
+
+type AplazarInput struct {
+	Motivo string `json:"motivo" binding:"max=255"`
+}
+
+type ReprogramarInput struct {
+	FechaHora *time.Time `json:"fechaHora" binding:"required"`
+	JornadaID uint       `json:"jornadaId"` // Opcional, para mover el partido a otra jornada
+	Motivo    string     `json:"motivo" binding:"max=255"`
+}
+
+// PartidoPatch contiene los datos administrativos de un partido que se pueden modificar con PATCH.
+// El marcador, el estado y los periodos tienen sus propios endpoints.
+type PartidoPatch struct {
+	JornadaID         *uint      `json:"jornadaId" binding:"omitempty,min=1"`
+	EquipoLocalID     *uint      `json:"equipoLocalId" binding:"omitempty,min=1"`
+	EquipoVisitanteID *uint      `json:"equipoVisitanteId" binding:"omitempty,min=1"`
+	FechaHora         *time.Time `json:"fechaHora"`
+	EstadioID         *uint      `json:"estadioId" binding:"omitempty,min=1"` // null para jugar en el estadio del local
+}
+
+// camposPatchPartido son los campos que acepta PATCH /partidos/:id
+var camposPatchPartido = []string{"jornadaId", "equipoLocalId", "equipoVisitanteId", "fechaHora", "estadioId"}
+
+// camposAnulablesPartido son los campos del partido que se pueden borrar enviando null
+var camposAnulablesPartido = []string{"estadioId"}
+
+// aplicar copia en el partido los campos enviados en el patch
+func (patch PartidoPatch) aplicar(partido *models.Partido, nulos map[string]bool) {
+	if patch.JornadaID != nil {
+		partido.JornadaID = *patch.JornadaID
+	}
+	if patch.EquipoLocalID != nil {
+		partido.EquipoLocalID = *patch.EquipoLocalID
+	}
+	if patch.EquipoVisitanteID != nil {
+		partido.EquipoVisitanteID = *patch.EquipoVisitanteID
+	}
+	if patch.FechaHora != nil {
+		partido.FechaHora = *patch.FechaHora
+	}
+	if nulos["estadioId"] {
+		partido.EstadioID = nil
+	} else if patch.EstadioID != nil {
+		partido.EstadioID = patch.EstadioID
+	}
+}
+
+// MetadatosPartidoPatch contiene los datos informativos de un partido que pueden editar
+// los editores; todos se pueden borrar enviando null
+type MetadatosPartidoPatch struct {
+	Espectadores *int    `json:"espectadores" binding:"omitempty,min=0"`
+	Transmision  *string `json:"transmision" binding:"omitempty,max=100"`
+	Clima        *string `json:"clima" binding:"omitempty,max=100"`
+	Cronica      *string `json:"cronica"`
+	ResumenURL   *string `json:"resumenUrl" binding:"omitempty,url,max=255"`
+}
+
+// camposMetadatosPartido son los campos que acepta PATCH /partidos/:id/metadatos
+var camposMetadatosPartido = []string{"espectadores", "transmision", "clima", "cronica", "resumenUrl"}
+
+// aplicar copia en el partido los datos enviados en el patch
+func (patch MetadatosPartidoPatch) aplicar(partido *models.Partido, nulos map[string]bool) {
+	if nulos["espectadores"] {
+		partido.Espectadores = nil
+	} else if patch.Espectadores != nil {
+		partido.Espectadores = patch.Espectadores
+	}
+	if nulos["transmision"] {
+		partido.Transmision = ""
+	} else if patch.Transmision != nil {
+		partido.Transmision = *patch.Transmision
+	}
+	if nulos["clima"] {
+		partido.Clima = ""
+	} else if patch.Clima != nil {
+		partido.Clima = *patch.Clima
+	}
+	if nulos["cronica"] {
+		partido.Cronica = ""
+	} else if patch.Cronica != nil {
+		partido.Cronica = *patch.Cronica
+	}
+	if nulos["resumenUrl"] {
+		partido.ResumenURL = ""
+	} else if patch.ResumenURL != nil {
+		partido.ResumenURL = *patch.ResumenURL
+	}
+}
+
+// ObtenerPartido retorna un partido con sus incidencias y sus datos informativos
+func ObtenerPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err := service.GetPartidoByID(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorObtenerPartido)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, partido)
+	}
+}
+
+// ModificarMetadatosPartido aplica un JSON Merge Patch sobre la asistencia, la transmisión,
+// el clima, la crónica y el resumen en video de un partido
+func ModificarMetadatosPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		var partido models.Partido
+		if err := db.First(&partido, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+
+		if esperada != nil && *esperada != partido.Secuencia {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionPartido)
+			return
+		}
+
+		var patch MetadatosPartidoPatch
+		nulos, err := leerMergePatch(c, &patch, camposMetadatosPartido, camposMetadatosPartido)
+		if err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		patch.aplicar(&partido, nulos)
+
+		service := services.NewCalendarioService(db)
+		partido, err = service.ActualizarPartido(partido)
+		if err != nil {
+			responderErrorPartido(c, err, ErrorActualizarPartido)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Partido actualizado exitosamente",
+			"partido": partido,
+		})
+	}
+}
+
+// ModificarPartido aplica un JSON Merge Patch sobre los datos administrativos de un partido
+func ModificarPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var partido models.Partido
+
+		esperada, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		if err := db.First(&partido, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+
+		if esperada != nil && *esperada != partido.Secuencia {
+			responderError(c, http.StatusPreconditionFailed, ErrorVersionPartido)
+			return
+		}
+
+		var patch PartidoPatch
+		nulos, err := leerMergePatch(c, &patch, camposPatchPartido, camposAnulablesPartido)
+		if err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		patch.aplicar(&partido, nulos)
+
+		if partido.EquipoLocalID == partido.EquipoVisitanteID {
+			responderError(c, http.StatusBadRequest, ErrorEquiposIguales)
+			return
+		}
+		var equipos int64
+		if err := db.Model(&models.Equipo{}).
+			Where("id IN ?", []uint{partido.EquipoLocalID, partido.EquipoVisitanteID}).
+			Count(&equipos).Error; err != nil || equipos != 2 {
+			responderError(c, http.StatusBadRequest, ErrorEquiposInexistentes)
+			return
+		}
+		if patch.JornadaID != nil {
+			var jornada models.Jornada
+			if err := db.First(&jornada, partido.JornadaID).Error; err != nil {
+				responderError(c, http.StatusBadRequest, ErrorJornadaInexistente)
+				return
+			}
+		}
+		if _, ok := validarEstadio(c, db, patch.EstadioID, "estadioId"); !ok {
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err = service.ActualizarPartido(partido)
+		if err != nil {
+			responderErrorPartido(c, err, ErrorActualizarPartido)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Partido actualizado exitosamente",
+			"partido": partido,
+		})
+	}
+}
+
+// CambiarEstadoPartido actualiza el estado de un partido
+func CambiarEstadoPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input EstadoInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		secuencia, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err := service.CambiarEstadoPartido(uint(id), secuencia, input.Estado)
+		if err != nil {
+			responderErrorPartido(c, err, ErrorActualizarEstado)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Estado del partido actualizado exitosamente",
+			"partido": partido,
+		})
+	}
+}
+
+// ActualizarMarcador actualiza el marcador de un partido
+func ActualizarMarcador(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input MarcadorInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		secuencia, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err := service.ActualizarMarcador(uint(id), secuencia, input.GolesLocal, input.GolesVisitante)
+		if err != nil {
+			responderErrorPartido(c, err, ErrorActualizarMarcador)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Marcador actualizado exitosamente",
+			"partido": partido,
+		})
+	}
+}
+
+// RegistrarIncidencia registra una nueva incidencia en un partido
+func RegistrarIncidencia(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input IncidenciaInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+		if !input.Tipo.Valido() {
+			responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, ErrorCampo{Campo: "tipo", Regla: "valor"})
+			return
+		}
+		if input.JugadorEntraID != 0 && input.Tipo != models.Sustitucion {
+			responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, ErrorCampo{Campo: "jugadorEntraId", Regla: "valor"})
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		incidencia, err := service.RegistrarIncidencia(models.Incidencia{
+			PartidoID:      uint(id),
+			JugadorID:      input.JugadorID,
+			JugadorEntraID: input.jugadorEntra(),
+			Tipo:           input.Tipo,
+			Minuto:         input.Minuto,
+			MinutoAnadido:  input.MinutoAnadido,
+			Descripcion:    input.Descripcion,
+			Timestamp:      time.Now(),
+		})
+		if err != nil {
+			responderErrorPartido(c, err, ErrorRegistrarIncidencia)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"mensaje":    "Incidencia registrada exitosamente",
+			"incidencia": incidencia,
+		})
+	}
+}
+
+// IniciarPeriodo inicia el siguiente periodo de juego de un partido
+func IniciarPeriodo(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err := service.IniciarPeriodo(uint(id))
+		if err != nil {
+			responderErrorPartido(c, err, ErrorIniciarPeriodo)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Periodo iniciado exitosamente",
+			"partido": partido,
+			"reloj":   services.CalcularReloj(partido, time.Now()),
+		})
+	}
+}
+
+// FinalizarPeriodo termina el periodo de juego en curso de un partido
+func FinalizarPeriodo(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err := service.FinalizarPeriodo(uint(id))
+		if err != nil {
+			responderErrorPartido(c, err, ErrorFinalizarPeriodo)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Periodo finalizado exitosamente",
+			"partido": partido,
+			"reloj":   services.CalcularReloj(partido, time.Now()),
+		})
+	}
+}
+
+// FijarTiempoAnadido registra el tiempo añadido del periodo en curso
+func FijarTiempoAnadido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input TiempoAnadidoInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		secuencia, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, err := service.FijarTiempoAnadido(uint(id), secuencia, input.Minutos)
+		if err != nil {
+			responderErrorPartido(c, err, ErrorTiempoAnadido)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje": "Tiempo añadido registrado exitosamente",
+			"partido": partido,
+			"reloj":   services.CalcularReloj(partido, time.Now()),
+		})
+	}
+}
+
+// ObtenerReloj retorna el minuto de juego actual de un partido
+func ObtenerReloj(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		var partido models.Partido
+
+		if err := db.First(&partido, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+
+		c.JSON(http.StatusOK, services.CalcularReloj(partido, time.Now()))
+	}
+}
+
+// TransmitirPartido envía por Server-Sent Events los cambios de un partido en vivo
+func TransmitirPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var partido models.Partido
+		if err := db.First(&partido, id).Error; err != nil {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+
+		eventos := services.EventosEnVivo.Suscribir()
+		defer services.EventosEnVivo.Cancelar(eventos)
+
+		// Enviar primero el estado actual del partido
+		transmitirEventos(c, eventos, []models.Partido{partido}, func(evento services.EventoPartido) bool {
+			return evento.PartidoID == partido.ID
+		})
+	}
+}
+
+// TransmitirJornadaActual envía por Server-Sent Events los cambios de los partidos de la jornada actual
+func TransmitirJornadaActual(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		service := services.NewCalendarioService(db)
+		jornada, err := service.GetJornadaActual(time.Now())
+		if errors.Is(err, services.ErrSinJornadaActual) {
+			responderError(c, http.StatusNotFound, ErrorSinJornadaEnJuego)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorPartidosEnCurso)
+			return
+		}
+
+		partidos, err := service.GetPartidosEnCurso(jornada.ID)
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorPartidosEnCurso)
+			return
+		}
+
+		eventos := services.EventosEnVivo.Suscribir()
+		defer services.EventosEnVivo.Cancelar(eventos)
+
+		// Enviar primero el estado actual de los partidos en curso
+		transmitirEventos(c, eventos, partidos, func(evento services.EventoPartido) bool {
+			return evento.JornadaID == jornada.ID
+		})
+	}
+}
+
+// transmitirEventos envía un evento de estado por cada partido inicial, con la misma forma que
+// los eventos en vivo, y después reenvía al cliente los eventos que cumplen el filtro hasta que
+// se desconecte
+func transmitirEventos(c *gin.Context, eventos chan services.EventoPartido, iniciales []models.Partido, filtro func(services.EventoPartido) bool) {
+	ping := time.NewTicker(intervaloPing)
+	defer ping.Stop()
+
+	// Los headers van antes del primer evento, que los envía. Si no hay estado inicial se
+	// envían igual, así el cliente sabe que la conexión está abierta.
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	for _, partido := range iniciales {
+		c.SSEvent(services.EventoEstado, services.NuevoEventoPartido(services.EventoEstado, partido))
+	}
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case evento, ok := <-eventos:
+			if !ok {
+				return false
+			}
+			if filtro(evento) {
+				c.SSEvent(evento.Tipo, evento)
+			}
+			return true
+		case <-ping.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
+
+// AplazarPartido deja un partido pendiente en estado aplazado y lo registra en su historial
+func AplazarPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input AplazarInput
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		secuencia, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		partido, reprogramacion, err := service.AplazarPartido(uint(id), secuencia, input.Motivo, c.GetString("usuarioEmail"))
+		if err != nil {
+			responderErrorPartido(c, err, ErrorAplazarPartido)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje":        "Partido aplazado exitosamente",
+			"partido":        partido,
+			"reprogramacion": reprogramacion,
+		})
+	}
+}
+
+// ReprogramarPartido asigna una nueva fecha, y opcionalmente otra jornada, a un partido
+// pendiente o aplazado. Ninguno de los equipos puede tener otro partido ese día en la zona
+// horaria de la liga.
+func ReprogramarPartido(db *gorm.DB, liga config.Liga) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		var input ReprogramarInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			responderErrorValidacion(c, err)
+			return
+		}
+
+		secuencia, err := versionIfMatch(c)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIfMatchInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		service.ZonaHoraria = liga.ZonaHoraria
+		partido, reprogramacion, err := service.ReprogramarPartido(uint(id), secuencia, services.Reprogramacion{
+			FechaHora: *input.FechaHora,
+			JornadaID: input.JornadaID,
+			Motivo:    input.Motivo,
+			Usuario:   c.GetString("usuarioEmail"),
+		})
+		var conflictoFecha *services.ErrConflictoFecha
+		if errors.As(err, &conflictoFecha) {
+			responderError(c, http.StatusConflict, ErrorFechaOcupada, ErrorCampo{Campo: "fechaHora", Regla: "dia_ocupado"})
+			return
+		}
+		if errors.Is(err, services.ErrJornadaInexistente) {
+			responderError(c, http.StatusBadRequest, ErrorJornadaInexistente, ErrorCampo{Campo: "jornadaId", Regla: "valor"})
+			return
+		}
+		if err != nil {
+			responderErrorPartido(c, err, ErrorReprogramarPartido)
+			return
+		}
+
+		escribirETag(c, partido.Secuencia)
+		c.JSON(http.StatusOK, gin.H{
+			"mensaje":        "Partido reprogramado exitosamente",
+			"partido":        partido,
+			"reprogramacion": reprogramacion,
+		})
+	}
+}
+
+// ObtenerReprogramaciones devuelve el historial de aplazamientos y cambios de fecha de un partido
+func ObtenerReprogramaciones(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewCalendarioService(db)
+		reprogramaciones, err := service.GetReprogramaciones(uint(id))
+		if err != nil {
+			responderErrorPartido(c, err, ErrorReprogramaciones)
+			return
+		}
+
+		c.JSON(http.StatusOK, reprogramaciones)
+	}
+}
+
+// responderErrorPartido traduce los errores del servicio de calendario a respuestas HTTP.
+// Un conflicto de secuencia responde 412 si el cliente envió If-Match y 409 si fue una escritura concurrente.
+func responderErrorPartido(c *gin.Context, err error, codigo string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+		return
+	}
+	if errors.Is(err, services.ErrConflictoPartido) && c.GetHeader("If-Match") != "" {
+		responderError(c, http.StatusPreconditionFailed, ErrorVersionPartido)
+		return
+	}
+	if errors.Is(err, services.ErrConflictoPartido) {
+		responderError(c, http.StatusConflict, ErrorConflictoPartido)
+		return
+	}
+	if errors.Is(err, services.ErrJugadorIncidencia) {
+		responderError(c, http.StatusNotFound, ErrorJugadorNoEncontrado)
+		return
+	}
+	if errors.Is(err, services.ErrJugadorAjeno) {
+		responderError(c, http.StatusUnprocessableEntity, ErrorJugadorAjeno)
+		return
+	}
+	if errors.Is(err, services.ErrTransicionPeriodo) {
+		responderError(c, http.StatusConflict, ErrorTransicionPeriodo)
+		return
+	}
+	if errors.Is(err, services.ErrPartidoNoReprogramable) {
+		responderError(c, http.StatusConflict, ErrorPartidoNoReprogramable)
+		return
+	}
+	responderError(c, http.StatusInternalServerError, codigo)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// leerMergePatch lee un documento JSON Merge Patch (RFC 7396) en destino, que debe ser un DTO
+// con campos puntero. Solo se aceptan los campos de permitidos, y solo los de anulables pueden
+// enviarse con null para borrar su valor. Devuelve el conjunto de campos enviados con null.
+func leerMergePatch(c *gin.Context, destino interface{}, permitidos, anulables []string) (map[string]bool, error) {
+	cuerpo, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, &errorValidacion{codigo: ErrorCuerpoInvalido}
+	}
+
+	var campos map[string]json.RawMessage
+	if err := json.Unmarshal(cuerpo, &campos); err != nil || campos == nil {
+		return nil, &errorValidacion{codigo: ErrorCuerpoInvalido}
+	}
+
+	nulos := make(map[string]bool)
+	var invalidos []ErrorCampo
+	for campo, valor := range campos {
+		if !slices.Contains(permitidos, campo) {
+			invalidos = append(invalidos, ErrorCampo{Campo: campo, Regla: "no_permitido"})
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(valor), []byte("null")) {
+			if !slices.Contains(anulables, campo) {
+				invalidos = append(invalidos, ErrorCampo{Campo: campo, Regla: "no_anulable"})
+				continue
+			}
+			nulos[campo] = true
+		}
+	}
+	if len(invalidos) > 0 {
+		sort.Slice(invalidos, func(i, j int) bool { return invalidos[i].Campo < invalidos[j].Campo })
+		return nil, &errorValidacion{codigo: ErrorDatosInvalidos, campos: invalidos}
+	}
+
+	if err := json.Unmarshal(cuerpo, destino); err != nil {
+		return nil, err
+	}
+	if err := binding.Validator.ValidateStruct(destino); err != nil {
+		return nil, err
+	}
+
+	return nulos, nil
+}
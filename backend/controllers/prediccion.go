@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noisk8/torneas/backend/services"
+	"gorm.io/gorm"
+)
+
+// ObtenerPredicciones retorna la probabilidad de victoria local, empate y victoria visitante
+// de los partidos que faltan por jugar. El parámetro "jornada" limita la respuesta a los
+// partidos de la jornada con ese número.
+func ObtenerPredicciones(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		numero := 0
+		if valor := c.Query("jornada"); valor != "" {
+			var err error
+			if numero, err = strconv.Atoi(valor); err != nil || numero <= 0 {
+				responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, ErrorCampo{Campo: "jornada", Regla: "tipo"})
+				return
+			}
+		}
+
+		service := services.NewPrediccionService(db)
+		predicciones, err := service.PredecirPartidos(numero)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorJornadaNoEncontrada)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorPrediccion)
+			return
+		}
+
+		c.JSON(http.StatusOK, predicciones)
+	}
+}
+
+// ObtenerPrediccionPartido retorna la predicción del resultado de un partido
+func ObtenerPrediccionPartido(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			responderError(c, http.StatusBadRequest, ErrorIDPartidoInvalido)
+			return
+		}
+
+		service := services.NewPrediccionService(db)
+		prediccion, err := service.PredecirPartido(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responderError(c, http.StatusNotFound, ErrorPartidoNoEncontrado)
+			return
+		}
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorPrediccion)
+			return
+		}
+
+		c.JSON(http.StatusOK, prediccion)
+	}
+}
+
+// ObtenerProyeccion retorna la probabilidad de cada equipo de terminar en cada posición de la
+// tabla y de clasificar. Acepta los parámetros "simulaciones", "clasifican" (los primeros
+// puestos que clasifican) y "semilla", con la que la proyección se puede reproducir.
+func ObtenerProyeccion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opciones := services.OpcionesProyeccion{Semilla: 1}
+		var campos []ErrorCampo
+		if valor := c.Query("simulaciones"); valor != "" {
+			simulaciones, err := strconv.Atoi(valor)
+			switch {
+			case err != nil:
+				campos = append(campos, ErrorCampo{Campo: "simulaciones", Regla: "tipo"})
+			case simulaciones < 1:
+				campos = append(campos, ErrorCampo{Campo: "simulaciones", Regla: "min", parametro: "1"})
+			case simulaciones > services.SimulacionesMaximas:
+				campos = append(campos, ErrorCampo{Campo: "simulaciones", Regla: "max", parametro: strconv.Itoa(services.SimulacionesMaximas)})
+			default:
+				opciones.Simulaciones = simulaciones
+			}
+		}
+		if valor := c.Query("clasifican"); valor != "" {
+			clasificados, err := strconv.Atoi(valor)
+			switch {
+			case err != nil:
+				campos = append(campos, ErrorCampo{Campo: "clasifican", Regla: "tipo"})
+			case clasificados < 1:
+				campos = append(campos, ErrorCampo{Campo: "clasifican", Regla: "min", parametro: "1"})
+			default:
+				opciones.Clasificados = clasificados
+			}
+		}
+		if valor := c.Query("semilla"); valor != "" {
+			semilla, err := strconv.ParseInt(valor, 10, 64)
+			if err != nil {
+				campos = append(campos, ErrorCampo{Campo: "semilla", Regla: "tipo"})
+			}
+			opciones.Semilla = semilla
+		}
+		if len(campos) > 0 {
+			responderError(c, http.StatusBadRequest, ErrorDatosInvalidos, campos...)
+			return
+		}
+
+		service := services.NewPrediccionService(db)
+		proyeccion, err := service.ProyectarTemporada(opciones)
+		if err != nil {
+			responderError(c, http.StatusInternalServerError, ErrorProyeccion)
+			return
+		}
+
+		c.JSON(http.StatusOK, proyeccion)
+	}
+}
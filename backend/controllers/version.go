@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionIfMatch obtiene la versión esperada del header If-Match.
+// Devuelve nil si el header no se envió o vale "*".
+func versionIfMatch(c *gin.Context) (*uint, error) {
+	valor := strings.TrimSpace(c.GetHeader("If-Match"))
+	if valor == "" || valor == "*" {
+		return nil, nil
+	}
+
+	valor = strings.Trim(strings.TrimPrefix(valor, "W/"), `"`)
+	version, err := strconv.ParseUint(valor, 10, 64)
+	if err != nil {
+		return nil, errors.New("header If-Match inválido")
+	}
+
+	resultado := uint(version)
+	return &resultado, nil
+}
+
+// escribirETag publica la versión de un registro en el header ETag
+func escribirETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
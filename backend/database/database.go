@@ -3,73 +3,68 @@ package database
 import (
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
-	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/config"
 )
 
-var DB *gorm.DB
-
-// InitDB inicializa la conexión a la base de datos
-func InitDB() {
-	// Cargar variables de entorno
-	err := godotenv.Load()
+// Conectar abre la conexión a la base de datos y configura el pool. El esquema se crea
+// con las migraciones versionadas (ver Migrador), no con AutoMigrate.
+// La conexión devuelta es la única de la aplicación y se pasa a controladores y servicios.
+func Conectar(cfg config.BaseDatos) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector(cfg), &gorm.Config{})
 	if err != nil {
-		log.Println("No se pudo cargar el archivo .env, usando valores por defecto")
+		return nil, fmt.Errorf("error al conectar a la base de datos: %w", err)
 	}
 
-	// Obtener variables de entorno para la conexión a la base de datos
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "tornea")
-	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
-
-	// Construir la cadena de conexión
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode)
-
-	// Conectar a la base de datos
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Error al conectar a la base de datos: %v", err)
+		return nil, fmt.Errorf("error al obtener el pool de conexiones: %w", err)
 	}
-
-	log.Println("Conexión a la base de datos establecida")
-
-	// Migrar los modelos a la base de datos
-	err = db.AutoMigrate(
-		&models.Equipo{},
-		&models.Jugador{},
-		&models.Jornada{},
-		&models.Partido{},
-		&models.Incidencia{},
-	)
-	if err != nil {
-		log.Fatalf("Error al migrar los modelos: %v", err)
+	sqlDB.SetMaxOpenConns(cfg.MaxConexionesAbiertas)
+	sqlDB.SetMaxIdleConns(cfg.MaxConexionesInactivas)
+	sqlDB.SetConnMaxLifetime(cfg.VidaMaximaConexion)
+	if cfg.Driver == config.DriverSQLite {
+		// SQLite admite un solo escritor, y cada conexión a :memory: abre una base distinta,
+		// así que se usa una única conexión que nunca se cierra
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
 	}
 
-	log.Println("Migración de modelos completada")
+	log.Printf("Conexión a la base de datos %s establecida", cfg.Driver)
 
-	// Asignar la conexión a la variable global
-	DB = db
+	return db, nil
 }
 
-// getEnv obtiene una variable de entorno o devuelve un valor por defecto
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// dialector elige el driver de GORM según la configuración
+func dialector(cfg config.BaseDatos) gorm.Dialector {
+	if cfg.Driver == config.DriverSQLite {
+		// Las claves foráneas de SQLite están desactivadas por defecto
+		return sqlite.Open(fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", cfg.RutaSQLite))
 	}
-	return value
+	return postgres.Open(cfg.DSN())
+}
+
+// tablasDatos son las tablas con los datos del torneo, en un orden en el que cada tabla se
+// vacía antes que las tablas a las que hace referencia
+var tablasDatos = []string{
+	"designaciones_arbitros", "reprogramaciones", "incidencias", "partidos", "jornadas",
+	"jugadores", "equipos", "arbitros", "estadios",
 }
 
-// GetDB devuelve la instancia de la base de datos
-func GetDB() *gorm.DB {
-	return DB
+// VaciarDatos borra en una transacción todos los datos del torneo. Conserva los usuarios y el
+// registro de migraciones, así que la aplicación sigue funcionando con el esquema actual.
+func VaciarDatos(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, tabla := range tablasDatos {
+			if err := tx.Exec("DELETE FROM " + tabla).Error; err != nil {
+				return fmt.Errorf("error al vaciar la tabla %s: %w", tabla, err)
+			}
+		}
+		return nil
+	})
 }
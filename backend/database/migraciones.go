@@ -0,0 +1,333 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/noisk8/torneas/backend/migraciones"
+)
+
+// Estados de una migración en el comando status
+const (
+	MigracionAplicada    = "aplicada"
+	MigracionPendiente   = "pendiente"
+	MigracionModificada  = "modificada"  // El archivo cambió después de aplicarse
+	MigracionDesconocida = "desconocida" // Está aplicada en la base pero no hay archivo
+)
+
+// ErrChecksumMigracion indica que una migración ya aplicada fue modificada
+var ErrChecksumMigracion = errors.New("el checksum de una migración aplicada no coincide con su archivo")
+
+var (
+	patronMigracion       = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	patronNombreMigracion = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migracion es una versión del esquema con sus scripts para aplicarla y revertirla
+type Migracion struct {
+	Version  uint
+	Nombre   string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// RegistroMigracion es una fila de la tabla de migraciones aplicadas
+type RegistroMigracion struct {
+	Version    uint      `gorm:"primaryKey;autoIncrement:false"`
+	Nombre     string    `gorm:"size:255;not null"`
+	Checksum   string    `gorm:"size:64;not null"`
+	AplicadaEn time.Time `gorm:"not null"`
+}
+
+// TableName fija el nombre de la tabla de migraciones
+func (RegistroMigracion) TableName() string {
+	return "schema_migraciones"
+}
+
+// EstadoMigracion describe una migración para el comando status
+type EstadoMigracion struct {
+	Version    uint
+	Nombre     string
+	Estado     string
+	AplicadaEn *time.Time
+}
+
+// Migrador aplica y revierte las migraciones de Archivos sobre la base de datos
+type Migrador struct {
+	DB       *gorm.DB
+	Archivos fs.FS
+}
+
+// NewMigrador crea un migrador con las migraciones incluidas en el binario para el motor de db
+func NewMigrador(db *gorm.DB) (*Migrador, error) {
+	archivos, err := fs.Sub(migraciones.Archivos, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrador{DB: db, Archivos: archivos}, nil
+}
+
+// Cargar lee y valida los archivos de migraciones, ordenados por versión
+func (m *Migrador) Cargar() ([]Migracion, error) {
+	entradas, err := fs.ReadDir(m.Archivos, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer las migraciones: %w", err)
+	}
+
+	porVersion := make(map[uint]*Migracion)
+	for _, entrada := range entradas {
+		if entrada.IsDir() {
+			continue
+		}
+		partes := patronMigracion.FindStringSubmatch(entrada.Name())
+		if partes == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entrada.Name())
+		}
+
+		version, _ := strconv.ParseUint(partes[1], 10, 64)
+		contenido, err := fs.ReadFile(m.Archivos, entrada.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migracion, ok := porVersion[uint(version)]
+		if !ok {
+			migracion = &Migracion{Version: uint(version), Nombre: partes[2]}
+			porVersion[uint(version)] = migracion
+		}
+		if migracion.Nombre != partes[2] {
+			return nil, fmt.Errorf("la versión %d tiene dos nombres: %s y %s", version, migracion.Nombre, partes[2])
+		}
+		if partes[3] == "up" {
+			migracion.Up = string(contenido)
+		} else {
+			migracion.Down = string(contenido)
+		}
+	}
+
+	migraciones := make([]Migracion, 0, len(porVersion))
+	for _, migracion := range porVersion {
+		if migracion.Up == "" || migracion.Down == "" {
+			return nil, fmt.Errorf("la migración %04d_%s debe tener archivos up y down", migracion.Version, migracion.Nombre)
+		}
+		suma := sha256.Sum256([]byte(migracion.Up + "\x00" + migracion.Down))
+		migracion.Checksum = hex.EncodeToString(suma[:])
+		migraciones = append(migraciones, *migracion)
+	}
+	sort.Slice(migraciones, func(i, j int) bool { return migraciones[i].Version < migraciones[j].Version })
+
+	return migraciones, nil
+}
+
+// aplicadas crea la tabla de migraciones si hace falta y devuelve las ya aplicadas por versión
+func (m *Migrador) aplicadas() (map[uint]RegistroMigracion, error) {
+	if err := m.DB.AutoMigrate(&RegistroMigracion{}); err != nil {
+		return nil, fmt.Errorf("error al crear la tabla de migraciones: %w", err)
+	}
+
+	var registros []RegistroMigracion
+	if err := m.DB.Order("version").Find(&registros).Error; err != nil {
+		return nil, err
+	}
+
+	aplicadas := make(map[uint]RegistroMigracion, len(registros))
+	for _, registro := range registros {
+		aplicadas[registro.Version] = registro
+	}
+	return aplicadas, nil
+}
+
+// Subir aplica en orden todas las migraciones pendientes, cada una en su propia transacción.
+// Si alguna migración aplicada fue modificada no se aplica nada.
+func (m *Migrador) Subir() ([]Migracion, error) {
+	migraciones, err := m.Cargar()
+	if err != nil {
+		return nil, err
+	}
+	aplicadas, err := m.aplicadas()
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarChecksums(migraciones, aplicadas); err != nil {
+		return nil, err
+	}
+
+	var nuevas []Migracion
+	for _, migracion := range migraciones {
+		if _, ok := aplicadas[migracion.Version]; ok {
+			continue
+		}
+
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migracion.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&RegistroMigracion{
+				Version:    migracion.Version,
+				Nombre:     migracion.Nombre,
+				Checksum:   migracion.Checksum,
+				AplicadaEn: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return nuevas, fmt.Errorf("error al aplicar la migración %04d_%s: %w", migracion.Version, migracion.Nombre, err)
+		}
+		nuevas = append(nuevas, migracion)
+	}
+
+	return nuevas, nil
+}
+
+// Bajar revierte las últimas pasos migraciones aplicadas, de la más reciente a la más antigua
+func (m *Migrador) Bajar(pasos int) ([]Migracion, error) {
+	migraciones, err := m.Cargar()
+	if err != nil {
+		return nil, err
+	}
+	aplicadas, err := m.aplicadas()
+	if err != nil {
+		return nil, err
+	}
+	if err := verificarChecksums(migraciones, aplicadas); err != nil {
+		return nil, err
+	}
+
+	var revertidas []Migracion
+	for i := len(migraciones) - 1; i >= 0 && len(revertidas) < pasos; i-- {
+		migracion := migraciones[i]
+		if _, ok := aplicadas[migracion.Version]; !ok {
+			continue
+		}
+
+		err := m.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migracion.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&RegistroMigracion{}, migracion.Version).Error
+		})
+		if err != nil {
+			return revertidas, fmt.Errorf("error al revertir la migración %04d_%s: %w", migracion.Version, migracion.Nombre, err)
+		}
+		revertidas = append(revertidas, migracion)
+	}
+
+	return revertidas, nil
+}
+
+// Estado devuelve todas las migraciones conocidas, ya sea por su archivo o por la tabla de migraciones
+func (m *Migrador) Estado() ([]EstadoMigracion, error) {
+	migraciones, err := m.Cargar()
+	if err != nil {
+		return nil, err
+	}
+	aplicadas, err := m.aplicadas()
+	if err != nil {
+		return nil, err
+	}
+
+	var estados []EstadoMigracion
+	for _, migracion := range migraciones {
+		estado := EstadoMigracion{Version: migracion.Version, Nombre: migracion.Nombre, Estado: MigracionPendiente}
+		if registro, ok := aplicadas[migracion.Version]; ok {
+			estado.Estado = MigracionAplicada
+			if registro.Checksum != migracion.Checksum {
+				estado.Estado = MigracionModificada
+			}
+			estado.AplicadaEn = &registro.AplicadaEn
+			delete(aplicadas, migracion.Version)
+		}
+		estados = append(estados, estado)
+	}
+	for _, registro := range aplicadas {
+		estados = append(estados, EstadoMigracion{
+			Version:    registro.Version,
+			Nombre:     registro.Nombre,
+			Estado:     MigracionDesconocida,
+			AplicadaEn: &registro.AplicadaEn,
+		})
+	}
+	sort.Slice(estados, func(i, j int) bool { return estados[i].Version < estados[j].Version })
+
+	return estados, nil
+}
+
+// verificarChecksums comprueba que ninguna migración aplicada haya cambiado ni desaparecido
+func verificarChecksums(migraciones []Migracion, aplicadas map[uint]RegistroMigracion) error {
+	conocidas := make(map[uint]bool, len(migraciones))
+	for _, migracion := range migraciones {
+		conocidas[migracion.Version] = true
+		if registro, ok := aplicadas[migracion.Version]; ok && registro.Checksum != migracion.Checksum {
+			return fmt.Errorf("%w: %04d_%s", ErrChecksumMigracion, migracion.Version, migracion.Nombre)
+		}
+	}
+	for version, registro := range aplicadas {
+		if !conocidas[version] {
+			return fmt.Errorf("la migración aplicada %04d_%s no tiene archivo", version, registro.Nombre)
+		}
+	}
+	return nil
+}
+
+// CrearMigracion crea los archivos vacíos up y down de una nueva migración en la carpeta de
+// cada driver dentro de dir, con la versión siguiente a la más alta existente
+func CrearMigracion(dir, nombre string) ([]string, error) {
+	if !patronNombreMigracion.MatchString(nombre) {
+		return nil, fmt.Errorf("el nombre de la migración solo puede tener minúsculas, números y guiones bajos: %q", nombre)
+	}
+
+	drivers, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer el directorio de migraciones: %w", err)
+	}
+
+	var carpetas []string
+	var ultima uint64
+	for _, driver := range drivers {
+		if !driver.IsDir() {
+			continue
+		}
+		carpeta := filepath.Join(dir, driver.Name())
+		carpetas = append(carpetas, carpeta)
+
+		entradas, err := os.ReadDir(carpeta)
+		if err != nil {
+			return nil, err
+		}
+		for _, entrada := range entradas {
+			if partes := patronMigracion.FindStringSubmatch(entrada.Name()); partes != nil {
+				if version, _ := strconv.ParseUint(partes[1], 10, 64); version > ultima {
+					ultima = version
+				}
+			}
+		}
+	}
+	if len(carpetas) == 0 {
+		return nil, fmt.Errorf("no hay carpetas de drivers en %s", dir)
+	}
+
+	base := fmt.Sprintf("%04d_%s", ultima+1, nombre)
+	var creados []string
+	for _, carpeta := range carpetas {
+		for _, sentido := range []string{"up", "down"} {
+			archivo := filepath.Join(carpeta, base+"."+sentido+".sql")
+			contenido := fmt.Sprintf("-- %s (%s)\n", base, sentido)
+			if err := os.WriteFile(archivo, []byte(contenido), 0o644); err != nil {
+				return creados, err
+			}
+			creados = append(creados, archivo)
+		}
+	}
+	return creados, nil
+}
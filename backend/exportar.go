@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/database"
+	"github.com/noisk8/torneas/backend/services"
+)
+
+const usoExportar = `Uso: go run . exportar <estadios|equipos|jugadores|partidos|incidencias|todo> [opciones]
+
+Opciones:
+  --formato csv|ndjson  Formato de salida (por defecto csv)
+  --salida <ruta>       Archivo de salida (por defecto la salida estándar). Con todo es el
+                        directorio donde se escribe un archivo por tipo (por defecto el actual)`
+
+// ejecutarExportar atiende el subcomando exportar y devuelve el código de salida del proceso
+func ejecutarExportar(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usoExportar)
+		return 2
+	}
+	tipo := args[0]
+
+	opciones := flag.NewFlagSet("exportar", flag.ContinueOnError)
+	opciones.SetOutput(io.Discard)
+	formato := opciones.String("formato", services.FormatoCSV, "")
+	salida := opciones.String("salida", "", "")
+	if err := opciones.Parse(args[1:]); err != nil || opciones.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, usoExportar)
+		return 2
+	}
+
+	tipos := []string{tipo}
+	if tipo == "todo" {
+		tipos = services.TiposExportacion
+	}
+	for _, t := range tipos {
+		if err := services.ValidarExportacion(t, *formato); err != nil {
+			fmt.Fprintln(os.Stderr, usoExportar)
+			return 2
+		}
+	}
+
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	service := services.NewExportacionService(db)
+
+	if tipo != "todo" {
+		if *salida == "" {
+			err = service.Exportar(os.Stdout, tipo, *formato)
+		} else {
+			err = exportarArchivo(service, *salida, tipo, *formato)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al exportar %s: %v\n", tipo, err)
+			return 1
+		}
+		return 0
+	}
+
+	directorio := *salida
+	if directorio == "" {
+		directorio = "."
+	}
+	if err := os.MkdirAll(directorio, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error al crear el directorio: %v\n", err)
+		return 1
+	}
+	for _, t := range tipos {
+		ruta := filepath.Join(directorio, t+services.ExtensionFormato(*formato))
+		if err := exportarArchivo(service, ruta, t, *formato); err != nil {
+			fmt.Fprintf(os.Stderr, "Error al exportar %s: %v\n", t, err)
+			return 1
+		}
+		fmt.Printf("Exportado %s\n", ruta)
+	}
+	return 0
+}
+
+// exportarArchivo escribe la exportación de un tipo de datos en un archivo
+func exportarArchivo(service *services.ExportacionService, ruta, tipo, formato string) error {
+	archivo, err := os.Create(ruta)
+	if err != nil {
+		return err
+	}
+	errExportar := service.Exportar(archivo, tipo, formato)
+	return errors.Join(errExportar, archivo.Close())
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/controllers"
+	"github.com/noisk8/torneas/backend/database"
+	"github.com/noisk8/torneas/backend/services"
+)
+
+const usoImportar = `Uso: go run . importar <estadios|equipos|jugadores|partidos|incidencias> <archivo> [--confirmar]
+
+Valida un archivo CSV o XLSX cuya primera fila es el encabezado y muestra lo que se
+importaría. Con --confirmar guarda todas las filas en una sola transacción, o ninguna
+si alguna tiene errores. Los CSV exportados con el comando exportar se pueden importar en
+el orden estadios, equipos, jugadores, partidos e incidencias.`
+
+// ejecutarImportar atiende el subcomando importar y devuelve el código de salida del proceso
+func ejecutarImportar(cfg *config.Config, args []string) int {
+	confirmar := false
+	var posicionales []string
+	for _, arg := range args {
+		if arg == "--confirmar" {
+			confirmar = true
+		} else {
+			posicionales = append(posicionales, arg)
+		}
+	}
+	if len(posicionales) != 2 {
+		fmt.Fprintln(os.Stderr, usoImportar)
+		return 2
+	}
+	tipo, ruta := posicionales[0], posicionales[1]
+
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al abrir el archivo: %v\n", err)
+		return 1
+	}
+	defer archivo.Close()
+	filas, err := services.LeerTabla(ruta, archivo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al leer el archivo: %v\n", err)
+		return 1
+	}
+
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	service := services.NewImportacionService(db)
+	resultado, err := service.Importar(tipo, filas, !confirmar)
+	if errors.Is(err, services.ErrTipoImportacion) {
+		fmt.Fprintln(os.Stderr, usoImportar)
+		return 2
+	}
+	if errors.Is(err, services.ErrImportacionInvalida) {
+		informe := controllers.NewInformeImportacion("es", resultado)
+		for _, e := range informe.Errores {
+			if e.Campo == "" {
+				fmt.Fprintf(os.Stderr, "Línea %d: %s\n", e.Linea, e.Mensaje)
+			} else if e.Valor == "" {
+				fmt.Fprintf(os.Stderr, "Línea %d, %s: %s\n", e.Linea, e.Campo, e.Mensaje)
+			} else {
+				fmt.Fprintf(os.Stderr, "Línea %d, %s %q: %s\n", e.Linea, e.Campo, e.Valor, e.Mensaje)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d errores, no se importó ningún dato\n", len(informe.Errores))
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al importar los datos: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("%d filas de %s: %d nuevos, %d actualizados, %d omitidos\n", resultado.Filas, tipo, resultado.Creados, resultado.Actualizados, resultado.Omitidos)
+	if resultado.Simulacion {
+		fmt.Println("Simulación, no se guardó ningún cambio. Use --confirmar para importar los datos.")
+	}
+	return 0
+}
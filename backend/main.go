@@ -4,74 +4,86 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/noisk8/torneas/backend/config"
 	"github.com/noisk8/torneas/backend/controllers"
-	"github.com/noisk8/torneas/backend/models"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
+	"github.com/noisk8/torneas/backend/database"
 )
 
 func main() {
-	// Cargar variables de entorno
-	err := godotenv.Load()
+	// Cargar la configuración desde el entorno y el archivo .env
+	cfg, err := config.Cargar()
 	if err != nil {
-		log.Println("No se pudo cargar el archivo .env, usando valores por defecto")
+		log.Fatalf("Error en la configuración: %v", err)
 	}
 
-	// Configurar la conexión a la base de datos
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost"
+	// Subcomando para administrar las migraciones del esquema
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(ejecutarMigrate(cfg, os.Args[2:]))
 	}
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "5432"
+
+	// Subcomando para importar equipos, jugadores o partidos desde un CSV o XLSX
+	if len(os.Args) > 1 && os.Args[1] == "importar" {
+		os.Exit(ejecutarImportar(cfg, os.Args[2:]))
 	}
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "postgres"
+
+	// Subcomando para exportar los datos del torneo en CSV o NDJSON
+	if len(os.Args) > 1 && os.Args[1] == "exportar" {
+		os.Exit(ejecutarExportar(cfg, os.Args[2:]))
 	}
-	dbPassword := os.Getenv("DB_PASSWORD")
-	if dbPassword == "" {
-		dbPassword = "postgres"
+
+	// Subcomando para sembrar datos de ejemplo
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		os.Exit(ejecutarSeed(cfg, os.Args[2:]))
 	}
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "torneas"
+
+	// Subcomando para cambiar el rol de un usuario
+	if len(os.Args) > 1 && os.Args[1] == "rol" {
+		os.Exit(ejecutarRol(cfg, os.Args[2:]))
 	}
-	dbSSLMode := os.Getenv("DB_SSL_MODE")
-	if dbSSLMode == "" {
-		dbSSLMode = "disable"
+
+	// Subcomando para simular los resultados de la temporada
+	if len(os.Args) > 1 && os.Args[1] == "simular" {
+		os.Exit(ejecutarSimular(cfg, os.Args[2:]))
 	}
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		dbHost, dbUser, dbPassword, dbName, dbPort, dbSSLMode)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err := cfg.Validar(); err != nil {
+		log.Fatalf("Error en la configuración: %v", err)
+	}
+
+	// Conectar a la base de datos; esta conexión se comparte con controladores y servicios
+	db, err := database.Conectar(cfg.BaseDatos)
 	if err != nil {
-		log.Fatalf("Error al conectar con la base de datos: %v", err)
+		log.Fatalf("%v", err)
 	}
 
-	// Migrar los modelos
-	db.AutoMigrate(&models.Usuario{})
+	// Aplicar las migraciones pendientes; si se desactiva, se aplican con el comando migrate up
+	if cfg.BaseDatos.MigrarAlIniciar {
+		migrador, err := database.NewMigrador(db)
+		if err != nil {
+			log.Fatalf("Error al cargar las migraciones: %v", err)
+		}
+		aplicadas, err := migrador.Subir()
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		log.Printf("Migraciones aplicadas: %d\n", len(aplicadas))
+	}
 
 	// Configurar el router con Gin
 	router := gin.Default()
 
 	// Configurar CORS
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:3000"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
-	router.Use(cors.New(config))
-
-	// Obtener puerto de las variables de entorno o usar el valor por defecto
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = cfg.OrigenesPermitidos
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "If-Match", "Accept-Language", "X-Request-ID"}
+	corsConfig.ExposeHeaders = []string{"ETag", "X-Request-ID"}
+	router.Use(cors.New(corsConfig))
+
+	// Identificar cada petición para poder relacionar los errores con los logs
+	router.Use(controllers.IdentificarPeticion())
 
 	// Rutas de la API
 	api := router.Group("/api")
@@ -80,8 +92,9 @@ func main() {
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", controllers.Register(db))
-			auth.POST("/login", controllers.Login(db))
-			auth.GET("/verify", controllers.VerifyToken())
+			auth.POST("/login", controllers.Login(db, cfg.JWT))
+			auth.GET("/verify", controllers.VerifyToken(cfg.JWT))
+			auth.PUT("/usuarios/:id/rol", controllers.RequerirAutenticacion(cfg.JWT, "admin"), controllers.AsignarRol(db))
 		}
 
 		// Rutas para equipos
@@ -89,25 +102,106 @@ func main() {
 		{
 			equipos.GET("", controllers.ObtenerEquipos(db))
 			equipos.GET("/:id", controllers.ObtenerEquipo(db))
-			equipos.POST("", controllers.CrearEquipo(db))
-			equipos.PUT("/:id", controllers.ActualizarEquipo(db))
-			equipos.DELETE("/:id", controllers.EliminarEquipo(db))
+			equipos.GET("/:id/estadisticas", controllers.ObtenerEstadisticasEquipo(db))
+			equipos.GET("/:id/vs/:rivalId", controllers.ObtenerEnfrentamientos(db))
+			equipos.GET("/:id/calendario.ics", controllers.CalendarioEquipoICS(db))
+			equipos.POST("", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.CrearEquipo(db))
+			equipos.PUT("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ActualizarEquipo(db))
+			equipos.PATCH("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarEquipo(db))
+			equipos.DELETE("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.EliminarEquipo(db))
+		}
+
+		// Rutas para estadios
+		estadios := api.Group("/estadios")
+		{
+			estadios.GET("", controllers.ObtenerEstadios(db))
+			estadios.GET("/:id", controllers.ObtenerEstadio(db))
+			estadios.GET("/:id/agenda", controllers.ObtenerAgendaEstadio(db))
+			estadios.POST("", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.CrearEstadio(db))
+			estadios.PATCH("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarEstadio(db))
+			estadios.DELETE("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.EliminarEstadio(db))
+		}
+
+		// Rutas para árbitros
+		arbitros := api.Group("/arbitros")
+		{
+			arbitros.GET("", controllers.ObtenerArbitros(db))
+			arbitros.GET("/:id", controllers.ObtenerArbitro(db))
+			arbitros.GET("/:id/estadisticas", controllers.ObtenerEstadisticasArbitro(db))
+			arbitros.POST("", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.CrearArbitro(db))
+			arbitros.PATCH("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarArbitro(db))
+			arbitros.DELETE("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.EliminarArbitro(db))
 		}
 
+		// Rutas para jugadores
+		jugadores := api.Group("/jugadores")
+		{
+			jugadores.PATCH("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarJugador(db))
+			jugadores.GET("/:id/partidos", controllers.ObtenerPartidosJugador(db))
+		}
+
+		// Rutas para jornadas
+		jornadas := api.Group("/jornadas")
+		{
+			jornadas.PATCH("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarJornada(db))
+		}
+
+		// Rutas para partidos
+		partidos := api.Group("/partidos")
+		{
+			partidos.GET("/:id", controllers.ObtenerPartido(db))
+			partidos.PATCH("/:id", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarPartido(db))
+			partidos.PATCH("/:id/metadatos", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ModificarMetadatosPartido(db))
+			partidos.PUT("/:id/estado", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.CambiarEstadoPartido(db))
+			partidos.PUT("/:id/marcador", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ActualizarMarcador(db))
+			partidos.POST("/:id/incidencias", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.RegistrarIncidencia(db))
+			partidos.GET("/:id/reloj", controllers.ObtenerReloj(db))
+			partidos.GET("/:id/prediccion", controllers.ObtenerPrediccionPartido(db))
+			partidos.POST("/:id/periodo/inicio", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.IniciarPeriodo(db))
+			partidos.POST("/:id/periodo/fin", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.FinalizarPeriodo(db))
+			partidos.PUT("/:id/tiempo-anadido", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.FijarTiempoAnadido(db))
+			partidos.GET("/:id/live", controllers.TransmitirPartido(db))
+			partidos.POST("/:id/aplazar", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.AplazarPartido(db))
+			partidos.POST("/:id/reprogramar", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.ReprogramarPartido(db, cfg.Liga))
+			partidos.GET("/:id/reprogramaciones", controllers.ObtenerReprogramaciones(db))
+			partidos.GET("/:id/arbitros", controllers.ObtenerArbitrosPartido(db))
+			partidos.PUT("/:id/arbitros", controllers.RequerirAutenticacion(cfg.JWT, "admin", "editor"), controllers.DesignarArbitros(db, cfg.Arbitros))
+			partidos.GET("/:id/operadores", controllers.RequerirAutenticacionWebSocket(cfg.JWT, "admin", "editor"), controllers.CanalOperadores(db))
+		}
+
+		// Transmisión en vivo de la jornada actual
+		api.GET("/live", controllers.TransmitirJornadaActual(db))
+
+		// Asistencia de público por equipo y por estadio
+		api.GET("/asistencia/equipos", controllers.ObtenerAsistenciaEquipos(db))
+		api.GET("/asistencia/estadios", controllers.ObtenerAsistenciaEstadios(db))
+
 		// Rutas para la tabla de posiciones
-		api.GET("/posiciones", getPosiciones)
+		api.GET("/posiciones", controllers.ObtenerPosiciones(db))
+		api.GET("/posiciones/proyeccion", controllers.ObtenerProyeccion(db))
+
+		// Predicciones de los partidos que faltan por jugar
+		api.GET("/predicciones", controllers.ObtenerPredicciones(db))
 
 		// Rutas para goleadores
 		api.GET("/goleadores", getGoleadores)
 
 		// Rutas para el calendario
 		api.GET("/calendario", getCalendario)
+		api.GET("/calendario.ics", controllers.CalendarioICS(db))
 		api.GET("/calendario/jornada/:numero", getCalendarioByJornada)
+		api.POST("/calendario/generar", controllers.RequerirAutenticacion(cfg.JWT, "admin"), controllers.GenerarCalendario(db))
+
+		// Importación de equipos, jugadores o partidos desde un CSV o XLSX
+		api.POST("/importar/:tipo", controllers.RequerirAutenticacion(cfg.JWT, "admin"), controllers.ImportarDatos(db))
+
+		// Exportación de todos los datos en CSV o NDJSON
+		api.GET("/export/:tipo", controllers.RequerirAutenticacion(cfg.JWT, "admin"), controllers.ExportarDatos(db))
 	}
 
 	// Iniciar el servidor
-	log.Printf("Servidor iniciado en el puerto %s\n", port)
-	if err := router.Run(":" + port); err != nil {
+	log.Printf("Servidor iniciado en el puerto %s\n", cfg.Puerto)
+	if err := router.Run(":" + cfg.Puerto); err != nil {
 		log.Fatalf("Error al iniciar el servidor: %v", err)
 	}
 }
@@ -115,12 +209,6 @@ func main() {
 // Handlers temporales para las rutas
 // Estos serán reemplazados por implementaciones reales que usen la base de datos
 
-func getPosiciones(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Tabla de posiciones - Implementación pendiente",
-	})
-}
-
 func getGoleadores(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Tabla de goleadores - Implementación pendiente",
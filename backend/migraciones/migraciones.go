@@ -0,0 +1,11 @@
+// Package migraciones contiene los archivos SQL versionados del esquema de la base de datos.
+// Cada versión tiene un archivo up y uno down con el formato NNNN_nombre.up.sql y NNNN_nombre.down.sql.
+package migraciones
+
+import "embed"
+
+// Archivos incluye en el binario las migraciones de cada motor de base de datos, una carpeta
+// por driver. Cada versión debe existir en todas las carpetas.
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var Archivos embed.FS
@@ -0,0 +1,91 @@
+-- Esquema inicial: corresponde a las tablas que antes creaba AutoMigrate.
+-- Se usa IF NOT EXISTS para que las bases creadas con AutoMigrate puedan adoptar las migraciones.
+
+CREATE TABLE IF NOT EXISTS usuarios (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    email TEXT NOT NULL,
+    password TEXT NOT NULL,
+    nombre TEXT NOT NULL,
+    rol TEXT NOT NULL DEFAULT 'admin',
+    activo BOOLEAN NOT NULL DEFAULT true,
+    CONSTRAINT uni_usuarios_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_usuarios_deleted_at ON usuarios (deleted_at);
+
+CREATE TABLE IF NOT EXISTS equipos (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    nombre TEXT,
+    nombre_corto TEXT,
+    ciudad TEXT,
+    estadio TEXT,
+    fundacion TEXT,
+    escudo TEXT,
+    version BIGINT NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_equipos_deleted_at ON equipos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS jugadores (
+    id BIGSERIAL PRIMARY KEY,
+    nombre VARCHAR(100) NOT NULL,
+    apellido VARCHAR(100) NOT NULL,
+    fecha_nacimiento TIMESTAMPTZ,
+    nacionalidad VARCHAR(50),
+    posicion VARCHAR(50),
+    numero BIGINT,
+    altura NUMERIC,
+    peso NUMERIC,
+    foto VARCHAR(255),
+    equipo_id BIGINT NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    CONSTRAINT fk_equipos_jugadores FOREIGN KEY (equipo_id) REFERENCES equipos (id)
+);
+
+CREATE TABLE IF NOT EXISTS jornadas (
+    id BIGSERIAL PRIMARY KEY,
+    numero BIGINT NOT NULL,
+    fecha TIMESTAMPTZ,
+    completada BOOLEAN DEFAULT false,
+    version BIGINT NOT NULL DEFAULT 1,
+    CONSTRAINT uni_jornadas_numero UNIQUE (numero)
+);
+
+CREATE TABLE IF NOT EXISTS partidos (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    jornada_id BIGINT,
+    equipo_local_id BIGINT,
+    equipo_visitante_id BIGINT,
+    goles_local BIGINT,
+    goles_visitante BIGINT,
+    fecha_hora TIMESTAMPTZ,
+    estado TEXT,
+    periodo VARCHAR(20),
+    inicio_partido TIMESTAMPTZ,
+    inicio_periodo TIMESTAMPTZ,
+    tiempo_anadido BIGINT,
+    secuencia BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT fk_jornadas_partidos FOREIGN KEY (jornada_id) REFERENCES jornadas (id)
+);
+CREATE INDEX IF NOT EXISTS idx_partidos_deleted_at ON partidos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS incidencias (
+    id BIGSERIAL PRIMARY KEY,
+    partido_id BIGINT NOT NULL,
+    jugador_id BIGINT NOT NULL,
+    tipo VARCHAR(20) NOT NULL,
+    minuto BIGINT,
+    minuto_anadido BIGINT,
+    descripcion VARCHAR(255),
+    timestamp TIMESTAMPTZ,
+    secuencia BIGINT,
+    CONSTRAINT fk_partidos_incidencias FOREIGN KEY (partido_id) REFERENCES partidos (id),
+    CONSTRAINT fk_incidencias_jugador FOREIGN KEY (jugador_id) REFERENCES jugadores (id)
+);
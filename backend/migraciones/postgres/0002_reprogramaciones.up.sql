@@ -0,0 +1,17 @@
+-- Historial de aplazamientos y cambios de fecha de los partidos.
+
+CREATE TABLE reprogramaciones (
+    id BIGSERIAL PRIMARY KEY,
+    partido_id BIGINT NOT NULL,
+    estado_anterior VARCHAR(20) NOT NULL,
+    estado_nuevo VARCHAR(20) NOT NULL,
+    fecha_anterior TIMESTAMPTZ,
+    fecha_nueva TIMESTAMPTZ,
+    jornada_anterior_id BIGINT,
+    jornada_nueva_id BIGINT,
+    motivo VARCHAR(255),
+    usuario VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_partidos_reprogramaciones FOREIGN KEY (partido_id) REFERENCES partidos (id)
+);
+CREATE INDEX idx_reprogramaciones_partido_id ON reprogramaciones (partido_id);
@@ -0,0 +1,3 @@
+ALTER TABLE partidos DROP COLUMN IF EXISTS estadio_id;
+ALTER TABLE equipos DROP COLUMN IF EXISTS estadio_id;
+DROP TABLE IF EXISTS estadios;
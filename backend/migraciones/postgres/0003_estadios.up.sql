@@ -0,0 +1,35 @@
+-- Estadios como entidad propia. Se crea un estadio por cada combinación de nombre de estadio
+-- y ciudad de los equipos existentes, y se asigna a cada equipo como su estadio local.
+
+CREATE TABLE estadios (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    nombre TEXT NOT NULL,
+    ciudad TEXT,
+    capacidad BIGINT NOT NULL DEFAULT 0,
+    latitud NUMERIC,
+    longitud NUMERIC,
+    superficie VARCHAR(20),
+    version BIGINT NOT NULL DEFAULT 1
+);
+CREATE INDEX idx_estadios_deleted_at ON estadios (deleted_at);
+
+ALTER TABLE equipos ADD COLUMN estadio_id BIGINT CONSTRAINT fk_equipos_estadio REFERENCES estadios (id);
+ALTER TABLE partidos ADD COLUMN estadio_id BIGINT CONSTRAINT fk_partidos_estadio REFERENCES estadios (id);
+
+INSERT INTO estadios (created_at, updated_at, nombre, ciudad)
+SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, nombre, ciudad
+FROM (
+    SELECT DISTINCT estadio AS nombre, COALESCE(ciudad, '') AS ciudad
+    FROM equipos
+    WHERE estadio IS NOT NULL AND estadio <> '' AND deleted_at IS NULL
+) AS existentes
+ORDER BY nombre, ciudad;
+
+UPDATE equipos SET estadio_id = (
+    SELECT estadios.id FROM estadios
+    WHERE estadios.nombre = equipos.estadio AND estadios.ciudad = COALESCE(equipos.ciudad, '')
+)
+WHERE estadio IS NOT NULL AND estadio <> '' AND deleted_at IS NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE partidos DROP COLUMN IF EXISTS resumen_url;
+ALTER TABLE partidos DROP COLUMN IF EXISTS cronica;
+ALTER TABLE partidos DROP COLUMN IF EXISTS clima;
+ALTER TABLE partidos DROP COLUMN IF EXISTS transmision;
+ALTER TABLE partidos DROP COLUMN IF EXISTS espectadores;
@@ -0,0 +1,7 @@
+-- Datos opcionales de cada partido: asistencia, transmisión, clima, crónica y resumen en video.
+
+ALTER TABLE partidos ADD COLUMN espectadores INTEGER;
+ALTER TABLE partidos ADD COLUMN transmision VARCHAR(100);
+ALTER TABLE partidos ADD COLUMN clima VARCHAR(100);
+ALTER TABLE partidos ADD COLUMN cronica TEXT;
+ALTER TABLE partidos ADD COLUMN resumen_url VARCHAR(255);
@@ -0,0 +1,2 @@
+ALTER TABLE incidencias DROP COLUMN IF EXISTS jugador_entra_id;
+ALTER TABLE incidencias DROP COLUMN IF EXISTS equipo_id;
@@ -0,0 +1,44 @@
+-- Adopción de las bases creadas con AutoMigrate antes de las migraciones versionadas.
+-- Esas bases pueden venir de una versión anterior a alguna columna, así que se agregan las que
+-- no existían en el esquema original. Además AutoMigrate llamaba jugadors e incidencia a las
+-- tablas de jugadores e incidencias, y 0001 creó junto a ellas las tablas vacías que usa la
+-- aplicación: sus datos se copian a las tablas nuevas, siempre que estén vacías, y las
+-- tablas antiguas se eliminan.
+
+ALTER TABLE equipos ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE jugadores ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE jornadas ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS periodo VARCHAR(20);
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS inicio_partido TIMESTAMPTZ;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS inicio_periodo TIMESTAMPTZ;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS tiempo_anadido BIGINT;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS secuencia BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE incidencias ADD COLUMN IF NOT EXISTS minuto_anadido BIGINT;
+ALTER TABLE incidencias ADD COLUMN IF NOT EXISTS secuencia BIGINT;
+
+DO $$
+BEGIN
+    IF to_regclass('jugadors') IS NOT NULL AND NOT EXISTS (SELECT 1 FROM jugadores) THEN
+        INSERT INTO jugadores (id, nombre, apellido, fecha_nacimiento, nacionalidad, posicion, numero, altura, peso, foto, equipo_id)
+        SELECT id, nombre, apellido, fecha_nacimiento, nacionalidad, posicion, numero, altura, peso, foto, equipo_id
+        FROM jugadors;
+        PERFORM setval(pg_get_serial_sequence('jugadores', 'id'), COALESCE((SELECT MAX(id) FROM jugadores), 0) + 1, false);
+    END IF;
+
+    IF to_regclass('incidencia') IS NOT NULL AND NOT EXISTS (SELECT 1 FROM incidencias) THEN
+        INSERT INTO incidencias (id, partido_id, jugador_id, equipo_id, tipo, minuto, descripcion, timestamp)
+        SELECT incidencia.id, incidencia.partido_id, incidencia.jugador_id, jugadores.equipo_id,
+            incidencia.tipo, incidencia.minuto, incidencia.descripcion, incidencia.timestamp
+        FROM incidencia
+        LEFT JOIN jugadores ON jugadores.id = incidencia.jugador_id;
+        PERFORM setval(pg_get_serial_sequence('incidencias', 'id'), COALESCE((SELECT MAX(id) FROM incidencias), 0) + 1, false);
+        DROP TABLE incidencia;
+    END IF;
+
+    -- La tabla antigua de incidencias tiene una clave foránea hacia jugadors
+    IF to_regclass('jugadors') IS NOT NULL AND to_regclass('incidencia') IS NULL
+        AND (SELECT COUNT(*) FROM jugadores) >= (SELECT COUNT(*) FROM jugadors) THEN
+        DROP TABLE jugadors;
+    END IF;
+END
+$$;
@@ -0,0 +1 @@
+ALTER TABLE usuarios ALTER COLUMN rol SET DEFAULT 'admin';
@@ -0,0 +1,4 @@
+-- Los usuarios que se crean sin indicar el rol reciben el rol lector, que no tiene permisos de
+-- escritura, y no el de administrador.
+
+ALTER TABLE usuarios ALTER COLUMN rol SET DEFAULT 'lector';
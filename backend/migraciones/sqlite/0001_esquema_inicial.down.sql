@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS incidencias;
+DROP TABLE IF EXISTS partidos;
+DROP TABLE IF EXISTS jornadas;
+DROP TABLE IF EXISTS jugadores;
+DROP TABLE IF EXISTS equipos;
+DROP TABLE IF EXISTS usuarios;
@@ -0,0 +1,90 @@
+-- Esquema inicial para SQLite, equivalente a postgres/0001_esquema_inicial.up.sql
+
+CREATE TABLE IF NOT EXISTS usuarios (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    email TEXT NOT NULL,
+    password TEXT NOT NULL,
+    nombre TEXT NOT NULL,
+    rol TEXT NOT NULL DEFAULT 'admin',
+    activo NUMERIC NOT NULL DEFAULT true,
+    CONSTRAINT uni_usuarios_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_usuarios_deleted_at ON usuarios (deleted_at);
+
+CREATE TABLE IF NOT EXISTS equipos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    nombre TEXT,
+    nombre_corto TEXT,
+    ciudad TEXT,
+    estadio TEXT,
+    fundacion TEXT,
+    escudo TEXT,
+    version INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_equipos_deleted_at ON equipos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS jugadores (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    nombre VARCHAR(100) NOT NULL,
+    apellido VARCHAR(100) NOT NULL,
+    fecha_nacimiento DATETIME,
+    nacionalidad VARCHAR(50),
+    posicion VARCHAR(50),
+    numero INTEGER,
+    altura REAL,
+    peso REAL,
+    foto VARCHAR(255),
+    equipo_id INTEGER NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT fk_equipos_jugadores FOREIGN KEY (equipo_id) REFERENCES equipos (id)
+);
+
+CREATE TABLE IF NOT EXISTS jornadas (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    numero INTEGER NOT NULL,
+    fecha DATETIME,
+    completada NUMERIC DEFAULT false,
+    version INTEGER NOT NULL DEFAULT 1,
+    CONSTRAINT uni_jornadas_numero UNIQUE (numero)
+);
+
+CREATE TABLE IF NOT EXISTS partidos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    jornada_id INTEGER,
+    equipo_local_id INTEGER,
+    equipo_visitante_id INTEGER,
+    goles_local INTEGER,
+    goles_visitante INTEGER,
+    fecha_hora DATETIME,
+    estado TEXT,
+    periodo VARCHAR(20),
+    inicio_partido DATETIME,
+    inicio_periodo DATETIME,
+    tiempo_anadido INTEGER,
+    secuencia INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT fk_jornadas_partidos FOREIGN KEY (jornada_id) REFERENCES jornadas (id)
+);
+CREATE INDEX IF NOT EXISTS idx_partidos_deleted_at ON partidos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS incidencias (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    partido_id INTEGER NOT NULL,
+    jugador_id INTEGER NOT NULL,
+    tipo VARCHAR(20) NOT NULL,
+    minuto INTEGER,
+    minuto_anadido INTEGER,
+    descripcion VARCHAR(255),
+    timestamp DATETIME,
+    secuencia INTEGER,
+    CONSTRAINT fk_partidos_incidencias FOREIGN KEY (partido_id) REFERENCES partidos (id),
+    CONSTRAINT fk_incidencias_jugador FOREIGN KEY (jugador_id) REFERENCES jugadores (id)
+);
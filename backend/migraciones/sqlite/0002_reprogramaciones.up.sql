@@ -0,0 +1,17 @@
+-- Historial de aplazamientos y cambios de fecha de los partidos.
+
+CREATE TABLE reprogramaciones (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    partido_id INTEGER NOT NULL,
+    estado_anterior VARCHAR(20) NOT NULL,
+    estado_nuevo VARCHAR(20) NOT NULL,
+    fecha_anterior DATETIME,
+    fecha_nueva DATETIME,
+    jornada_anterior_id INTEGER,
+    jornada_nueva_id INTEGER,
+    motivo VARCHAR(255),
+    usuario VARCHAR(255),
+    created_at DATETIME,
+    CONSTRAINT fk_partidos_reprogramaciones FOREIGN KEY (partido_id) REFERENCES partidos (id)
+);
+CREATE INDEX idx_reprogramaciones_partido_id ON reprogramaciones (partido_id);
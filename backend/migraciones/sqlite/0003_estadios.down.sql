@@ -0,0 +1,3 @@
+ALTER TABLE partidos DROP COLUMN estadio_id;
+ALTER TABLE equipos DROP COLUMN estadio_id;
+DROP TABLE IF EXISTS estadios;
@@ -0,0 +1,37 @@
+-- Estadios como entidad propia. Se crea un estadio por cada combinación de nombre de estadio
+-- y ciudad de los equipos existentes, y se asigna a cada equipo como su estadio local.
+-- SQLite no permite borrar columnas que tienen clave foránea, así que para que la migración
+-- se pueda revertir las columnas estadio_id no la declaran.
+
+CREATE TABLE estadios (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    nombre TEXT NOT NULL,
+    ciudad TEXT,
+    capacidad INTEGER NOT NULL DEFAULT 0,
+    latitud REAL,
+    longitud REAL,
+    superficie VARCHAR(20),
+    version INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX idx_estadios_deleted_at ON estadios (deleted_at);
+
+ALTER TABLE equipos ADD COLUMN estadio_id INTEGER;
+ALTER TABLE partidos ADD COLUMN estadio_id INTEGER;
+
+INSERT INTO estadios (created_at, updated_at, nombre, ciudad)
+SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, nombre, ciudad
+FROM (
+    SELECT DISTINCT estadio AS nombre, COALESCE(ciudad, '') AS ciudad
+    FROM equipos
+    WHERE estadio IS NOT NULL AND estadio <> '' AND deleted_at IS NULL
+) AS existentes
+ORDER BY nombre, ciudad;
+
+UPDATE equipos SET estadio_id = (
+    SELECT estadios.id FROM estadios
+    WHERE estadios.nombre = equipos.estadio AND estadios.ciudad = COALESCE(equipos.ciudad, '')
+)
+WHERE estadio IS NOT NULL AND estadio <> '' AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS designaciones_arbitros;
+DROP TABLE IF EXISTS arbitros;
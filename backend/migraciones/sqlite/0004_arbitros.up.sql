@@ -0,0 +1,25 @@
+-- Árbitros y su designación para cada partido. Cada partido tiene como mucho un árbitro por rol.
+
+CREATE TABLE arbitros (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    nombre TEXT NOT NULL,
+    ciudad TEXT,
+    categoria VARCHAR(50),
+    version INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX idx_arbitros_deleted_at ON arbitros (deleted_at);
+
+CREATE TABLE designaciones_arbitros (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    partido_id INTEGER NOT NULL,
+    arbitro_id INTEGER NOT NULL,
+    rol VARCHAR(20) NOT NULL,
+    created_at DATETIME,
+    CONSTRAINT fk_partidos_designaciones FOREIGN KEY (partido_id) REFERENCES partidos (id),
+    CONSTRAINT fk_arbitros_designaciones FOREIGN KEY (arbitro_id) REFERENCES arbitros (id)
+);
+CREATE UNIQUE INDEX idx_designaciones_partido_rol ON designaciones_arbitros (partido_id, rol);
+CREATE INDEX idx_designaciones_arbitro_id ON designaciones_arbitros (arbitro_id);
@@ -0,0 +1,5 @@
+ALTER TABLE partidos DROP COLUMN resumen_url;
+ALTER TABLE partidos DROP COLUMN cronica;
+ALTER TABLE partidos DROP COLUMN clima;
+ALTER TABLE partidos DROP COLUMN transmision;
+ALTER TABLE partidos DROP COLUMN espectadores;
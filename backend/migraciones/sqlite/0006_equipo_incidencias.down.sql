@@ -0,0 +1,2 @@
+ALTER TABLE incidencias DROP COLUMN jugador_entra_id;
+ALTER TABLE incidencias DROP COLUMN equipo_id;
@@ -0,0 +1,7 @@
+-- Equipo con el que jugaba el protagonista de cada incidencia y jugador que entra en una
+-- sustitución. Las incidencias existentes toman el equipo actual del jugador.
+
+ALTER TABLE incidencias ADD COLUMN equipo_id INTEGER;
+ALTER TABLE incidencias ADD COLUMN jugador_entra_id INTEGER REFERENCES jugadores (id);
+
+UPDATE incidencias SET equipo_id = (SELECT equipo_id FROM jugadores WHERE jugadores.id = incidencias.jugador_id);
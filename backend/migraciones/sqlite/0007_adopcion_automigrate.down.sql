@@ -0,0 +1,2 @@
+-- La adopción no se deshace: las columnas agregadas pertenecen al esquema inicial y los datos
+-- copiados ya están en las tablas que usa la aplicación.
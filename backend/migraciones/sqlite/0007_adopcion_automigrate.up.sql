@@ -0,0 +1,2 @@
+-- Adopción de las bases creadas con AutoMigrate. Solo aplica a PostgreSQL: las bases SQLite
+-- se crearon siempre con las migraciones versionadas.
@@ -0,0 +1,21 @@
+-- Los usuarios que se crean sin indicar el rol reciben el rol lector, que no tiene permisos de
+-- escritura, y no el de administrador.
+-- SQLite no permite cambiar el valor por defecto de una columna, así que se reconstruye la tabla.
+
+CREATE TABLE usuarios_nueva (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    email TEXT NOT NULL,
+    password TEXT NOT NULL,
+    nombre TEXT NOT NULL,
+    rol TEXT NOT NULL DEFAULT 'lector',
+    activo NUMERIC NOT NULL DEFAULT true,
+    CONSTRAINT uni_usuarios_email UNIQUE (email)
+);
+INSERT INTO usuarios_nueva (id, created_at, updated_at, deleted_at, email, password, nombre, rol, activo)
+SELECT id, created_at, updated_at, deleted_at, email, password, nombre, rol, activo FROM usuarios;
+DROP TABLE usuarios;
+ALTER TABLE usuarios_nueva RENAME TO usuarios;
+CREATE INDEX idx_usuarios_deleted_at ON usuarios (deleted_at);
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/database"
+)
+
+const usoMigrate = `Uso: go run . migrate <comando>
+
+Comandos:
+  up              Aplica todas las migraciones pendientes
+  down [pasos]    Revierte las últimas migraciones aplicadas (por defecto 1)
+  status          Muestra el estado de cada migración
+  create <nombre> Crea los archivos up y down de una nueva migración para cada driver`
+
+// ejecutarMigrate atiende el subcomando migrate y devuelve el código de salida del proceso
+func ejecutarMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usoMigrate)
+		return 2
+	}
+
+	// create solo escribe archivos y no necesita conexión a la base de datos
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usoMigrate)
+			return 2
+		}
+		archivos, err := database.CrearMigracion(cfg.BaseDatos.DirectorioMigraciones, args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al crear la migración: %v\n", err)
+			return 1
+		}
+		for _, archivo := range archivos {
+			fmt.Printf("Creado %s\n", archivo)
+		}
+		return 0
+	}
+
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	migrador, err := database.NewMigrador(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al cargar las migraciones: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		aplicadas, err := migrador.Subir()
+		for _, migracion := range aplicadas {
+			fmt.Printf("Aplicada %04d_%s\n", migracion.Version, migracion.Nombre)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(aplicadas) == 0 {
+			fmt.Println("No hay migraciones pendientes")
+		}
+	case "down":
+		pasos := 1
+		if len(args) > 1 {
+			if pasos, err = strconv.Atoi(args[1]); err != nil || pasos < 1 {
+				fmt.Fprintf(os.Stderr, "Número de pasos inválido: %s\n", args[1])
+				return 2
+			}
+		}
+		revertidas, err := migrador.Bajar(pasos)
+		for _, migracion := range revertidas {
+			fmt.Printf("Revertida %04d_%s\n", migracion.Version, migracion.Nombre)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(revertidas) == 0 {
+			fmt.Println("No hay migraciones aplicadas")
+		}
+	case "status":
+		estados, err := migrador.Estado()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, estado := range estados {
+			aplicadaEn := "-"
+			if estado.AplicadaEn != nil {
+				aplicadaEn = estado.AplicadaEn.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %-12s %s\n", estado.Version, estado.Nombre, estado.Estado, aplicadaEn)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usoMigrate)
+		return 2
+	}
+
+	return 0
+}
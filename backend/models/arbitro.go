@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Roles de los árbitros designados para un partido
+const (
+	RolArbitroPrincipal  = "principal"
+	RolArbitroAsistente1 = "asistente_1"
+	RolArbitroAsistente2 = "asistente_2"
+	RolArbitroVAR        = "var"
+)
+
+// RolesArbitro son los roles de la designación arbitral de un partido, en orden
+var RolesArbitro = []string{RolArbitroPrincipal, RolArbitroAsistente1, RolArbitroAsistente2, RolArbitroVAR}
+
+// Arbitro representa a un árbitro del torneo
+type Arbitro struct {
+	gorm.Model
+	Nombre    string `json:"nombre" gorm:"not null"`
+	Ciudad    string `json:"ciudad"`                            // Ciudad de origen, para no dirigir a equipos de su ciudad
+	Categoria string `json:"categoria" gorm:"size:50"`          // Categoría o escalafón, ej: FIFA, nacional
+	Version   uint   `json:"version" gorm:"not null;default:1"` // Para control de concurrencia optimista
+}
+
+// DesignacionArbitro asigna un árbitro a un partido con uno de los roles de la terna arbitral
+type DesignacionArbitro struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PartidoID uint      `json:"partidoId" gorm:"not null"`
+	ArbitroID uint      `json:"arbitroId" gorm:"not null"`
+	Rol       string    `json:"rol" gorm:"size:20;not null"` // principal, asistente_1, asistente_2, var
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName evita que GORM pluralice solo la última palabra del nombre
+func (DesignacionArbitro) TableName() string {
+	return "designaciones_arbitros"
+}
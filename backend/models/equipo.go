@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Equipo representa un equipo de fútbol en el torneo
 type Equipo struct {
@@ -9,8 +13,10 @@ type Equipo struct {
 	NombreCorto string `json:"nombreCorto" binding:"required"`
 	Ciudad      string `json:"ciudad" binding:"required"`
 	Estadio     string `json:"estadio" binding:"required"`
+	EstadioID   *uint  `json:"estadioId"` // Estadio donde juega de local; Estadio conserva su nombre
 	Fundacion   string `json:"fundacion" binding:"required"`
 	Escudo      string `json:"escudo" binding:"required"`
+	Version     uint   `json:"version" gorm:"not null;default:1"` // Para control de concurrencia optimista
 	Jugadores    []Jugador `json:"jugadores,omitempty" gorm:"foreignKey:EquipoID"`
 	PJ           int       `json:"pj" gorm:"-"` // Partidos jugados
 	PG           int       `json:"pg" gorm:"-"` // Partidos ganados
@@ -21,5 +27,18 @@ type Equipo struct {
 	DG           int       `json:"dg" gorm:"-"` // Diferencia de goles
 	Puntos       int       `json:"puntos" gorm:"-"`
 	Posicion     int       `json:"posicion" gorm:"-"`
-	UltimosJuegos string    `json:"ultimosJuegos" gorm:"-"` // Ej: "VVEPD" (Victoria, Victoria, Empate, Perdido, Derrota)
+	UltimosJuegos string    `json:"ultimosJuegos" gorm:"-"` // Ej: "VVEDD", del partido más antiguo al más reciente
+	Forma         []PartidoForma `json:"forma,omitempty" gorm:"-"`
+}
+
+// PartidoForma representa un partido reciente dentro de la forma de un equipo
+type PartidoForma struct {
+	PartidoID   uint      `json:"partidoId"`
+	FechaHora   time.Time `json:"fechaHora"`
+	RivalID     uint      `json:"rivalId"`
+	Rival       string    `json:"rival"`
+	Local       bool      `json:"local"`
+	GolesFavor  int       `json:"golesFavor"`
+	GolesContra int       `json:"golesContra"`
+	Resultado   string    `json:"resultado"` // V, E o D
 }
@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// Superficies de juego de un estadio
+const (
+	SuperficieNatural    = "natural"
+	SuperficieArtificial = "artificial"
+	SuperficieHibrida    = "hibrida"
+)
+
+// Estadio representa un escenario donde se juegan partidos. Varios equipos pueden
+// compartirlo como local y un partido puede jugarse en un estadio neutral.
+type Estadio struct {
+	gorm.Model
+	Nombre     string   `json:"nombre" gorm:"not null"`
+	Ciudad     string   `json:"ciudad"`
+	Capacidad  int      `json:"capacidad"`
+	Latitud    *float64 `json:"latitud"`
+	Longitud   *float64 `json:"longitud"`
+	Superficie string   `json:"superficie" gorm:"size:20"`         // natural, artificial, hibrida
+	Version    uint     `json:"version" gorm:"not null;default:1"` // Para control de concurrencia optimista
+}
@@ -8,6 +8,7 @@ type TipoIncidencia string
 const (
 	Gol            TipoIncidencia = "GOL"
 	GolPenal       TipoIncidencia = "GOL_PENAL"
+	PenalFallado   TipoIncidencia = "PENAL_FALLADO" // Penal atajado o desviado; no cambia el marcador
 	GolEnContra    TipoIncidencia = "GOL_EN_CONTRA"
 	TarjetaAmarilla TipoIncidencia = "TARJETA_AMARILLA"
 	TarjetaRoja    TipoIncidencia = "TARJETA_ROJA"
@@ -21,8 +22,26 @@ type Incidencia struct {
 	PartidoID   uint           `json:"partidoId" gorm:"not null"`
 	JugadorID   uint           `json:"jugadorId" gorm:"not null"`
 	Jugador     Jugador        `json:"jugador,omitempty" gorm:"foreignKey:JugadorID"`
+	EquipoID    uint           `json:"equipoId"` // Equipo con el que jugaba el jugador en el partido
+	JugadorEntraID *uint       `json:"jugadorEntraId,omitempty"` // En una sustitución, el jugador que entra al campo
 	Tipo        TipoIncidencia `json:"tipo" gorm:"size:20;not null"`
 	Minuto      int            `json:"minuto"`
+	MinutoAnadido int          `json:"minutoAnadido"` // Minuto dentro del tiempo añadido, ej: 2 en el 45+2
 	Descripcion string         `json:"descripcion" gorm:"size:255"`
 	Timestamp   time.Time      `json:"timestamp"`
+	Secuencia   uint           `json:"secuencia"` // Secuencia del partido en la que se registró
+}
+
+// TableName evita que GORM tome "incidencia" como plural y deje el nombre sin pluralizar
+func (Incidencia) TableName() string {
+	return "incidencias"
+}
+
+// Valido indica si el tipo de incidencia es uno de los tipos conocidos
+func (t TipoIncidencia) Valido() bool {
+	switch t {
+	case Gol, GolPenal, PenalFallado, GolEnContra, TarjetaAmarilla, TarjetaRoja, Sustitucion, Asistencia:
+		return true
+	}
+	return false
 }
@@ -9,4 +9,5 @@ type Jornada struct {
 	Fecha     time.Time `json:"fecha"`
 	Partidos  []Partido `json:"partidos,omitempty" gorm:"foreignKey:JornadaID"`
 	Completada bool     `json:"completada" gorm:"default:false"`
+	Version    uint     `json:"version" gorm:"not null;default:1"` // Para control de concurrencia optimista
 }
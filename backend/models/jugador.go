@@ -15,6 +15,7 @@ type Jugador struct {
 	Peso            float64   `json:"peso"`   // En kilogramos
 	Foto            string    `json:"foto" gorm:"size:255"`
 	EquipoID        uint      `json:"equipoId" gorm:"not null"`
+	Version         uint      `json:"version" gorm:"not null;default:1"` // Para control de concurrencia optimista
 	Goles           int       `json:"goles" gorm:"-"`
 	Asistencias     int       `json:"asistencias" gorm:"-"`
 	TarjetasAmarillas int     `json:"tarjetasAmarillas" gorm:"-"`
@@ -22,3 +23,8 @@ type Jugador struct {
 	MinutosJugados  int       `json:"minutosJugados" gorm:"-"`
 	PartidosJugados int       `json:"partidosJugados" gorm:"-"`
 }
+
+// TableName evita que GORM pluralice el nombre como "jugadors"
+func (Jugador) TableName() string {
+	return "jugadores"
+}
@@ -6,6 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// Estados posibles de un partido
+const (
+	EstadoPendiente  = "pendiente"
+	EstadoEnCurso    = "en_curso"
+	EstadoFinalizado = "finalizado"
+	EstadoAplazado   = "aplazado" // Suspendido antes de jugarse, a la espera de una nueva fecha
+)
+
+// Periodos de juego de un partido. Un partido sin iniciar tiene el periodo vacío.
+const (
+	PeriodoPrimerTiempo  = "primer_tiempo"
+	PeriodoDescanso      = "descanso"
+	PeriodoSegundoTiempo = "segundo_tiempo"
+	PeriodoFinal         = "final"
+)
+
+// DuracionPeriodo es la duración reglamentaria de cada tiempo, en minutos
+const DuracionPeriodo = 45
+
 // Partido representa un partido del torneo
 type Partido struct {
 	gorm.Model
@@ -15,6 +34,17 @@ type Partido struct {
 	GolesLocal       int       `json:"golesLocal"`
 	GolesVisitante   int       `json:"golesVisitante"`
 	FechaHora        time.Time `json:"fechaHora"`
-	Estado           string    `json:"estado"` // pendiente, en_curso, finalizado
+	EstadioID        *uint     `json:"estadioId"` // Solo si no se juega en el estadio del equipo local
+	Estado           string    `json:"estado"` // pendiente, en_curso, finalizado, aplazado
+	Periodo          string     `json:"periodo" gorm:"size:20"` // primer_tiempo, descanso, segundo_tiempo, final
+	InicioPartido    *time.Time `json:"inicioPartido"`
+	InicioPeriodo    *time.Time `json:"inicioPeriodo"`
+	TiempoAnadido    int        `json:"tiempoAnadido"` // Minutos añadidos anunciados en el periodo actual
+	Secuencia        uint       `json:"secuencia" gorm:"not null;default:0"` // Aumenta con cada cambio registrado en el partido
+	Espectadores     *int       `json:"espectadores"` // Asistencia de público, si se conoce
+	Transmision      string     `json:"transmision" gorm:"size:100"` // Canal o plataforma que transmite el partido
+	Clima            string     `json:"clima" gorm:"size:100"`
+	Cronica          string     `json:"cronica"` // Crónica del partido
+	ResumenURL       string     `json:"resumenUrl" gorm:"size:255"` // Video con las mejores jugadas
 	Incidencias      []Incidencia `json:"incidencias,omitempty" gorm:"foreignKey:PartidoID"`
 }
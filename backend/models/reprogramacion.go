@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Reprogramacion registra un aplazamiento o un cambio de fecha de un partido
+type Reprogramacion struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	PartidoID         uint       `json:"partidoId" gorm:"not null;index"`
+	EstadoAnterior    string     `json:"estadoAnterior" gorm:"size:20;not null"`
+	EstadoNuevo       string     `json:"estadoNuevo" gorm:"size:20;not null"`
+	FechaAnterior     time.Time  `json:"fechaAnterior"`
+	FechaNueva        *time.Time `json:"fechaNueva"` // nil en un aplazamiento sin nueva fecha
+	JornadaAnteriorID uint       `json:"jornadaAnteriorId"`
+	JornadaNuevaID    uint       `json:"jornadaNuevaId"`
+	Motivo            string     `json:"motivo" gorm:"size:255"`
+	Usuario           string     `json:"usuario" gorm:"size:255"` // Email de quien hizo el cambio
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// TableName evita que GORM pluralice el nombre como "reprogramacions"
+func (Reprogramacion) TableName() string {
+	return "reprogramaciones"
+}
@@ -5,12 +5,23 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Roles de los usuarios. Solo un administrador puede dar los roles admin y editor; quien se
+// registra por su cuenta recibe el rol lector, que no tiene permisos de escritura.
+const (
+	RolAdmin  = "admin"
+	RolEditor = "editor"
+	RolLector = "lector"
+)
+
+// Roles son los roles válidos de un usuario
+var Roles = []string{RolAdmin, RolEditor, RolLector}
+
 type Usuario struct {
 	gorm.Model
 	Email        string `gorm:"unique;not null"`
 	Password     string `gorm:"not null"`
 	Nombre       string `gorm:"not null"`
-	Rol          string `gorm:"not null;default:'admin'"` // admin, editor
+	Rol          string `gorm:"not null;default:'lector'"` // admin, editor, lector
 	Activo       bool   `gorm:"not null;default:true"`
 }
 
@@ -0,0 +1,358 @@
+package repositorios
+
+import (
+	"github.com/noisk8/torneas/backend/models"
+	"gorm.io/gorm"
+)
+
+// NewGORM crea los repositorios que guardan los datos en la base de datos
+func NewGORM(db *gorm.DB) Repositorios {
+	return Repositorios{
+		Equipos:    &equipoGORM{db: db},
+		Jugadores:  &jugadorGORM{db: db},
+		Estadios:   &estadioGORM{db: db},
+		Arbitros:   &arbitroGORM{db: db},
+		Calendario: &calendarioGORM{db: db},
+		transaccion: func(fn func(repos Repositorios) error) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				return fn(NewGORM(tx))
+			})
+		},
+	}
+}
+
+// guardarConVersion guarda todos los campos de modelo solo si la columna de versión sigue
+// teniendo el valor de *version en la base de datos, y en ese caso incrementa la versión.
+// modelo debe ser un puntero a un registro con clave primaria.
+func guardarConVersion(db *gorm.DB, modelo interface{}, columna string, version *uint) error {
+	anterior := *version
+	*version++
+
+	result := db.Model(modelo).Where(columna+" = ?", anterior).Select("*").Updates(modelo)
+	if result.Error != nil {
+		*version = anterior
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = anterior
+		return ErrVersionObsoleta
+	}
+	return nil
+}
+
+type equipoGORM struct {
+	db *gorm.DB
+}
+
+func (r *equipoGORM) Listar() ([]models.Equipo, error) {
+	var equipos []models.Equipo
+	err := r.db.Order("id").Find(&equipos).Error
+	return equipos, err
+}
+
+func (r *equipoGORM) Obtener(id uint) (models.Equipo, error) {
+	var equipo models.Equipo
+	err := r.db.First(&equipo, id).Error
+	return equipo, err
+}
+
+func (r *equipoGORM) ObtenerVarios(ids []uint) ([]models.Equipo, error) {
+	var equipos []models.Equipo
+	if len(ids) == 0 {
+		return equipos, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id").Find(&equipos).Error
+	return equipos, err
+}
+
+func (r *equipoGORM) Crear(equipo *models.Equipo) error {
+	return r.db.Create(equipo).Error
+}
+
+func (r *equipoGORM) Actualizar(equipo *models.Equipo) error {
+	return guardarConVersion(r.db, equipo, "version", &equipo.Version)
+}
+
+func (r *equipoGORM) Eliminar(id uint) error {
+	return r.db.Delete(&models.Equipo{}, id).Error
+}
+
+type jugadorGORM struct {
+	db *gorm.DB
+}
+
+func (r *jugadorGORM) Listar() ([]models.Jugador, error) {
+	var jugadores []models.Jugador
+	err := r.db.Order("id").Find(&jugadores).Error
+	return jugadores, err
+}
+
+func (r *jugadorGORM) ListarPorEquipo(equipoID uint) ([]models.Jugador, error) {
+	var jugadores []models.Jugador
+	err := r.db.Where("equipo_id = ?", equipoID).Order("id").Find(&jugadores).Error
+	return jugadores, err
+}
+
+func (r *jugadorGORM) Obtener(id uint) (models.Jugador, error) {
+	var jugador models.Jugador
+	err := r.db.First(&jugador, id).Error
+	return jugador, err
+}
+
+func (r *jugadorGORM) Crear(jugador *models.Jugador) error {
+	return r.db.Create(jugador).Error
+}
+
+func (r *jugadorGORM) Actualizar(jugador *models.Jugador) error {
+	return guardarConVersion(r.db, jugador, "version", &jugador.Version)
+}
+
+func (r *jugadorGORM) Eliminar(id uint) error {
+	return r.db.Delete(&models.Jugador{}, id).Error
+}
+
+type estadioGORM struct {
+	db *gorm.DB
+}
+
+func (r *estadioGORM) Listar() ([]models.Estadio, error) {
+	var estadios []models.Estadio
+	err := r.db.Order("id").Find(&estadios).Error
+	return estadios, err
+}
+
+func (r *estadioGORM) Obtener(id uint) (models.Estadio, error) {
+	var estadio models.Estadio
+	err := r.db.First(&estadio, id).Error
+	return estadio, err
+}
+
+func (r *estadioGORM) ObtenerVarios(ids []uint) ([]models.Estadio, error) {
+	var estadios []models.Estadio
+	if len(ids) == 0 {
+		return estadios, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id").Find(&estadios).Error
+	return estadios, err
+}
+
+func (r *estadioGORM) Crear(estadio *models.Estadio) error {
+	return r.db.Create(estadio).Error
+}
+
+func (r *estadioGORM) Actualizar(estadio *models.Estadio) error {
+	return guardarConVersion(r.db, estadio, "version", &estadio.Version)
+}
+
+func (r *estadioGORM) Eliminar(id uint) error {
+	return r.db.Delete(&models.Estadio{}, id).Error
+}
+
+type arbitroGORM struct {
+	db *gorm.DB
+}
+
+func (r *arbitroGORM) Listar() ([]models.Arbitro, error) {
+	var arbitros []models.Arbitro
+	err := r.db.Order("id").Find(&arbitros).Error
+	return arbitros, err
+}
+
+func (r *arbitroGORM) Obtener(id uint) (models.Arbitro, error) {
+	var arbitro models.Arbitro
+	err := r.db.First(&arbitro, id).Error
+	return arbitro, err
+}
+
+func (r *arbitroGORM) ObtenerVarios(ids []uint) ([]models.Arbitro, error) {
+	var arbitros []models.Arbitro
+	if len(ids) == 0 {
+		return arbitros, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id").Find(&arbitros).Error
+	return arbitros, err
+}
+
+func (r *arbitroGORM) Crear(arbitro *models.Arbitro) error {
+	return r.db.Create(arbitro).Error
+}
+
+func (r *arbitroGORM) Actualizar(arbitro *models.Arbitro) error {
+	return guardarConVersion(r.db, arbitro, "version", &arbitro.Version)
+}
+
+func (r *arbitroGORM) Eliminar(id uint) error {
+	return r.db.Delete(&models.Arbitro{}, id).Error
+}
+
+func (r *arbitroGORM) BuscarDesignaciones(filtro FiltroDesignaciones) ([]models.DesignacionArbitro, error) {
+	query := r.db.Model(&models.DesignacionArbitro{})
+	if len(filtro.PartidoIDs) > 0 {
+		query = query.Where("partido_id IN ?", filtro.PartidoIDs)
+	}
+	if filtro.ArbitroID != 0 {
+		query = query.Where("arbitro_id = ?", filtro.ArbitroID)
+	}
+
+	var designaciones []models.DesignacionArbitro
+	err := query.Order("partido_id, id").Find(&designaciones).Error
+	return designaciones, err
+}
+
+func (r *arbitroGORM) DesignarPartido(partidoID uint, designaciones []models.DesignacionArbitro) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("partido_id = ?", partidoID).Delete(&models.DesignacionArbitro{}).Error; err != nil {
+			return err
+		}
+		for i := range designaciones {
+			designaciones[i].PartidoID = partidoID
+			if err := tx.Create(&designaciones[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type calendarioGORM struct {
+	db *gorm.DB
+}
+
+func (r *calendarioGORM) ListarJornadas() ([]models.Jornada, error) {
+	var jornadas []models.Jornada
+	err := r.db.Order("numero").Find(&jornadas).Error
+	return jornadas, err
+}
+
+func (r *calendarioGORM) ObtenerJornada(id uint) (models.Jornada, error) {
+	var jornada models.Jornada
+	err := r.db.First(&jornada, id).Error
+	return jornada, err
+}
+
+func (r *calendarioGORM) ObtenerJornadaPorNumero(numero int) (models.Jornada, error) {
+	var jornada models.Jornada
+	err := r.db.Where("numero = ?", numero).First(&jornada).Error
+	return jornada, err
+}
+
+func (r *calendarioGORM) CrearJornada(jornada *models.Jornada) error {
+	return r.db.Create(jornada).Error
+}
+
+func (r *calendarioGORM) ActualizarJornada(jornada *models.Jornada) error {
+	return guardarConVersion(r.db, jornada, "version", &jornada.Version)
+}
+
+func (r *calendarioGORM) EliminarJornada(id uint) error {
+	return r.db.Delete(&models.Jornada{}, id).Error
+}
+
+func (r *calendarioGORM) ObtenerPartido(id uint) (models.Partido, error) {
+	var partido models.Partido
+	err := r.db.First(&partido, id).Error
+	return partido, err
+}
+
+func (r *calendarioGORM) BuscarPartidos(filtro FiltroPartidos) ([]models.Partido, error) {
+	query := r.db.Model(&models.Partido{})
+	if len(filtro.IDs) > 0 {
+		query = query.Where("id IN ?", filtro.IDs)
+	}
+	if filtro.EquipoID != 0 && filtro.RivalID != 0 {
+		query = query.Where("(equipo_local_id = ? AND equipo_visitante_id = ?) OR (equipo_local_id = ? AND equipo_visitante_id = ?)",
+			filtro.EquipoID, filtro.RivalID, filtro.RivalID, filtro.EquipoID)
+	} else if filtro.EquipoID != 0 {
+		query = query.Where("equipo_local_id = ? OR equipo_visitante_id = ?", filtro.EquipoID, filtro.EquipoID)
+	}
+	if filtro.JornadaID != 0 {
+		query = query.Where("jornada_id = ?", filtro.JornadaID)
+	}
+	if filtro.Estado != "" {
+		query = query.Where("estado = ?", filtro.Estado)
+	}
+	if filtro.Desde != nil {
+		query = query.Where("fecha_hora >= ?", *filtro.Desde)
+	}
+	if filtro.Hasta != nil {
+		query = query.Where("fecha_hora < ?", *filtro.Hasta)
+	}
+	if filtro.Descendente {
+		query = query.Order("fecha_hora DESC, id DESC")
+	} else {
+		query = query.Order("fecha_hora, id")
+	}
+	if filtro.Limite > 0 {
+		query = query.Limit(filtro.Limite)
+	}
+
+	var partidos []models.Partido
+	err := query.Find(&partidos).Error
+	return partidos, err
+}
+
+func (r *calendarioGORM) CrearPartido(partido *models.Partido) error {
+	return r.db.Create(partido).Error
+}
+
+func (r *calendarioGORM) GuardarPartido(partido *models.Partido) error {
+	return guardarConVersion(r.db, partido, "secuencia", &partido.Secuencia)
+}
+
+func (r *calendarioGORM) EliminarPartidosDeJornada(jornadaID uint) error {
+	partidos := r.db.Model(&models.Partido{}).Select("id").Where("jornada_id = ?", jornadaID)
+	if err := r.db.Where("partido_id IN (?)", partidos).Delete(&models.DesignacionArbitro{}).Error; err != nil {
+		return err
+	}
+	// Sin Unscoped quedarían partidos borrados lógicamente que impiden eliminar la jornada
+	return r.db.Unscoped().Where("jornada_id = ?", jornadaID).Delete(&models.Partido{}).Error
+}
+
+func (r *calendarioGORM) BuscarIncidencias(filtro FiltroIncidencias) ([]models.Incidencia, error) {
+	query := r.db.Model(&models.Incidencia{})
+	if len(filtro.PartidoIDs) > 0 {
+		query = query.Where("partido_id IN ?", filtro.PartidoIDs)
+	}
+	if filtro.JugadorID != 0 {
+		query = query.Where("jugador_id = ?", filtro.JugadorID)
+	}
+	if filtro.Participante != 0 {
+		query = query.Where("jugador_id = ? OR jugador_entra_id = ?", filtro.Participante, filtro.Participante)
+	}
+	if len(filtro.Tipos) > 0 {
+		query = query.Where("tipo IN ?", filtro.Tipos)
+	}
+	if filtro.SecuenciaMayorA > 0 {
+		query = query.Where("secuencia > ?", filtro.SecuenciaMayorA)
+	}
+	if filtro.ConJugador {
+		query = query.Preload("Jugador")
+	}
+
+	var incidencias []models.Incidencia
+	err := query.Order("partido_id, secuencia, id").Find(&incidencias).Error
+	return incidencias, err
+}
+
+func (r *calendarioGORM) CrearIncidencia(incidencia *models.Incidencia) error {
+	return r.db.Create(incidencia).Error
+}
+
+func (r *calendarioGORM) BuscarReprogramaciones(partidoIDs []uint) ([]models.Reprogramacion, error) {
+	var reprogramaciones []models.Reprogramacion
+	if len(partidoIDs) == 0 {
+		return reprogramaciones, nil
+	}
+	err := r.db.Where("partido_id IN ?", partidoIDs).Order("id").Find(&reprogramaciones).Error
+	return reprogramaciones, err
+}
+
+func (r *calendarioGORM) CrearReprogramacion(reprogramacion *models.Reprogramacion) error {
+	return r.db.Create(reprogramacion).Error
+}
+
+func (r *calendarioGORM) Transaccion(fn func(repo CalendarioRepositorio) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&calendarioGORM{db: tx})
+	})
+}
@@ -0,0 +1,695 @@
+package repositorios
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+// almacen guarda en memoria los datos de todos los repositorios. Los registros se guardan
+// sin sus relaciones y se devuelven como copias, igual que al leerlos de la base de datos.
+type almacen struct {
+	mu          sync.Mutex
+	ultimosIDs  map[string]uint
+	equipos     map[uint]models.Equipo
+	jugadores   map[uint]models.Jugador
+	estadios    map[uint]models.Estadio
+	arbitros    map[uint]models.Arbitro
+	jornadas    map[uint]models.Jornada
+	partidos    map[uint]models.Partido
+	incidencias map[uint]models.Incidencia
+
+	reprogramaciones map[uint]models.Reprogramacion
+	designaciones    map[uint]models.DesignacionArbitro
+}
+
+// vistaAlmacen es el acceso de un repositorio al almacén. Fuera de una transacción cada
+// operación toma el candado del almacén; dentro, la transacción lo tiene tomado de principio
+// a fin, así que ninguna otra operación puede cambiar los datos que restaura si falla.
+type vistaAlmacen struct {
+	*almacen
+	mu sync.Locker
+}
+
+// sinCandado es el candado de las operaciones dentro de una transacción
+type sinCandado struct{}
+
+func (sinCandado) Lock()   {}
+func (sinCandado) Unlock() {}
+
+// NewEnMemoria crea repositorios vacíos que guardan los datos en memoria, pensados para
+// probar la lógica de los servicios sin base de datos
+func NewEnMemoria() Repositorios {
+	a := &almacen{
+		ultimosIDs:  make(map[string]uint),
+		equipos:     make(map[uint]models.Equipo),
+		jugadores:   make(map[uint]models.Jugador),
+		estadios:    make(map[uint]models.Estadio),
+		arbitros:    make(map[uint]models.Arbitro),
+		jornadas:    make(map[uint]models.Jornada),
+		partidos:    make(map[uint]models.Partido),
+		incidencias: make(map[uint]models.Incidencia),
+
+		reprogramaciones: make(map[uint]models.Reprogramacion),
+		designaciones:    make(map[uint]models.DesignacionArbitro),
+	}
+	return a.repositorios(&a.mu)
+}
+
+// repositorios crea los repositorios sobre el almacén; cada operación toma el candado mu
+func (a *almacen) repositorios(mu sync.Locker) Repositorios {
+	vista := vistaAlmacen{a, mu}
+	repos := Repositorios{
+		Equipos:    &equipoMemoria{vista},
+		Jugadores:  &jugadorMemoria{vista},
+		Estadios:   &estadioMemoria{vista},
+		Arbitros:   &arbitroMemoria{vista},
+		Calendario: &calendarioMemoria{vista},
+	}
+	repos.transaccion = func(fn func(repos Repositorios) error) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		restaurar := a.instantanea()
+		if err := fn(a.repositorios(sinCandado{})); err != nil {
+			restaurar()
+			return err
+		}
+		return nil
+	}
+	return repos
+}
+
+// instantanea copia todos los registros y devuelve una función que los restaura. Se llama
+// con el candado del almacén tomado.
+func (a *almacen) instantanea() func() {
+	copia := almacen{
+		ultimosIDs:       maps.Clone(a.ultimosIDs),
+		equipos:          maps.Clone(a.equipos),
+		jugadores:        maps.Clone(a.jugadores),
+		estadios:         maps.Clone(a.estadios),
+		arbitros:         maps.Clone(a.arbitros),
+		jornadas:         maps.Clone(a.jornadas),
+		partidos:         maps.Clone(a.partidos),
+		incidencias:      maps.Clone(a.incidencias),
+		reprogramaciones: maps.Clone(a.reprogramaciones),
+		designaciones:    maps.Clone(a.designaciones),
+	}
+	return func() {
+		a.ultimosIDs = copia.ultimosIDs
+		a.equipos = copia.equipos
+		a.jugadores = copia.jugadores
+		a.estadios = copia.estadios
+		a.arbitros = copia.arbitros
+		a.jornadas = copia.jornadas
+		a.partidos = copia.partidos
+		a.incidencias = copia.incidencias
+		a.reprogramaciones = copia.reprogramaciones
+		a.designaciones = copia.designaciones
+	}
+}
+
+// nuevoID asigna un ID autoincremental de la tabla si el registro no trae uno
+func (a *almacen) nuevoID(tabla string, id *uint) {
+	if *id == 0 {
+		a.ultimosIDs[tabla]++
+		*id = a.ultimosIDs[tabla]
+	} else if *id > a.ultimosIDs[tabla] {
+		a.ultimosIDs[tabla] = *id
+	}
+}
+
+// valores devuelve los registros de un mapa ordenados por ID
+func valores[T any](registros map[uint]T) []T {
+	ids := slices.Sorted(maps.Keys(registros))
+	lista := make([]T, 0, len(ids))
+	for _, id := range ids {
+		lista = append(lista, registros[id])
+	}
+	return lista
+}
+
+type equipoMemoria struct {
+	vistaAlmacen
+}
+
+func (r *equipoMemoria) Listar() ([]models.Equipo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return valores(r.equipos), nil
+}
+
+func (r *equipoMemoria) Obtener(id uint) (models.Equipo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	equipo, ok := r.equipos[id]
+	if !ok {
+		return equipo, ErrNoEncontrado
+	}
+	return equipo, nil
+}
+
+func (r *equipoMemoria) ObtenerVarios(ids []uint) ([]models.Equipo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	equipos := []models.Equipo{}
+	for _, equipo := range valores(r.equipos) {
+		if slices.Contains(ids, equipo.ID) {
+			equipos = append(equipos, equipo)
+		}
+	}
+	return equipos, nil
+}
+
+func (r *equipoMemoria) Crear(equipo *models.Equipo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("equipos", &equipo.ID)
+	if equipo.Version == 0 {
+		equipo.Version = 1
+	}
+	equipo.CreatedAt = time.Now()
+	equipo.UpdatedAt = equipo.CreatedAt
+	copia := *equipo
+	copia.Jugadores = nil
+	r.equipos[equipo.ID] = copia
+	return nil
+}
+
+func (r *equipoMemoria) Actualizar(equipo *models.Equipo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := r.equipos[equipo.ID]
+	if !ok || actual.Version != equipo.Version {
+		return ErrVersionObsoleta
+	}
+	equipo.Version++
+	equipo.UpdatedAt = time.Now()
+	copia := *equipo
+	copia.Jugadores = nil
+	r.equipos[equipo.ID] = copia
+	return nil
+}
+
+func (r *equipoMemoria) Eliminar(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.equipos, id)
+	return nil
+}
+
+type jugadorMemoria struct {
+	vistaAlmacen
+}
+
+func (r *jugadorMemoria) Listar() ([]models.Jugador, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return valores(r.jugadores), nil
+}
+
+func (r *jugadorMemoria) ListarPorEquipo(equipoID uint) ([]models.Jugador, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jugadores := []models.Jugador{}
+	for _, jugador := range valores(r.jugadores) {
+		if jugador.EquipoID == equipoID {
+			jugadores = append(jugadores, jugador)
+		}
+	}
+	return jugadores, nil
+}
+
+func (r *jugadorMemoria) Obtener(id uint) (models.Jugador, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jugador, ok := r.jugadores[id]
+	if !ok {
+		return jugador, ErrNoEncontrado
+	}
+	return jugador, nil
+}
+
+func (r *jugadorMemoria) Crear(jugador *models.Jugador) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("jugadores", &jugador.ID)
+	if jugador.Version == 0 {
+		jugador.Version = 1
+	}
+	r.jugadores[jugador.ID] = *jugador
+	return nil
+}
+
+func (r *jugadorMemoria) Actualizar(jugador *models.Jugador) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := r.jugadores[jugador.ID]
+	if !ok || actual.Version != jugador.Version {
+		return ErrVersionObsoleta
+	}
+	jugador.Version++
+	r.jugadores[jugador.ID] = *jugador
+	return nil
+}
+
+func (r *jugadorMemoria) Eliminar(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jugadores, id)
+	return nil
+}
+
+type estadioMemoria struct {
+	vistaAlmacen
+}
+
+func (r *estadioMemoria) Listar() ([]models.Estadio, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return valores(r.estadios), nil
+}
+
+func (r *estadioMemoria) Obtener(id uint) (models.Estadio, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	estadio, ok := r.estadios[id]
+	if !ok {
+		return estadio, ErrNoEncontrado
+	}
+	return estadio, nil
+}
+
+func (r *estadioMemoria) ObtenerVarios(ids []uint) ([]models.Estadio, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	estadios := []models.Estadio{}
+	for _, estadio := range valores(r.estadios) {
+		if slices.Contains(ids, estadio.ID) {
+			estadios = append(estadios, estadio)
+		}
+	}
+	return estadios, nil
+}
+
+func (r *estadioMemoria) Crear(estadio *models.Estadio) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("estadios", &estadio.ID)
+	if estadio.Version == 0 {
+		estadio.Version = 1
+	}
+	estadio.CreatedAt = time.Now()
+	estadio.UpdatedAt = estadio.CreatedAt
+	r.estadios[estadio.ID] = *estadio
+	return nil
+}
+
+func (r *estadioMemoria) Actualizar(estadio *models.Estadio) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := r.estadios[estadio.ID]
+	if !ok || actual.Version != estadio.Version {
+		return ErrVersionObsoleta
+	}
+	estadio.Version++
+	estadio.UpdatedAt = time.Now()
+	r.estadios[estadio.ID] = *estadio
+	return nil
+}
+
+func (r *estadioMemoria) Eliminar(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.estadios, id)
+	return nil
+}
+
+type arbitroMemoria struct {
+	vistaAlmacen
+}
+
+func (r *arbitroMemoria) Listar() ([]models.Arbitro, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return valores(r.arbitros), nil
+}
+
+func (r *arbitroMemoria) Obtener(id uint) (models.Arbitro, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	arbitro, ok := r.arbitros[id]
+	if !ok {
+		return arbitro, ErrNoEncontrado
+	}
+	return arbitro, nil
+}
+
+func (r *arbitroMemoria) ObtenerVarios(ids []uint) ([]models.Arbitro, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	arbitros := []models.Arbitro{}
+	for _, arbitro := range valores(r.arbitros) {
+		if slices.Contains(ids, arbitro.ID) {
+			arbitros = append(arbitros, arbitro)
+		}
+	}
+	return arbitros, nil
+}
+
+func (r *arbitroMemoria) Crear(arbitro *models.Arbitro) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("arbitros", &arbitro.ID)
+	if arbitro.Version == 0 {
+		arbitro.Version = 1
+	}
+	arbitro.CreatedAt = time.Now()
+	arbitro.UpdatedAt = arbitro.CreatedAt
+	r.arbitros[arbitro.ID] = *arbitro
+	return nil
+}
+
+func (r *arbitroMemoria) Actualizar(arbitro *models.Arbitro) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := r.arbitros[arbitro.ID]
+	if !ok || actual.Version != arbitro.Version {
+		return ErrVersionObsoleta
+	}
+	arbitro.Version++
+	arbitro.UpdatedAt = time.Now()
+	r.arbitros[arbitro.ID] = *arbitro
+	return nil
+}
+
+func (r *arbitroMemoria) Eliminar(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.arbitros, id)
+	return nil
+}
+
+func (r *arbitroMemoria) BuscarDesignaciones(filtro FiltroDesignaciones) ([]models.DesignacionArbitro, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	designaciones := []models.DesignacionArbitro{}
+	for _, designacion := range valores(r.designaciones) {
+		if len(filtro.PartidoIDs) > 0 && !slices.Contains(filtro.PartidoIDs, designacion.PartidoID) {
+			continue
+		}
+		if filtro.ArbitroID != 0 && designacion.ArbitroID != filtro.ArbitroID {
+			continue
+		}
+		designaciones = append(designaciones, designacion)
+	}
+	sort.SliceStable(designaciones, func(i, j int) bool {
+		return designaciones[i].PartidoID < designaciones[j].PartidoID
+	})
+	return designaciones, nil
+}
+
+func (r *arbitroMemoria) DesignarPartido(partidoID uint, designaciones []models.DesignacionArbitro) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, designacion := range r.designaciones {
+		if designacion.PartidoID == partidoID {
+			delete(r.designaciones, id)
+		}
+	}
+	for i := range designaciones {
+		designaciones[i].PartidoID = partidoID
+		r.nuevoID("designaciones_arbitros", &designaciones[i].ID)
+		designaciones[i].CreatedAt = time.Now()
+		r.designaciones[designaciones[i].ID] = designaciones[i]
+	}
+	return nil
+}
+
+type calendarioMemoria struct {
+	vistaAlmacen
+}
+
+func (r *calendarioMemoria) ListarJornadas() ([]models.Jornada, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jornadas := valores(r.jornadas)
+	sort.SliceStable(jornadas, func(i, j int) bool { return jornadas[i].Numero < jornadas[j].Numero })
+	return jornadas, nil
+}
+
+func (r *calendarioMemoria) ObtenerJornada(id uint) (models.Jornada, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jornada, ok := r.jornadas[id]
+	if !ok {
+		return jornada, ErrNoEncontrado
+	}
+	return jornada, nil
+}
+
+func (r *calendarioMemoria) ObtenerJornadaPorNumero(numero int) (models.Jornada, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, jornada := range r.jornadas {
+		if jornada.Numero == numero {
+			return jornada, nil
+		}
+	}
+	return models.Jornada{}, ErrNoEncontrado
+}
+
+func (r *calendarioMemoria) CrearJornada(jornada *models.Jornada) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existente := range r.jornadas {
+		if existente.Numero == jornada.Numero {
+			return ErrDuplicado
+		}
+	}
+	r.nuevoID("jornadas", &jornada.ID)
+	if jornada.Version == 0 {
+		jornada.Version = 1
+	}
+	copia := *jornada
+	copia.Partidos = nil
+	r.jornadas[jornada.ID] = copia
+	return nil
+}
+
+func (r *calendarioMemoria) ActualizarJornada(jornada *models.Jornada) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := r.jornadas[jornada.ID]
+	if !ok || actual.Version != jornada.Version {
+		return ErrVersionObsoleta
+	}
+	for _, existente := range r.jornadas {
+		if existente.ID != jornada.ID && existente.Numero == jornada.Numero {
+			return ErrDuplicado
+		}
+	}
+	jornada.Version++
+	copia := *jornada
+	copia.Partidos = nil
+	r.jornadas[jornada.ID] = copia
+	return nil
+}
+
+func (r *calendarioMemoria) EliminarJornada(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jornadas, id)
+	return nil
+}
+
+func (r *calendarioMemoria) ObtenerPartido(id uint) (models.Partido, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	partido, ok := r.partidos[id]
+	if !ok {
+		return partido, ErrNoEncontrado
+	}
+	return partido, nil
+}
+
+func (r *calendarioMemoria) BuscarPartidos(filtro FiltroPartidos) ([]models.Partido, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	partidos := []models.Partido{}
+	for _, partido := range r.partidos {
+		if len(filtro.IDs) > 0 && !slices.Contains(filtro.IDs, partido.ID) {
+			continue
+		}
+		juegaEquipo := partido.EquipoLocalID == filtro.EquipoID || partido.EquipoVisitanteID == filtro.EquipoID
+		if filtro.EquipoID != 0 && !juegaEquipo {
+			continue
+		}
+		juegaRival := partido.EquipoLocalID == filtro.RivalID || partido.EquipoVisitanteID == filtro.RivalID
+		if filtro.EquipoID != 0 && filtro.RivalID != 0 && !juegaRival {
+			continue
+		}
+		if filtro.JornadaID != 0 && partido.JornadaID != filtro.JornadaID {
+			continue
+		}
+		if filtro.Estado != "" && partido.Estado != filtro.Estado {
+			continue
+		}
+		if filtro.Desde != nil && partido.FechaHora.Before(*filtro.Desde) {
+			continue
+		}
+		if filtro.Hasta != nil && !partido.FechaHora.Before(*filtro.Hasta) {
+			continue
+		}
+		partidos = append(partidos, partido)
+	}
+
+	sort.Slice(partidos, func(i, j int) bool {
+		a, b := partidos[i], partidos[j]
+		if filtro.Descendente {
+			a, b = b, a
+		}
+		if !a.FechaHora.Equal(b.FechaHora) {
+			return a.FechaHora.Before(b.FechaHora)
+		}
+		return a.ID < b.ID
+	})
+	if filtro.Limite > 0 && len(partidos) > filtro.Limite {
+		partidos = partidos[:filtro.Limite]
+	}
+	return partidos, nil
+}
+
+func (r *calendarioMemoria) CrearPartido(partido *models.Partido) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("partidos", &partido.ID)
+	partido.CreatedAt = time.Now()
+	partido.UpdatedAt = partido.CreatedAt
+	copia := *partido
+	copia.Incidencias = nil
+	r.partidos[partido.ID] = copia
+	return nil
+}
+
+func (r *calendarioMemoria) GuardarPartido(partido *models.Partido) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actual, ok := r.partidos[partido.ID]
+	if !ok || actual.Secuencia != partido.Secuencia {
+		return ErrVersionObsoleta
+	}
+	partido.Secuencia++
+	partido.UpdatedAt = time.Now()
+	copia := *partido
+	copia.Incidencias = nil
+	r.partidos[partido.ID] = copia
+	return nil
+}
+
+func (r *calendarioMemoria) EliminarPartidosDeJornada(jornadaID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, partido := range r.partidos {
+		if partido.JornadaID == jornadaID {
+			delete(r.partidos, id)
+		}
+	}
+	for id, designacion := range r.designaciones {
+		if _, ok := r.partidos[designacion.PartidoID]; !ok {
+			delete(r.designaciones, id)
+		}
+	}
+	return nil
+}
+
+func (r *calendarioMemoria) BuscarIncidencias(filtro FiltroIncidencias) ([]models.Incidencia, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	incidencias := []models.Incidencia{}
+	for _, incidencia := range r.incidencias {
+		if len(filtro.PartidoIDs) > 0 && !slices.Contains(filtro.PartidoIDs, incidencia.PartidoID) {
+			continue
+		}
+		if filtro.JugadorID != 0 && incidencia.JugadorID != filtro.JugadorID {
+			continue
+		}
+		if filtro.Participante != 0 && incidencia.JugadorID != filtro.Participante &&
+			(incidencia.JugadorEntraID == nil || *incidencia.JugadorEntraID != filtro.Participante) {
+			continue
+		}
+		if len(filtro.Tipos) > 0 && !slices.Contains(filtro.Tipos, incidencia.Tipo) {
+			continue
+		}
+		if filtro.SecuenciaMayorA > 0 && incidencia.Secuencia <= filtro.SecuenciaMayorA {
+			continue
+		}
+		if filtro.ConJugador {
+			incidencia.Jugador = r.jugadores[incidencia.JugadorID]
+		}
+		incidencias = append(incidencias, incidencia)
+	}
+
+	sort.Slice(incidencias, func(i, j int) bool {
+		a, b := incidencias[i], incidencias[j]
+		if a.PartidoID != b.PartidoID {
+			return a.PartidoID < b.PartidoID
+		}
+		if a.Secuencia != b.Secuencia {
+			return a.Secuencia < b.Secuencia
+		}
+		return a.ID < b.ID
+	})
+	return incidencias, nil
+}
+
+func (r *calendarioMemoria) CrearIncidencia(incidencia *models.Incidencia) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("incidencias", &incidencia.ID)
+	copia := *incidencia
+	copia.Jugador = models.Jugador{}
+	r.incidencias[incidencia.ID] = copia
+	return nil
+}
+
+func (r *calendarioMemoria) BuscarReprogramaciones(partidoIDs []uint) ([]models.Reprogramacion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reprogramaciones := []models.Reprogramacion{}
+	for _, reprogramacion := range valores(r.reprogramaciones) {
+		if slices.Contains(partidoIDs, reprogramacion.PartidoID) {
+			reprogramaciones = append(reprogramaciones, reprogramacion)
+		}
+	}
+	return reprogramaciones, nil
+}
+
+func (r *calendarioMemoria) CrearReprogramacion(reprogramacion *models.Reprogramacion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nuevoID("reprogramaciones", &reprogramacion.ID)
+	reprogramacion.CreatedAt = time.Now()
+	r.reprogramaciones[reprogramacion.ID] = *reprogramacion
+	return nil
+}
+
+// Transaccion ejecuta las transacciones de una en una y, si fn falla, restaura
+// todos los registros que había antes de empezar
+func (r *calendarioMemoria) Transaccion(fn func(repo CalendarioRepositorio) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	restaurar := r.instantanea()
+	if err := fn(&calendarioMemoria{vistaAlmacen{r.almacen, sinCandado{}}}); err != nil {
+		restaurar()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repositorios
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+func TestTransaccionEnMemoriaDeshaceLosCambios(t *testing.T) {
+	repos := NewEnMemoria()
+	errFallo := errors.New("fallo")
+
+	err := repos.Transaccion(func(tx Repositorios) error {
+		if err := tx.Equipos.Crear(&models.Equipo{Nombre: "Águilas"}); err != nil {
+			return err
+		}
+		return tx.Calendario.Transaccion(func(calendario CalendarioRepositorio) error {
+			if err := calendario.CrearJornada(&models.Jornada{Numero: 1}); err != nil {
+				return err
+			}
+			return errFallo
+		})
+	})
+	if !errors.Is(err, errFallo) {
+		t.Fatalf("error = %v; se esperaba el de la transacción", err)
+	}
+
+	equipos, _ := repos.Equipos.Listar()
+	jornadas, _ := repos.Calendario.ListarJornadas()
+	if len(equipos) != 0 || len(jornadas) != 0 {
+		t.Errorf("quedaron %d equipos y %d jornadas; se esperaba que no quedara nada", len(equipos), len(jornadas))
+	}
+}
+
+func TestTransaccionEnMemoriaNoPierdeEscriturasConcurrentes(t *testing.T) {
+	repos := NewEnMemoria()
+	errFallo := errors.New("fallo")
+	const escrituras = 50
+
+	// Mientras unas transacciones fallan y restauran los datos, otras operaciones crean equipos
+	// fuera de ellas; ninguno de esos equipos se debe perder
+	var wg sync.WaitGroup
+	for i := 0; i < escrituras; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			repos.Transaccion(func(tx Repositorios) error {
+				tx.Jugadores.Crear(&models.Jugador{Nombre: "Temporal"})
+				return errFallo
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			if err := repos.Equipos.Crear(&models.Equipo{Nombre: "Equipo"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	equipos, _ := repos.Equipos.Listar()
+	jugadores, _ := repos.Jugadores.Listar()
+	if len(equipos) != escrituras || len(jugadores) != 0 {
+		t.Errorf("hay %d equipos y %d jugadores; se esperaban %d y 0", len(equipos), len(jugadores), escrituras)
+	}
+}
@@ -0,0 +1,149 @@
+// Package repositorios define el acceso a datos de cada agregado del torneo. Los servicios
+// dependen de estas interfaces, que tienen una implementación con GORM para la aplicación
+// y otra en memoria para probar la lógica sin base de datos.
+package repositorios
+
+import (
+	"errors"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoEncontrado indica que el registro buscado no existe. Es el mismo error de GORM
+	// para que quienes ya comparan con gorm.ErrRecordNotFound sigan funcionando.
+	ErrNoEncontrado = gorm.ErrRecordNotFound
+	// ErrDuplicado indica que ya existe un registro con la misma clave única
+	ErrDuplicado = gorm.ErrDuplicatedKey
+	// ErrVersionObsoleta indica que el registro cambió desde la versión que conocía quien lo modifica
+	ErrVersionObsoleta = errors.New("el registro fue modificado por otro usuario")
+)
+
+// EquipoRepositorio da acceso a los equipos
+type EquipoRepositorio interface {
+	Listar() ([]models.Equipo, error)
+	Obtener(id uint) (models.Equipo, error)
+	ObtenerVarios(ids []uint) ([]models.Equipo, error)
+	Crear(equipo *models.Equipo) error
+	// Actualizar guarda el equipo si su versión no cambió e incrementa la versión
+	Actualizar(equipo *models.Equipo) error
+	Eliminar(id uint) error
+}
+
+// JugadorRepositorio da acceso a los jugadores
+type JugadorRepositorio interface {
+	Listar() ([]models.Jugador, error)
+	ListarPorEquipo(equipoID uint) ([]models.Jugador, error)
+	Obtener(id uint) (models.Jugador, error)
+	Crear(jugador *models.Jugador) error
+	// Actualizar guarda el jugador si su versión no cambió e incrementa la versión
+	Actualizar(jugador *models.Jugador) error
+	Eliminar(id uint) error
+}
+
+// EstadioRepositorio da acceso a los estadios
+type EstadioRepositorio interface {
+	Listar() ([]models.Estadio, error)
+	Obtener(id uint) (models.Estadio, error)
+	ObtenerVarios(ids []uint) ([]models.Estadio, error)
+	Crear(estadio *models.Estadio) error
+	// Actualizar guarda el estadio si su versión no cambió e incrementa la versión
+	Actualizar(estadio *models.Estadio) error
+	Eliminar(id uint) error
+}
+
+// FiltroDesignaciones selecciona designaciones arbitrales; los campos vacíos no filtran.
+// Las designaciones se devuelven ordenadas por partido y en el orden en que se registraron.
+type FiltroDesignaciones struct {
+	PartidoIDs []uint
+	ArbitroID  uint
+}
+
+// ArbitroRepositorio da acceso a los árbitros y a sus designaciones para los partidos
+type ArbitroRepositorio interface {
+	Listar() ([]models.Arbitro, error)
+	Obtener(id uint) (models.Arbitro, error)
+	ObtenerVarios(ids []uint) ([]models.Arbitro, error)
+	Crear(arbitro *models.Arbitro) error
+	// Actualizar guarda el árbitro si su versión no cambió e incrementa la versión
+	Actualizar(arbitro *models.Arbitro) error
+	Eliminar(id uint) error
+
+	BuscarDesignaciones(filtro FiltroDesignaciones) ([]models.DesignacionArbitro, error)
+	// DesignarPartido reemplaza de forma atómica todas las designaciones de un partido
+	DesignarPartido(partidoID uint, designaciones []models.DesignacionArbitro) error
+}
+
+// FiltroPartidos selecciona partidos; los campos vacíos no filtran. Los partidos se
+// devuelven ordenados por fecha y hora, y por ID a igual fecha.
+type FiltroPartidos struct {
+	IDs         []uint
+	EquipoID    uint // El equipo juega como local o como visitante
+	RivalID     uint // Junto con EquipoID, solo los partidos entre ambos equipos
+	JornadaID   uint
+	Estado      string
+	Desde       *time.Time // Partidos desde esta fecha y hora, inclusive
+	Hasta       *time.Time // Partidos antes de esta fecha y hora, sin incluirla
+	Descendente bool       // Del más reciente al más antiguo
+	Limite      int
+}
+
+// FiltroIncidencias selecciona incidencias; los campos vacíos no filtran. Las incidencias
+// se devuelven ordenadas por partido y por secuencia de registro.
+type FiltroIncidencias struct {
+	PartidoIDs      []uint
+	JugadorID       uint
+	Participante    uint // Incidencias del jugador o sustituciones en las que entra al campo
+	Tipos           []models.TipoIncidencia
+	SecuenciaMayorA uint
+	ConJugador      bool // Incluir los datos del jugador
+}
+
+// CalendarioRepositorio da acceso a las jornadas, los partidos y sus incidencias
+type CalendarioRepositorio interface {
+	ListarJornadas() ([]models.Jornada, error)
+	ObtenerJornada(id uint) (models.Jornada, error)
+	ObtenerJornadaPorNumero(numero int) (models.Jornada, error)
+	CrearJornada(jornada *models.Jornada) error
+	// ActualizarJornada guarda la jornada si su versión no cambió e incrementa la versión
+	ActualizarJornada(jornada *models.Jornada) error
+	// EliminarJornada borra una jornada que ya no tiene partidos
+	EliminarJornada(id uint) error
+
+	ObtenerPartido(id uint) (models.Partido, error)
+	BuscarPartidos(filtro FiltroPartidos) ([]models.Partido, error)
+	CrearPartido(partido *models.Partido) error
+	// GuardarPartido guarda el partido si su secuencia no cambió y avanza la secuencia
+	GuardarPartido(partido *models.Partido) error
+	// EliminarPartidosDeJornada borra definitivamente los partidos de una jornada y sus designaciones arbitrales
+	EliminarPartidosDeJornada(jornadaID uint) error
+
+	BuscarIncidencias(filtro FiltroIncidencias) ([]models.Incidencia, error)
+	CrearIncidencia(incidencia *models.Incidencia) error
+
+	// BuscarReprogramaciones devuelve el historial de los partidos indicados en orden de registro
+	BuscarReprogramaciones(partidoIDs []uint) ([]models.Reprogramacion, error)
+	CrearReprogramacion(reprogramacion *models.Reprogramacion) error
+
+	// Transaccion ejecuta fn de forma atómica: si devuelve un error no se guarda ningún cambio
+	Transaccion(fn func(repo CalendarioRepositorio) error) error
+}
+
+// Repositorios agrupa los repositorios de todos los agregados sobre un mismo almacenamiento
+type Repositorios struct {
+	Equipos    EquipoRepositorio
+	Jugadores  JugadorRepositorio
+	Estadios   EstadioRepositorio
+	Arbitros   ArbitroRepositorio
+	Calendario CalendarioRepositorio
+
+	transaccion func(fn func(repos Repositorios) error) error
+}
+
+// Transaccion ejecuta fn de forma atómica sobre todos los repositorios: si devuelve un error
+// no se guarda ningún cambio. Dentro de fn no se debe abrir otra transacción del calendario.
+func (r Repositorios) Transaccion(fn func(repos Repositorios) error) error {
+	return r.transaccion(fn)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/database"
+	"github.com/noisk8/torneas/backend/models"
+	"gorm.io/gorm"
+)
+
+const usoRol = `Uso: go run . rol <email> <admin|editor|lector>
+
+Cambia el rol de un usuario registrado. Quien se registra por la API recibe el rol lector;
+este comando sirve para nombrar al primer administrador, que después puede dar roles con
+PUT /api/auth/usuarios/:id/rol.`
+
+// ejecutarRol atiende el subcomando rol y devuelve el código de salida del proceso
+func ejecutarRol(cfg *config.Config, args []string) int {
+	if len(args) != 2 || !slices.Contains(models.Roles, args[1]) {
+		fmt.Fprintln(os.Stderr, usoRol)
+		return 2
+	}
+	email, rol := args[0], args[1]
+
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var usuario models.Usuario
+	if err := db.Where("email = ?", email).First(&usuario).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Fprintf(os.Stderr, "No hay un usuario registrado con el correo %s\n", email)
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+	if err := db.Model(&usuario).Update("rol", rol).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "Error al actualizar el usuario: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("%s ahora tiene el rol %s\n", email, rol)
+	return 0
+}
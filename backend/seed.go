@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/database"
+	"github.com/noisk8/torneas/backend/services"
+)
+
+const usoSeed = `Uso: go run . seed [equipos|demo] [--reset]
+
+Conjuntos de datos:
+  equipos   Los equipos del torneo y sus estadios (por defecto)
+  demo      Temporada de demostración: estadios, equipos, plantillas de 22 jugadores,
+            calendario y resultados simulados, con su público, de las jornadas que ya pasaron
+
+Los registros se identifican por su nombre, así que sembrar varias veces no duplica datos.
+
+Opciones:
+  --reset   Borra todos los datos del torneo antes de sembrar. Solo se permite si APP_ENV
+            está definida como development o test`
+
+// ejecutarSeed atiende el subcomando seed y devuelve el código de salida del proceso
+func ejecutarSeed(cfg *config.Config, args []string) int {
+	conjunto := services.SemillaEquipos
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		conjunto, args = args[0], args[1:]
+	}
+
+	opciones := flag.NewFlagSet("seed", flag.ContinueOnError)
+	opciones.SetOutput(io.Discard)
+	reset := opciones.Bool("reset", false, "")
+	if err := opciones.Parse(args); err != nil || opciones.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, usoSeed)
+		return 2
+	}
+	if !slices.Contains(services.ConjuntosSemilla, conjunto) {
+		fmt.Fprintln(os.Stderr, usoSeed)
+		return 2
+	}
+	if *reset && !cfg.PermiteVaciarDatos() {
+		fmt.Fprintln(os.Stderr, "--reset solo se permite con APP_ENV=development o APP_ENV=test definida de forma explícita")
+		return 1
+	}
+
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *reset {
+		if err := database.VaciarDatos(db); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println("Datos del torneo borrados")
+	}
+
+	service := services.NewSemillaService(db)
+	resultado, err := service.Sembrar(conjunto, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al sembrar los datos: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Estadios: %d nuevos, %d actualizados\n", resultado.EstadiosCreados, resultado.EstadiosActualizados)
+	fmt.Printf("Equipos: %d nuevos, %d actualizados\n", resultado.EquiposCreados, resultado.EquiposActualizados)
+	if conjunto == services.SemillaDemo {
+		fmt.Printf("Jugadores: %d nuevos, %d actualizados\n", resultado.JugadoresCreados, resultado.JugadoresActualizados)
+		fmt.Printf("Jornadas creadas: %d\n", resultado.JornadasCreadas)
+		fmt.Printf("Resultados simulados: %d\n", resultado.ResultadosSimulados)
+	}
+	return 0
+}
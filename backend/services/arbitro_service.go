@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"gorm.io/gorm"
+)
+
+// ErrArbitroConDesignaciones indica que el árbitro está designado en algún partido y no se puede eliminar
+var ErrArbitroConDesignaciones = errors.New("el árbitro está designado en algún partido")
+
+// Reglas por las que se rechaza la designación de un árbitro
+const (
+	ReglaArbitroInexistente    = "inexistente"     // El árbitro no existe
+	ReglaArbitroRepetido       = "repetido"        // El árbitro ya tiene otro rol en el mismo partido
+	ReglaArbitroJornadaOcupada = "jornada_ocupada" // El árbitro ya está designado en otro partido de la jornada
+	ReglaArbitroMismaCiudad    = "misma_ciudad"    // Uno de los equipos es de la ciudad del árbitro
+)
+
+// ErrDesignacionArbitro indica que un árbitro no puede ser designado con un rol en un partido
+type ErrDesignacionArbitro struct {
+	Rol       string
+	ArbitroID uint
+	Regla     string
+	PartidoID uint // Con jornada_ocupada, el otro partido de la jornada
+	EquipoID  uint // Con misma_ciudad, el equipo de la ciudad del árbitro
+}
+
+func (e *ErrDesignacionArbitro) Error() string {
+	return "el árbitro " + e.Rol + " no se puede designar: " + e.Regla
+}
+
+// ArbitroService proporciona métodos para interactuar con los árbitros y sus designaciones
+type ArbitroService struct {
+	repositorios.Repositorios
+	RestringirCiudad bool // Rechazar árbitros de la misma ciudad que alguno de los equipos
+}
+
+// NewArbitroService crea una nueva instancia del servicio de árbitros
+func NewArbitroService(db *gorm.DB) *ArbitroService {
+	return NewArbitroServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewArbitroServiceConRepositorios crea el servicio de árbitros sobre los repositorios indicados
+func NewArbitroServiceConRepositorios(repos repositorios.Repositorios) *ArbitroService {
+	return &ArbitroService{
+		Repositorios: repos,
+	}
+}
+
+// GetArbitros obtiene todos los árbitros
+func (s *ArbitroService) GetArbitros() ([]models.Arbitro, error) {
+	return s.Arbitros.Listar()
+}
+
+// GetArbitro obtiene un árbitro por su ID
+func (s *ArbitroService) GetArbitro(id uint) (models.Arbitro, error) {
+	return s.Arbitros.Obtener(id)
+}
+
+// CrearArbitro crea un nuevo árbitro
+func (s *ArbitroService) CrearArbitro(arbitro models.Arbitro) (models.Arbitro, error) {
+	err := s.Arbitros.Crear(&arbitro)
+	return arbitro, err
+}
+
+// ActualizarArbitro guarda los cambios de un árbitro si su versión sigue siendo la que
+// conocía quien lo modifica; en caso contrario devuelve ErrVersionObsoleta
+func (s *ArbitroService) ActualizarArbitro(arbitro models.Arbitro) (models.Arbitro, error) {
+	err := s.Arbitros.Actualizar(&arbitro)
+	return arbitro, err
+}
+
+// EliminarArbitro elimina un árbitro que no está designado en ningún partido
+func (s *ArbitroService) EliminarArbitro(id uint) error {
+	if _, err := s.Arbitros.Obtener(id); err != nil {
+		return err
+	}
+
+	designaciones, err := s.Arbitros.BuscarDesignaciones(repositorios.FiltroDesignaciones{ArbitroID: id})
+	if err != nil {
+		return err
+	}
+	if len(designaciones) > 0 {
+		return ErrArbitroConDesignaciones
+	}
+
+	return s.Arbitros.Eliminar(id)
+}
+
+// ArbitroDesignado es un árbitro con el rol para el que fue designado en un partido
+type ArbitroDesignado struct {
+	Rol     string         `json:"rol"`
+	Arbitro models.Arbitro `json:"arbitro"`
+}
+
+// GetDesignacion obtiene los árbitros designados para un partido en el orden de los roles
+func (s *ArbitroService) GetDesignacion(partidoID uint) ([]ArbitroDesignado, error) {
+	if _, err := s.Calendario.ObtenerPartido(partidoID); err != nil {
+		return nil, err
+	}
+
+	designaciones, err := s.Arbitros.BuscarDesignaciones(repositorios.FiltroDesignaciones{PartidoIDs: []uint{partidoID}})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(designaciones))
+	roles := make(map[string]uint, len(designaciones))
+	for _, designacion := range designaciones {
+		ids = append(ids, designacion.ArbitroID)
+		roles[designacion.Rol] = designacion.ArbitroID
+	}
+	arbitrosLista, err := s.Arbitros.ObtenerVarios(ids)
+	if err != nil {
+		return nil, err
+	}
+	arbitros := make(map[uint]models.Arbitro, len(arbitrosLista))
+	for _, arbitro := range arbitrosLista {
+		arbitros[arbitro.ID] = arbitro
+	}
+
+	designados := []ArbitroDesignado{}
+	for _, rol := range models.RolesArbitro {
+		if id, ok := roles[rol]; ok {
+			designados = append(designados, ArbitroDesignado{Rol: rol, Arbitro: arbitros[id]})
+		}
+	}
+	return designados, nil
+}
+
+// DesignarArbitros reemplaza la designación arbitral de un partido por los árbitros indicados
+// para cada rol; los roles que no aparecen quedan sin árbitro. Un árbitro solo puede tener un
+// rol por partido y un partido por jornada y, si RestringirCiudad está activo, no puede dirigir
+// a equipos de su ciudad. Si alguna regla no se cumple devuelve *ErrDesignacionArbitro.
+func (s *ArbitroService) DesignarArbitros(partidoID uint, roles map[string]uint) ([]ArbitroDesignado, error) {
+	// Las designaciones de la jornada se leen y se guardan en la misma transacción para que dos
+	// peticiones simultáneas no puedan asignar el mismo árbitro a dos partidos de la jornada
+	err := s.Transaccion(func(repos repositorios.Repositorios) error {
+		return s.designarArbitros(repos, partidoID, roles)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDesignacion(partidoID)
+}
+
+// designarArbitros comprueba las reglas de designación y guarda los árbitros del partido usando repos
+func (s *ArbitroService) designarArbitros(repos repositorios.Repositorios, partidoID uint, roles map[string]uint) error {
+	partido, err := repos.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return err
+	}
+
+	// Los árbitros de los demás partidos de la jornada
+	otrosPartidos, err := repos.Calendario.BuscarPartidos(repositorios.FiltroPartidos{JornadaID: partido.JornadaID})
+	if err != nil {
+		return err
+	}
+	otrosIDs := make([]uint, 0, len(otrosPartidos))
+	for _, otro := range otrosPartidos {
+		if otro.ID != partido.ID {
+			otrosIDs = append(otrosIDs, otro.ID)
+		}
+	}
+	ocupados := make(map[uint]uint)
+	if len(otrosIDs) > 0 {
+		designaciones, err := repos.Arbitros.BuscarDesignaciones(repositorios.FiltroDesignaciones{PartidoIDs: otrosIDs})
+		if err != nil {
+			return err
+		}
+		for _, designacion := range designaciones {
+			ocupados[designacion.ArbitroID] = designacion.PartidoID
+		}
+	}
+
+	var equipos []models.Equipo
+	if s.RestringirCiudad {
+		if equipos, err = repos.Equipos.ObtenerVarios([]uint{partido.EquipoLocalID, partido.EquipoVisitanteID}); err != nil {
+			return err
+		}
+	}
+
+	var designaciones []models.DesignacionArbitro
+	asignados := make(map[uint]bool)
+	for _, rol := range models.RolesArbitro {
+		arbitroID, ok := roles[rol]
+		if !ok || arbitroID == 0 {
+			continue
+		}
+		rechazo := &ErrDesignacionArbitro{Rol: rol, ArbitroID: arbitroID}
+
+		arbitro, err := repos.Arbitros.Obtener(arbitroID)
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
+			rechazo.Regla = ReglaArbitroInexistente
+			return rechazo
+		}
+		if err != nil {
+			return err
+		}
+		if asignados[arbitroID] {
+			rechazo.Regla = ReglaArbitroRepetido
+			return rechazo
+		}
+		if otroID, ok := ocupados[arbitroID]; ok {
+			rechazo.Regla, rechazo.PartidoID = ReglaArbitroJornadaOcupada, otroID
+			return rechazo
+		}
+		for _, equipo := range equipos {
+			if mismaCiudad(arbitro.Ciudad, equipo.Ciudad) {
+				rechazo.Regla, rechazo.EquipoID = ReglaArbitroMismaCiudad, equipo.ID
+				return rechazo
+			}
+		}
+
+		asignados[arbitroID] = true
+		designaciones = append(designaciones, models.DesignacionArbitro{ArbitroID: arbitroID, Rol: rol})
+	}
+
+	return repos.Arbitros.DesignarPartido(partido.ID, designaciones)
+}
+
+// mismaCiudad compara dos ciudades sin distinguir mayúsculas; una ciudad vacía no coincide con ninguna
+func mismaCiudad(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	return a != "" && strings.EqualFold(a, b)
+}
+
+// EstadisticasArbitro resume los partidos de un árbitro y las decisiones que tomó como árbitro principal
+type EstadisticasArbitro struct {
+	Arbitro             models.Arbitro `json:"arbitro"`
+	Designaciones       map[string]int `json:"designaciones"`     // Partidos designados por rol
+	PartidosDirigidos   int            `json:"partidosDirigidos"` // Partidos finalizados como árbitro principal
+	Amarillas           int            `json:"amarillas"`
+	Rojas               int            `json:"rojas"`
+	Penales             int            `json:"penales"` // Penales sancionados, convertidos o fallados
+	AmarillasPorPartido float64        `json:"amarillasPorPartido"`
+	RojasPorPartido     float64        `json:"rojasPorPartido"`
+	PenalesPorPartido   float64        `json:"penalesPorPartido"`
+}
+
+// GetEstadisticasArbitro calcula las estadísticas de un árbitro a partir de las incidencias de
+// los partidos finalizados en los que fue el árbitro principal
+func (s *ArbitroService) GetEstadisticasArbitro(id uint) (EstadisticasArbitro, error) {
+	estadisticas := EstadisticasArbitro{Designaciones: make(map[string]int)}
+
+	arbitro, err := s.Arbitros.Obtener(id)
+	if err != nil {
+		return estadisticas, err
+	}
+	estadisticas.Arbitro = arbitro
+
+	designaciones, err := s.Arbitros.BuscarDesignaciones(repositorios.FiltroDesignaciones{ArbitroID: id})
+	if err != nil {
+		return estadisticas, err
+	}
+	var dirigidos []uint
+	for _, designacion := range designaciones {
+		estadisticas.Designaciones[designacion.Rol]++
+		if designacion.Rol == models.RolArbitroPrincipal {
+			dirigidos = append(dirigidos, designacion.PartidoID)
+		}
+	}
+	if len(dirigidos) == 0 {
+		return estadisticas, nil
+	}
+
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{IDs: dirigidos, Estado: models.EstadoFinalizado})
+	if err != nil {
+		return estadisticas, err
+	}
+	if len(partidos) == 0 {
+		return estadisticas, nil
+	}
+	finalizados := make([]uint, 0, len(partidos))
+	for _, partido := range partidos {
+		finalizados = append(finalizados, partido.ID)
+	}
+	estadisticas.PartidosDirigidos = len(finalizados)
+
+	incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{
+		PartidoIDs: finalizados,
+		Tipos:      []models.TipoIncidencia{models.TarjetaAmarilla, models.TarjetaRoja, models.GolPenal, models.PenalFallado},
+	})
+	if err != nil {
+		return estadisticas, err
+	}
+	for _, incidencia := range incidencias {
+		switch incidencia.Tipo {
+		case models.TarjetaAmarilla:
+			estadisticas.Amarillas++
+		case models.TarjetaRoja:
+			estadisticas.Rojas++
+		case models.GolPenal, models.PenalFallado:
+			estadisticas.Penales++
+		}
+	}
+
+	estadisticas.AmarillasPorPartido = promedioPorPartido(estadisticas.Amarillas, estadisticas.PartidosDirigidos)
+	estadisticas.RojasPorPartido = promedioPorPartido(estadisticas.Rojas, estadisticas.PartidosDirigidos)
+	estadisticas.PenalesPorPartido = promedioPorPartido(estadisticas.Penales, estadisticas.PartidosDirigidos)
+	return estadisticas, nil
+}
+
+// promedioPorPartido divide un total entre los partidos, redondeado a dos decimales
+func promedioPorPartido(total, partidos int) float64 {
+	return math.Round(float64(total)/float64(partidos)*100) / 100
+}
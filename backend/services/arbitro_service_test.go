@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+func TestDesignarArbitrosSegunLasReglas(t *testing.T) {
+	repos := repositorios.NewEnMemoria()
+	aguilas := models.Equipo{Nombre: "Águilas", Ciudad: "Bogotá"}
+	buhos := models.Equipo{Nombre: "Búhos", Ciudad: "Cali"}
+	condores := models.Equipo{Nombre: "Cóndores", Ciudad: "Medellín"}
+	delfines := models.Equipo{Nombre: "Delfines", Ciudad: "Barranquilla"}
+	for _, equipo := range []*models.Equipo{&aguilas, &buhos, &condores, &delfines} {
+		if err := repos.Equipos.Crear(equipo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ocupado := models.Arbitro{Nombre: "Wilmar Roldán", Ciudad: "Medellín"}
+	bogotano := models.Arbitro{Nombre: "Andrés Rojas", Ciudad: " bogotá "}
+	libre := models.Arbitro{Nombre: "Carlos Ortega", Ciudad: "Pereira"}
+	for _, arbitro := range []*models.Arbitro{&ocupado, &bogotano, &libre} {
+		if err := repos.Arbitros.Crear(arbitro); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jornada := models.Jornada{Numero: 1}
+	if err := repos.Calendario.CrearJornada(&jornada); err != nil {
+		t.Fatal(err)
+	}
+	partido := models.Partido{JornadaID: jornada.ID, EquipoLocalID: aguilas.ID, EquipoVisitanteID: buhos.ID, FechaHora: time.Date(2024, 8, 3, 20, 0, 0, 0, time.UTC), Estado: models.EstadoPendiente}
+	otro := models.Partido{JornadaID: jornada.ID, EquipoLocalID: condores.ID, EquipoVisitanteID: delfines.ID, FechaHora: time.Date(2024, 8, 4, 20, 0, 0, 0, time.UTC), Estado: models.EstadoPendiente}
+	for _, p := range []*models.Partido{&partido, &otro} {
+		if err := repos.Calendario.CrearPartido(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repos.Arbitros.DesignarPartido(otro.ID, []models.DesignacionArbitro{{ArbitroID: ocupado.ID, Rol: models.RolArbitroPrincipal}}); err != nil {
+		t.Fatal(err)
+	}
+
+	casos := []struct {
+		nombre           string
+		restringirCiudad bool
+		roles            map[string]uint
+		regla            string // vacía si la designación es válida
+	}{
+		{"valida", true, map[string]uint{models.RolArbitroPrincipal: libre.ID}, ""},
+		{"jornada ocupada", false, map[string]uint{models.RolArbitroPrincipal: ocupado.ID}, ReglaArbitroJornadaOcupada},
+		{"misma ciudad", true, map[string]uint{models.RolArbitroPrincipal: libre.ID, models.RolArbitroVAR: bogotano.ID}, ReglaArbitroMismaCiudad},
+		{"misma ciudad sin restriccion", false, map[string]uint{models.RolArbitroPrincipal: bogotano.ID}, ""},
+		{"repetido", false, map[string]uint{models.RolArbitroPrincipal: libre.ID, models.RolArbitroAsistente1: libre.ID}, ReglaArbitroRepetido},
+		{"inexistente", false, map[string]uint{models.RolArbitroPrincipal: 999}, ReglaArbitroInexistente},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			// Cada caso parte de un partido sin árbitros
+			if err := repos.Arbitros.DesignarPartido(partido.ID, nil); err != nil {
+				t.Fatal(err)
+			}
+			service := NewArbitroServiceConRepositorios(repos)
+			service.RestringirCiudad = caso.restringirCiudad
+
+			designados, err := service.DesignarArbitros(partido.ID, caso.roles)
+			guardadas, errBuscar := repos.Arbitros.BuscarDesignaciones(repositorios.FiltroDesignaciones{PartidoIDs: []uint{partido.ID}})
+			if errBuscar != nil {
+				t.Fatal(errBuscar)
+			}
+			if caso.regla == "" {
+				if err != nil {
+					t.Fatalf("error = %v; se esperaba una designación válida", err)
+				}
+				if len(designados) != len(caso.roles) || len(guardadas) != len(caso.roles) {
+					t.Errorf("%d árbitros designados y %d guardados; se esperaban %d", len(designados), len(guardadas), len(caso.roles))
+				}
+				return
+			}
+			var rechazo *ErrDesignacionArbitro
+			if !errors.As(err, &rechazo) || rechazo.Regla != caso.regla {
+				t.Fatalf("error = %v; se esperaba la regla %s", err, caso.regla)
+			}
+			if len(guardadas) != 0 {
+				t.Errorf("se guardaron %d designaciones de una designación rechazada", len(guardadas))
+			}
+		})
+	}
+}
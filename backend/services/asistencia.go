@@ -0,0 +1,141 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+// ResumenAsistencia acumula el público de un grupo de partidos con la asistencia registrada
+type ResumenAsistencia struct {
+	Partidos int     `json:"partidos"`
+	Total    int     `json:"total"`
+	Promedio float64 `json:"promedio"`
+	Maxima   int     `json:"maxima"`
+	Minima   int     `json:"minima"`
+}
+
+// agregar suma la asistencia de un partido y recalcula el promedio
+func (r *ResumenAsistencia) agregar(espectadores int) {
+	if r.Partidos == 0 || espectadores < r.Minima {
+		r.Minima = espectadores
+	}
+	if espectadores > r.Maxima {
+		r.Maxima = espectadores
+	}
+	r.Partidos++
+	r.Total += espectadores
+	r.Promedio = math.Round(float64(r.Total)/float64(r.Partidos)*100) / 100
+}
+
+// AsistenciaEquipo resume el público en los partidos de un equipo como local y como visitante
+type AsistenciaEquipo struct {
+	EquipoID  uint              `json:"equipoId"`
+	Equipo    string            `json:"equipo"`
+	Local     ResumenAsistencia `json:"local"`
+	Visitante ResumenAsistencia `json:"visitante"`
+}
+
+// AsistenciaEstadio resume el público de los partidos jugados en un estadio
+type AsistenciaEstadio struct {
+	EstadioID uint   `json:"estadioId"`
+	Estadio   string `json:"estadio"`
+	Capacidad int    `json:"capacidad"`
+	ResumenAsistencia
+	Ocupacion float64 `json:"ocupacion"` // Promedio de público sobre la capacidad, en porcentaje
+}
+
+// partidosConAsistencia obtiene los partidos con el público registrado
+func (s *CalendarioService) partidosConAsistencia() ([]models.Partido, error) {
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		return nil, err
+	}
+	conAsistencia := partidos[:0]
+	for _, partido := range partidos {
+		if partido.Espectadores != nil {
+			conAsistencia = append(conAsistencia, partido)
+		}
+	}
+	return conAsistencia, nil
+}
+
+// GetAsistenciaEquipos calcula la asistencia de cada equipo como local y como visitante,
+// ordenados de mayor a menor promedio como local
+func (s *CalendarioService) GetAsistenciaEquipos() ([]AsistenciaEquipo, error) {
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	partidos, err := s.partidosConAsistencia()
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make(map[uint]int, len(equipos))
+	asistencia := make([]AsistenciaEquipo, len(equipos))
+	for i, equipo := range equipos {
+		indices[equipo.ID] = i
+		asistencia[i] = AsistenciaEquipo{EquipoID: equipo.ID, Equipo: equipo.Nombre}
+	}
+	for _, partido := range partidos {
+		if i, ok := indices[partido.EquipoLocalID]; ok {
+			asistencia[i].Local.agregar(*partido.Espectadores)
+		}
+		if i, ok := indices[partido.EquipoVisitanteID]; ok {
+			asistencia[i].Visitante.agregar(*partido.Espectadores)
+		}
+	}
+
+	sort.SliceStable(asistencia, func(i, j int) bool {
+		return asistencia[i].Local.Promedio > asistencia[j].Local.Promedio
+	})
+	return asistencia, nil
+}
+
+// GetAsistenciaEstadios calcula la asistencia de cada estadio, ordenados de mayor a menor
+// promedio. Un partido cuenta para el estadio asignado al partido o, si no tiene, para el del
+// equipo local.
+func (s *CalendarioService) GetAsistenciaEstadios() ([]AsistenciaEstadio, error) {
+	estadios, err := s.Estadios.Listar()
+	if err != nil {
+		return nil, err
+	}
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	partidos, err := s.partidosConAsistencia()
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make(map[uint]int, len(estadios))
+	asistencia := make([]AsistenciaEstadio, len(estadios))
+	for i, estadio := range estadios {
+		indices[estadio.ID] = i
+		asistencia[i] = AsistenciaEstadio{EstadioID: estadio.ID, Estadio: estadio.Nombre, Capacidad: estadio.Capacidad}
+	}
+	sedes := sedesEquipos(equipos)
+	for _, partido := range partidos {
+		sede := estadioDePartido(partido, sedes)
+		if sede == nil {
+			continue
+		}
+		if i, ok := indices[*sede]; ok {
+			asistencia[i].agregar(*partido.Espectadores)
+		}
+	}
+
+	for i := range asistencia {
+		if asistencia[i].Capacidad > 0 {
+			asistencia[i].Ocupacion = math.Round(asistencia[i].Promedio/float64(asistencia[i].Capacidad)*10000) / 100
+		}
+	}
+	sort.SliceStable(asistencia, func(i, j int) bool {
+		return asistencia[i].Promedio > asistencia[j].Promedio
+	})
+	return asistencia, nil
+}
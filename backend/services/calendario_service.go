@@ -2,42 +2,45 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
-	"github.com/noisk8/torneas/backend/database"
 	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
 	"gorm.io/gorm"
 )
 
 // CalendarioService proporciona métodos para interactuar con las jornadas y partidos
 type CalendarioService struct {
-	DB *gorm.DB
+	repositorios.Repositorios
+	ZonaHoraria *time.Location // Zona de la liga en la que se cuenta el día de un partido; nil usa la de cada fecha
 }
 
 // NewCalendarioService crea una nueva instancia del servicio de calendario
-func NewCalendarioService() *CalendarioService {
+func NewCalendarioService(db *gorm.DB) *CalendarioService {
+	return NewCalendarioServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewCalendarioServiceConRepositorios crea el servicio de calendario sobre los repositorios indicados
+func NewCalendarioServiceConRepositorios(repos repositorios.Repositorios) *CalendarioService {
 	return &CalendarioService{
-		DB: database.GetDB(),
+		Repositorios: repos,
 	}
 }
 
 // GetCalendarioCompleto obtiene todas las jornadas con sus partidos
 func (s *CalendarioService) GetCalendarioCompleto() ([]models.Jornada, error) {
-	var jornadas []models.Jornada
-	
 	// Obtener todas las jornadas ordenadas por número
-	result := s.DB.Order("numero").Find(&jornadas)
-	if result.Error != nil {
-		return nil, result.Error
+	jornadas, err := s.Calendario.ListarJornadas()
+	if err != nil {
+		return nil, err
 	}
 	
 	// Para cada jornada, obtener sus partidos
 	for i := range jornadas {
-		var partidos []models.Partido
-		if err := s.DB.Where("jornada_id = ?", jornadas[i].ID).
-			Preload("EquipoLocal").
-			Preload("EquipoVisitante").
-			Find(&partidos).Error; err != nil {
+		partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{JornadaID: jornadas[i].ID})
+		if err != nil {
 			return nil, err
 		}
 		jornadas[i].Partidos = partidos
@@ -48,23 +51,18 @@ func (s *CalendarioService) GetCalendarioCompleto() ([]models.Jornada, error) {
 
 // GetJornadaByNumero obtiene una jornada específica con sus partidos
 func (s *CalendarioService) GetJornadaByNumero(numero int) (models.Jornada, error) {
-	var jornada models.Jornada
-	
 	// Obtener la jornada por su número
-	result := s.DB.Where("numero = ?", numero).First(&jornada)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	jornada, err := s.Calendario.ObtenerJornadaPorNumero(numero)
+	if err != nil {
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
 			return jornada, errors.New("jornada no encontrada")
 		}
-		return jornada, result.Error
+		return jornada, err
 	}
 	
 	// Obtener los partidos de la jornada
-	var partidos []models.Partido
-	if err := s.DB.Where("jornada_id = ?", jornada.ID).
-		Preload("EquipoLocal").
-		Preload("EquipoVisitante").
-		Find(&partidos).Error; err != nil {
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{JornadaID: jornada.ID})
+	if err != nil {
 		return jornada, err
 	}
 	jornada.Partidos = partidos
@@ -72,31 +70,30 @@ func (s *CalendarioService) GetJornadaByNumero(numero int) (models.Jornada, erro
 	return jornada, nil
 }
 
-// GetPartidoByID obtiene un partido específico con sus equipos e incidencias
+// GetPartidoByID obtiene un partido específico con sus incidencias ordenadas por minuto
 func (s *CalendarioService) GetPartidoByID(id uint) (models.Partido, error) {
-	var partido models.Partido
-	
-	// Obtener el partido con sus equipos
-	result := s.DB.
-		Preload("EquipoLocal").
-		Preload("EquipoVisitante").
-		First(&partido, id)
-	
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return partido, errors.New("partido no encontrado")
+	partido, err := s.Calendario.ObtenerPartido(id)
+	if err != nil {
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
+			return partido, fmt.Errorf("partido no encontrado: %w", err)
 		}
-		return partido, result.Error
+		return partido, err
 	}
 	
 	// Obtener las incidencias del partido
-	var incidencias []models.Incidencia
-	if err := s.DB.Where("partido_id = ?", partido.ID).
-		Preload("Jugador").
-		Order("minuto").
-		Find(&incidencias).Error; err != nil {
+	incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{
+		PartidoIDs: []uint{partido.ID},
+		ConJugador: true,
+	})
+	if err != nil {
 		return partido, err
 	}
+	sort.SliceStable(incidencias, func(i, j int) bool {
+		if incidencias[i].Minuto != incidencias[j].Minuto {
+			return incidencias[i].Minuto < incidencias[j].Minuto
+		}
+		return incidencias[i].MinutoAnadido < incidencias[j].MinutoAnadido
+	})
 	partido.Incidencias = incidencias
 	
 	return partido, nil
@@ -104,149 +101,485 @@ func (s *CalendarioService) GetPartidoByID(id uint) (models.Partido, error) {
 
 // GetPartidosByEquipo obtiene los partidos de un equipo específico
 func (s *CalendarioService) GetPartidosByEquipo(equipoID uint) ([]models.Partido, error) {
-	var partidos []models.Partido
-	
 	// Obtener partidos donde el equipo es local o visitante
-	result := s.DB.
-		Where("equipo_local_id = ? OR equipo_visitante_id = ?", equipoID, equipoID).
-		Preload("EquipoLocal").
-		Preload("EquipoVisitante").
-		Preload("Jornada").
-		Order("fecha, hora").
-		Find(&partidos)
-	
-	return partidos, result.Error
+	return s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{EquipoID: equipoID})
 }
 
 // GetProximosPartidos obtiene los próximos N partidos a partir de la fecha actual
 func (s *CalendarioService) GetProximosPartidos(cantidad int) ([]models.Partido, error) {
-	var partidos []models.Partido
-	
-	// Obtener partidos a partir de la fecha actual
-	result := s.DB.
-		Where("fecha >= ?", time.Now()).
-		Preload("EquipoLocal").
-		Preload("EquipoVisitante").
-		Preload("Jornada").
-		Order("fecha, hora").
-		Limit(cantidad).
-		Find(&partidos)
-	
-	return partidos, result.Error
+	ahora := time.Now()
+	return s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		Desde:  &ahora,
+		Limite: cantidad,
+	})
 }
 
 // GetUltimosResultados obtiene los últimos N partidos jugados
 func (s *CalendarioService) GetUltimosResultados(cantidad int) ([]models.Partido, error) {
-	var partidos []models.Partido
+	return s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		Estado:      models.EstadoFinalizado,
+		Descendente: true,
+		Limite:      cantidad,
+	})
+}
+
+// ActualizarResultadoPartido actualiza el resultado de un partido y lo da por finalizado
+func (s *CalendarioService) ActualizarResultadoPartido(partidoID uint, golesLocal, golesVisitante int) error {
+	// Obtener el partido
+	partido, err := s.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return err
+	}
 	
-	// Obtener partidos finalizados
-	result := s.DB.
-		Where("estado = ?", "Finalizado").
-		Preload("EquipoLocal").
-		Preload("EquipoVisitante").
-		Preload("Jornada").
-		Order("fecha DESC, hora DESC").
-		Limit(cantidad).
-		Find(&partidos)
+	// Actualizar el resultado
+	partido.GolesLocal = golesLocal
+	partido.GolesVisitante = golesVisitante
+	partido.Estado = models.EstadoFinalizado
 	
-	return partidos, result.Error
+	// Guardar cambios
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return err
+	}
+
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoMarcador, partido))
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoEstado, partido))
+	return nil
 }
 
-// GenerarCalendario genera un calendario completo para el torneo
-func (s *CalendarioService) GenerarCalendario() error {
-	return GenerarCalendario(s.DB)
+// ActualizarMarcador actualiza el marcador de un partido sin cambiar su estado.
+// Si se indica la secuencia esperada y el partido ya cambió, devuelve ErrConflictoPartido.
+func (s *CalendarioService) ActualizarMarcador(partidoID uint, secuencia *uint, golesLocal, golesVisitante int) (models.Partido, error) {
+	partido, err := s.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return partido, err
+	}
+
+	if secuencia != nil && partido.Secuencia != *secuencia {
+		return partido, ErrConflictoPartido
+	}
+
+	partido.GolesLocal = golesLocal
+	partido.GolesVisitante = golesVisitante
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return partido, err
+	}
+
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoMarcador, partido))
+	return partido, nil
 }
 
-func GenerarCalendario(db *gorm.DB) error {
-	// Obtener todos los equipos
-	var equipos []models.Equipo
-	if err := db.Find(&equipos).Error; err != nil {
-		return err
+// CambiarEstadoPartido cambia el estado de un partido (pendiente, en_curso, finalizado).
+// Si se indica la secuencia esperada y el partido ya cambió, devuelve ErrConflictoPartido.
+func (s *CalendarioService) CambiarEstadoPartido(partidoID uint, secuencia *uint, estado string) (models.Partido, error) {
+	if estado != models.EstadoPendiente && estado != models.EstadoEnCurso && estado != models.EstadoFinalizado {
+		return models.Partido{}, errors.New("estado de partido inválido")
 	}
 
-	// Número de equipos
-	numEquipos := len(equipos)
-	if numEquipos%2 != 0 {
-		return nil // Necesitamos un número par de equipos
+	partido, err := s.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return partido, err
 	}
 
-	// Número de jornadas será (numEquipos - 1) * 2 para ida y vuelta
-	numJornadas := (numEquipos - 1) * 2
+	if secuencia != nil && partido.Secuencia != *secuencia {
+		return partido, ErrConflictoPartido
+	}
 
-	// Fecha inicial para los partidos (primer sábado disponible)
-	fechaInicial := time.Now()
-	for fechaInicial.Weekday() != time.Saturday {
-		fechaInicial = fechaInicial.AddDate(0, 0, 1)
+	partido.Estado = estado
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return partido, err
 	}
 
-	// Crear jornadas
-	for i := 1; i <= numJornadas; i++ {
-		jornada := models.Jornada{
-			Numero: i,
-			Fecha:  fechaInicial.AddDate(0, 0, (i-1)*7), // Cada jornada una semana después
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoEstado, partido))
+	return partido, nil
+}
+
+// RegistrarIncidencia registra una incidencia en un partido.
+// Si la incidencia no tiene minuto y el partido está en juego, se usa el minuto del reloj.
+// Si el jugador no es de uno de los dos equipos del partido devuelve ErrJugadorAjeno.
+func (s *CalendarioService) RegistrarIncidencia(incidencia models.Incidencia) (models.Incidencia, error) {
+	return s.registrarIncidencia(incidencia, nil)
+}
+
+// RegistrarIncidenciaEnSecuencia registra una incidencia enviada por un operador que conocía
+// el partido en la secuencia indicada. Si desde entonces otro operador registró una incidencia
+// del mismo tipo para el mismo jugador, se considera un posible duplicado y devuelve ErrConflictoPartido.
+func (s *CalendarioService) RegistrarIncidenciaEnSecuencia(incidencia models.Incidencia, secuencia uint) (models.Incidencia, error) {
+	return s.registrarIncidencia(incidencia, &secuencia)
+}
+
+func (s *CalendarioService) registrarIncidencia(incidencia models.Incidencia, secuencia *uint) (models.Incidencia, error) {
+	// El equipo del jugador se guarda con la incidencia para que siga siendo correcto
+	// después de un traspaso
+	if incidencia.EquipoID == 0 {
+		jugador, err := s.Jugadores.Obtener(incidencia.JugadorID)
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
+			return incidencia, ErrJugadorIncidencia
+		}
+		if err != nil {
+			return incidencia, err
+		}
+		incidencia.EquipoID = jugador.EquipoID
+	}
+	var equipoEntraID uint
+	if incidencia.JugadorEntraID != nil {
+		entra, err := s.Jugadores.Obtener(*incidencia.JugadorEntraID)
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
+			return incidencia, ErrJugadorIncidencia
+		}
+		if err != nil {
+			return incidencia, err
 		}
+		equipoEntraID = entra.EquipoID
+	}
 
-		if err := db.Create(&jornada).Error; err != nil {
+	var partido models.Partido
+	err := s.Calendario.Transaccion(func(tx repositorios.CalendarioRepositorio) error {
+		var err error
+		if partido, err = tx.ObtenerPartido(incidencia.PartidoID); err != nil {
 			return err
 		}
+		if incidencia.EquipoID != partido.EquipoLocalID && incidencia.EquipoID != partido.EquipoVisitanteID {
+			return ErrJugadorAjeno
+		}
+		if incidencia.JugadorEntraID != nil && equipoEntraID != incidencia.EquipoID {
+			return ErrJugadorAjeno
+		}
 
-		// Generar partidos para esta jornada
-		if i <= numJornadas/2 {
-			// Primera vuelta
-			for j := 0; j < numEquipos/2; j++ {
-				partido := models.Partido{
-					JornadaID:         jornada.ID,
-					EquipoLocalID:     equipos[j].ID,
-					EquipoVisitanteID: equipos[numEquipos-1-j].ID,
-					FechaHora:         jornada.Fecha.Add(time.Hour * 15), // 3:00 PM
-					Estado:            "pendiente",
-					GolesLocal:        0,
-					GolesVisitante:    0,
-				}
-				if err := db.Create(&partido).Error; err != nil {
-					return err
-				}
+		if secuencia != nil && partido.Secuencia != *secuencia {
+			duplicadas, err := tx.BuscarIncidencias(repositorios.FiltroIncidencias{
+				PartidoIDs:      []uint{partido.ID},
+				JugadorID:       incidencia.JugadorID,
+				Tipos:           []models.TipoIncidencia{incidencia.Tipo},
+				SecuenciaMayorA: *secuencia,
+			})
+			if err != nil {
+				return err
 			}
-		} else {
-			// Segunda vuelta (invertir locales y visitantes)
-			for j := 0; j < numEquipos/2; j++ {
-				partido := models.Partido{
-					JornadaID:         jornada.ID,
-					EquipoLocalID:     equipos[numEquipos-1-j].ID,
-					EquipoVisitanteID: equipos[j].ID,
-					FechaHora:         jornada.Fecha.Add(time.Hour * 15), // 3:00 PM
-					Estado:            "pendiente",
-					GolesLocal:        0,
-					GolesVisitante:    0,
-				}
-				if err := db.Create(&partido).Error; err != nil {
-					return err
-				}
+			if len(duplicadas) > 0 {
+				return ErrConflictoPartido
+			}
+		}
+
+		if incidencia.Minuto == 0 {
+			reloj := CalcularReloj(partido, time.Now())
+			if reloj.EnJuego {
+				incidencia.Minuto = reloj.Minuto
+				incidencia.MinutoAnadido = reloj.MinutoAnadido
 			}
 		}
+
+		if err := guardarPartido(tx, &partido); err != nil {
+			return err
+		}
+
+		incidencia.Secuencia = partido.Secuencia
+		return tx.CrearIncidencia(&incidencia)
+	})
+	if err != nil {
+		return incidencia, err
 	}
 
-	return nil
+	evento := NuevoEventoPartido(EventoIncidencia, partido)
+	evento.Incidencia = &incidencia
+	EventosEnVivo.Publicar(evento)
+	return incidencia, nil
 }
 
-// ActualizarResultadoPartido actualiza el resultado de un partido
-func (s *CalendarioService) ActualizarResultadoPartido(partidoID uint, golesLocal, golesVisitante int) error {
-	// Obtener el partido
-	var partido models.Partido
-	if err := s.DB.First(&partido, partidoID).Error; err != nil {
+// GetIncidenciasDesde obtiene las incidencias de un partido registradas después de una secuencia
+func (s *CalendarioService) GetIncidenciasDesde(partidoID, secuencia uint) ([]models.Incidencia, error) {
+	return s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{
+		PartidoIDs:      []uint{partidoID},
+		SecuenciaMayorA: secuencia,
+	})
+}
+
+// ErrJugadorIncidencia indica que el jugador de una incidencia no existe
+var ErrJugadorIncidencia = errors.New("el jugador de la incidencia no existe")
+
+// ErrJugadorAjeno indica que el jugador de una incidencia no juega con ninguno de los dos equipos
+// del partido, o que el jugador que entra en una sustitución no es del equipo del que sale
+var ErrJugadorAjeno = errors.New("el jugador no pertenece a los equipos del partido")
+
+// ErrConflictoPartido indica que el partido cambió desde la versión que conocía quien hizo la operación
+var ErrConflictoPartido = errors.New("el partido fue modificado por otro operador")
+
+// guardarPartido guarda todos los campos del partido solo si nadie lo modificó desde que se leyó,
+// y avanza su número de secuencia
+func guardarPartido(repo repositorios.CalendarioRepositorio, partido *models.Partido) error {
+	if err := repo.GuardarPartido(partido); err != nil {
+		if errors.Is(err, ErrVersionObsoleta) {
+			return ErrConflictoPartido
+		}
 		return err
 	}
-	
-	// Actualizar el resultado
-	partido.GolesLocal = golesLocal
-	partido.GolesVisitante = golesVisitante
-	partido.Estado = "Finalizado"
-	
-	// Guardar cambios
-	return s.DB.Save(&partido).Error
+	return nil
 }
 
-// RegistrarIncidencia registra una incidencia en un partido
-func (s *CalendarioService) RegistrarIncidencia(incidencia models.Incidencia) error {
-	return s.DB.Create(&incidencia).Error
+// ErrTransicionPeriodo indica que el periodo actual del partido no admite la operación solicitada
+var ErrTransicionPeriodo = errors.New("transición de periodo inválida")
+
+// IniciarPeriodo inicia el siguiente periodo de juego de un partido: el primer tiempo si está
+// pendiente o el segundo tiempo si está en el descanso. Un partido sin periodo que ya se jugó o
+// se aplazó no se puede volver a iniciar.
+func (s *CalendarioService) IniciarPeriodo(partidoID uint) (models.Partido, error) {
+	partido, err := s.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return partido, err
+	}
+
+	ahora := time.Now()
+	switch {
+	case partido.Periodo == "" && partido.Estado == models.EstadoPendiente:
+		partido.Periodo = models.PeriodoPrimerTiempo
+		partido.Estado = models.EstadoEnCurso
+		partido.InicioPartido = &ahora
+	case partido.Periodo == models.PeriodoDescanso:
+		partido.Periodo = models.PeriodoSegundoTiempo
+	default:
+		return partido, ErrTransicionPeriodo
+	}
+	partido.InicioPeriodo = &ahora
+	partido.TiempoAnadido = 0
+
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return partido, err
+	}
+
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoEstado, partido))
+	return partido, nil
+}
+
+// FinalizarPeriodo termina el periodo en juego: el primer tiempo pasa al descanso
+// y el segundo tiempo da el partido por finalizado
+func (s *CalendarioService) FinalizarPeriodo(partidoID uint) (models.Partido, error) {
+	partido, err := s.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return partido, err
+	}
+
+	switch partido.Periodo {
+	case models.PeriodoPrimerTiempo:
+		partido.Periodo = models.PeriodoDescanso
+	case models.PeriodoSegundoTiempo:
+		partido.Periodo = models.PeriodoFinal
+		partido.Estado = models.EstadoFinalizado
+	default:
+		return partido, ErrTransicionPeriodo
+	}
+	partido.InicioPeriodo = nil
+
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return partido, err
+	}
+
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoEstado, partido))
+	return partido, nil
+}
+
+// FijarTiempoAnadido registra los minutos añadidos anunciados para el periodo en juego.
+// Si se indica la secuencia esperada y el partido ya cambió, devuelve ErrConflictoPartido.
+func (s *CalendarioService) FijarTiempoAnadido(partidoID uint, secuencia *uint, minutos int) (models.Partido, error) {
+	partido, err := s.Calendario.ObtenerPartido(partidoID)
+	if err != nil {
+		return partido, err
+	}
+
+	if secuencia != nil && partido.Secuencia != *secuencia {
+		return partido, ErrConflictoPartido
+	}
+
+	if partido.Periodo != models.PeriodoPrimerTiempo && partido.Periodo != models.PeriodoSegundoTiempo {
+		return partido, ErrTransicionPeriodo
+	}
+
+	partido.TiempoAnadido = minutos
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return partido, err
+	}
+
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoEstado, partido))
+	return partido, nil
+}
+
+// ActualizarPartido guarda los datos administrativos de un partido si nadie lo modificó
+// desde que se leyó; en caso contrario devuelve ErrConflictoPartido
+func (s *CalendarioService) ActualizarPartido(partido models.Partido) (models.Partido, error) {
+	if err := guardarPartido(s.Calendario, &partido); err != nil {
+		return partido, err
+	}
+
+	EventosEnVivo.Publicar(NuevoEventoPartido(EventoEstado, partido))
+	return partido, nil
+}
+
+// ActualizarJornada guarda los cambios de una jornada si su versión sigue siendo la que
+// conocía quien la modifica; en caso contrario devuelve ErrVersionObsoleta
+func (s *CalendarioService) ActualizarJornada(jornada models.Jornada) (models.Jornada, error) {
+	err := s.Calendario.ActualizarJornada(&jornada)
+	return jornada, err
+}
+
+// ventanaInicioPartido es cuánto tiempo después de su hora un partido que todavía no empezó
+// sigue marcando la jornada actual, por si los operadores lo inician con retraso
+const ventanaInicioPartido = 3 * time.Hour
+
+// ErrSinJornadaActual indica que el calendario no tiene partidos
+var ErrSinJornadaActual = errors.New("no hay jornadas con partidos")
+
+// GetJornadaActual obtiene la jornada que se está jugando según las fechas y los estados de
+// sus partidos: la de un partido en curso; si no hay ninguno, la del próximo partido por
+// jugar; y al terminar la temporada, la del último partido jugado
+func (s *CalendarioService) GetJornadaActual(ahora time.Time) (models.Jornada, error) {
+	desde := ahora.Add(-ventanaInicioPartido)
+	filtros := []repositorios.FiltroPartidos{
+		{Estado: models.EstadoEnCurso, Limite: 1},
+		{Estado: models.EstadoPendiente, Desde: &desde, Limite: 1},
+		{Estado: models.EstadoFinalizado, Descendente: true, Limite: 1},
+	}
+	for _, filtro := range filtros {
+		partidos, err := s.Calendario.BuscarPartidos(filtro)
+		if err != nil {
+			return models.Jornada{}, err
+		}
+		if len(partidos) > 0 {
+			return s.Calendario.ObtenerJornada(partidos[0].JornadaID)
+		}
+	}
+	return models.Jornada{}, ErrSinJornadaActual
+}
+
+// GetPartidosEnCurso obtiene los partidos en curso de una jornada
+func (s *CalendarioService) GetPartidosEnCurso(jornadaID uint) ([]models.Partido, error) {
+	return s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		JornadaID: jornadaID,
+		Estado:    models.EstadoEnCurso,
+	})
+}
+
+// GoleadorEnfrentamiento representa a un goleador en los partidos entre dos equipos
+type GoleadorEnfrentamiento struct {
+	JugadorID uint   `json:"jugadorId"`
+	Nombre    string `json:"nombre"`
+	Apellido  string `json:"apellido"`
+	EquipoID  uint   `json:"equipoId"`
+	Goles     int    `json:"goles"`
+}
+
+// Enfrentamiento resume el historial de partidos entre dos equipos
+type Enfrentamiento struct {
+	EquipoAID      uint                     `json:"equipoAId"`
+	EquipoBID      uint                     `json:"equipoBId"`
+	Partidos       []models.Partido         `json:"partidos"`
+	PJ             int                      `json:"pj"`
+	VictoriasA     int                      `json:"victoriasA"`
+	Empates        int                      `json:"empates"`
+	VictoriasB     int                      `json:"victoriasB"`
+	GolesA         int                      `json:"golesA"`
+	GolesB         int                      `json:"golesB"`
+	Goleadores     []GoleadorEnfrentamiento `json:"goleadores"`
+	ProximoPartido *models.Partido          `json:"proximoPartido"`
+}
+
+// GetEnfrentamientos obtiene todos los partidos entre dos equipos, el balance de resultados,
+// los máximos goleadores del cruce y el próximo partido programado entre ambos
+func (s *CalendarioService) GetEnfrentamientos(equipoAID, equipoBID uint) (Enfrentamiento, error) {
+	enfrentamiento := Enfrentamiento{
+		EquipoAID:  equipoAID,
+		EquipoBID:  equipoBID,
+		Partidos:   []models.Partido{},
+		Goleadores: []GoleadorEnfrentamiento{},
+	}
+
+	// Obtener partidos donde ambos equipos se enfrentaron, sin importar la localía
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		EquipoID: equipoAID,
+		RivalID:  equipoBID,
+	})
+	if err != nil {
+		return enfrentamiento, err
+	}
+
+	var finalizados []uint
+	for _, partido := range partidos {
+		if partido.Estado != models.EstadoFinalizado {
+			continue
+		}
+
+		enfrentamiento.Partidos = append(enfrentamiento.Partidos, partido)
+		finalizados = append(finalizados, partido.ID)
+		enfrentamiento.PJ++
+
+		golesA, golesB := partido.GolesLocal, partido.GolesVisitante
+		if partido.EquipoLocalID == equipoBID {
+			golesA, golesB = golesB, golesA
+		}
+		enfrentamiento.GolesA += golesA
+		enfrentamiento.GolesB += golesB
+
+		switch resultadoPartido(golesA, golesB) {
+		case "V":
+			enfrentamiento.VictoriasA++
+		case "E":
+			enfrentamiento.Empates++
+		case "D":
+			enfrentamiento.VictoriasB++
+		}
+	}
+
+	// Máximos goleadores en los partidos finalizados entre ambos equipos
+	if len(finalizados) > 0 {
+		incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{
+			PartidoIDs: finalizados,
+			Tipos:      []models.TipoIncidencia{models.Gol, models.GolPenal},
+			ConJugador: true,
+		})
+		if err != nil {
+			return enfrentamiento, err
+		}
+
+		// Cada goleador cuenta con el equipo con el que jugaba; un jugador traspasado de un
+		// equipo al otro aparece una vez por cada uno
+		goleadores := make(map[[2]uint]*GoleadorEnfrentamiento) // [jugador, equipo]
+		for _, incidencia := range incidencias {
+			clave := [2]uint{incidencia.JugadorID, equipoIncidencia(incidencia)}
+			goleador, ok := goleadores[clave]
+			if !ok {
+				goleador = &GoleadorEnfrentamiento{
+					JugadorID: incidencia.JugadorID,
+					Nombre:    incidencia.Jugador.Nombre,
+					Apellido:  incidencia.Jugador.Apellido,
+					EquipoID:  clave[1],
+				}
+				goleadores[clave] = goleador
+			}
+			goleador.Goles++
+		}
+
+		for _, goleador := range goleadores {
+			enfrentamiento.Goleadores = append(enfrentamiento.Goleadores, *goleador)
+		}
+		sort.Slice(enfrentamiento.Goleadores, func(i, j int) bool {
+			a, b := enfrentamiento.Goleadores[i], enfrentamiento.Goleadores[j]
+			if a.Goles != b.Goles {
+				return a.Goles > b.Goles
+			}
+			if a.JugadorID != b.JugadorID {
+				return a.JugadorID < b.JugadorID
+			}
+			return a.EquipoID < b.EquipoID
+		})
+		if len(enfrentamiento.Goleadores) > 10 {
+			enfrentamiento.Goleadores = enfrentamiento.Goleadores[:10]
+		}
+	}
+
+	// Próximo partido programado entre ambos equipos
+	for i := range partidos {
+		if partidos[i].Estado == models.EstadoPendiente && !partidos[i].FechaHora.Before(time.Now()) {
+			enfrentamiento.ProximoPartido = &partidos[i]
+			break
+		}
+	}
+
+	return enfrentamiento, nil
 }
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+func TestGetEnfrentamientosDespuesDeUnTraspaso(t *testing.T) {
+	repos, aguilas, buhos, ida := traspasoEnMemoria(t)
+
+	// En la vuelta el goleador traspasado marca para Águilas, y queda un tercer partido por jugar
+	jugadores, err := repos.Jugadores.Listar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var traspasado, delantero models.Jugador
+	for _, jugador := range jugadores {
+		switch jugador.Nombre {
+		case "Luis":
+			traspasado = jugador
+		case "Ana":
+			delantero = jugador
+		}
+	}
+	vuelta := models.Partido{
+		JornadaID:         ida.JornadaID,
+		EquipoLocalID:     buhos.ID,
+		EquipoVisitanteID: aguilas.ID,
+		GolesVisitante:    1,
+		FechaHora:         ida.FechaHora.AddDate(0, 0, 7),
+		Estado:            models.EstadoFinalizado,
+	}
+	proximo := models.Partido{
+		JornadaID:         ida.JornadaID,
+		EquipoLocalID:     aguilas.ID,
+		EquipoVisitanteID: buhos.ID,
+		FechaHora:         time.Now().AddDate(0, 1, 0),
+		Estado:            models.EstadoPendiente,
+	}
+	for _, partido := range []*models.Partido{&vuelta, &proximo} {
+		if err := repos.Calendario.CrearPartido(partido); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gol := models.Incidencia{PartidoID: vuelta.ID, JugadorID: traspasado.ID, EquipoID: aguilas.ID, Tipo: models.Gol, Minuto: 5, Secuencia: 1}
+	if err := repos.Calendario.CrearIncidencia(&gol); err != nil {
+		t.Fatal(err)
+	}
+
+	enfrentamiento, err := NewCalendarioServiceConRepositorios(repos).GetEnfrentamientos(aguilas.ID, buhos.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := [5]int{enfrentamiento.PJ, enfrentamiento.VictoriasA, enfrentamiento.Empates, enfrentamiento.VictoriasB, enfrentamiento.GolesA}
+	if balance != [5]int{2, 1, 0, 1, 2} || enfrentamiento.GolesB != 2 {
+		t.Errorf("PJ, victorias A, empates, victorias B, goles A = %v y goles B = %d; se esperaba [2 1 0 1 2] y 2", balance, enfrentamiento.GolesB)
+	}
+	if enfrentamiento.ProximoPartido == nil || enfrentamiento.ProximoPartido.ID != proximo.ID {
+		t.Errorf("próximo partido %v; se esperaba el %d", enfrentamiento.ProximoPartido, proximo.ID)
+	}
+
+	// El gol en contra no cuenta, y el traspasado aparece con cada uno de sus equipos
+	esperados := []struct {
+		jugador, equipo uint
+	}{
+		{delantero.ID, aguilas.ID},
+		{traspasado.ID, aguilas.ID},
+		{traspasado.ID, buhos.ID},
+	}
+	if len(enfrentamiento.Goleadores) != len(esperados) {
+		t.Fatalf("goleadores %+v; se esperaban %d", enfrentamiento.Goleadores, len(esperados))
+	}
+	for i, esperado := range esperados {
+		goleador := enfrentamiento.Goleadores[i]
+		if goleador.JugadorID != esperado.jugador || goleador.EquipoID != esperado.equipo || goleador.Goles != 1 {
+			t.Errorf("goleador %d: jugador %d de %d con %d goles; se esperaba el %d de %d con 1",
+				i+1, goleador.JugadorID, goleador.EquipoID, goleador.Goles, esperado.jugador, esperado.equipo)
+		}
+	}
+}
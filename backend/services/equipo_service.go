@@ -3,80 +3,86 @@ package services
 import (
 	"errors"
 
-	"github.com/noisk8/torneas/backend/database"
 	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
 	"gorm.io/gorm"
 )
 
+const (
+	// FormaPorDefecto es la cantidad de partidos que se incluyen en la forma reciente
+	FormaPorDefecto = 5
+	// FormaMaxima limita la cantidad de partidos que se pueden pedir en la forma reciente
+	FormaMaxima = 20
+)
+
 // EquipoService proporciona métodos para interactuar con los equipos
 type EquipoService struct {
-	DB *gorm.DB
+	repositorios.Repositorios
 }
 
 // NewEquipoService crea una nueva instancia del servicio de equipos
-func NewEquipoService() *EquipoService {
+func NewEquipoService(db *gorm.DB) *EquipoService {
+	return NewEquipoServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewEquipoServiceConRepositorios crea el servicio de equipos sobre los repositorios indicados
+func NewEquipoServiceConRepositorios(repos repositorios.Repositorios) *EquipoService {
 	return &EquipoService{
-		DB: database.GetDB(),
+		Repositorios: repos,
 	}
 }
 
 // GetAllEquipos obtiene todos los equipos
 func (s *EquipoService) GetAllEquipos() ([]models.Equipo, error) {
-	var equipos []models.Equipo
-	result := s.DB.Find(&equipos)
-	return equipos, result.Error
+	return s.Equipos.Listar()
 }
 
 // GetEquipoByID obtiene un equipo por su ID
 func (s *EquipoService) GetEquipoByID(id uint) (models.Equipo, error) {
-	var equipo models.Equipo
-	result := s.DB.First(&equipo, id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	equipo, err := s.Equipos.Obtener(id)
+	if err != nil {
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
 			return equipo, errors.New("equipo no encontrado")
 		}
-		return equipo, result.Error
+		return equipo, err
 	}
 	return equipo, nil
 }
 
 // GetJugadoresByEquipo obtiene los jugadores de un equipo
 func (s *EquipoService) GetJugadoresByEquipo(equipoID uint) ([]models.Jugador, error) {
-	var jugadores []models.Jugador
-	result := s.DB.Where("equipo_id = ?", equipoID).Find(&jugadores)
-	return jugadores, result.Error
+	return s.Jugadores.ListarPorEquipo(equipoID)
 }
 
 // CreateEquipo crea un nuevo equipo
 func (s *EquipoService) CreateEquipo(equipo models.Equipo) (models.Equipo, error) {
-	result := s.DB.Create(&equipo)
-	return equipo, result.Error
+	err := s.Equipos.Crear(&equipo)
+	return equipo, err
 }
 
-// UpdateEquipo actualiza un equipo existente
+// UpdateEquipo actualiza un equipo existente si su versión sigue siendo la que
+// conocía quien lo modifica; en caso contrario devuelve ErrVersionObsoleta
 func (s *EquipoService) UpdateEquipo(equipo models.Equipo) (models.Equipo, error) {
-	result := s.DB.Save(&equipo)
-	return equipo, result.Error
+	err := s.Equipos.Actualizar(&equipo)
+	return equipo, err
 }
 
 // DeleteEquipo elimina un equipo por su ID
 func (s *EquipoService) DeleteEquipo(id uint) error {
-	result := s.DB.Delete(&models.Equipo{}, id)
-	return result.Error
+	return s.Equipos.Eliminar(id)
 }
 
 // GetTablaPosiciones obtiene la tabla de posiciones
-func (s *EquipoService) GetTablaPosiciones() ([]models.Equipo, error) {
-	var equipos []models.Equipo
-	
+func (s *EquipoService) GetTablaPosiciones(partidosForma int) ([]models.Equipo, error) {
 	// Obtener todos los equipos
-	if err := s.DB.Find(&equipos).Error; err != nil {
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
 		return nil, err
 	}
 	
 	// Calcular estadísticas para cada equipo
 	for i := range equipos {
-		if err := CalcularEstadisticas(s.DB, &equipos[i]); err != nil {
+		if err := s.CalcularEstadisticas(&equipos[i], partidosForma); err != nil {
 			return nil, err
 		}
 	}
@@ -104,12 +110,15 @@ func (s *EquipoService) GetTablaPosiciones() ([]models.Equipo, error) {
 	return equipos, nil
 }
 
-// CalcularEstadisticas calcula las estadísticas para un equipo
-func CalcularEstadisticas(db *gorm.DB, equipo *models.Equipo) error {
+// CalcularEstadisticas calcula las estadísticas para un equipo, incluyendo
+// la forma reciente con los últimos partidosForma partidos
+func (s *EquipoService) CalcularEstadisticas(equipo *models.Equipo, partidosForma int) error {
 	// Obtener todos los partidos finalizados del equipo
-	var partidos []models.Partido
-	if err := db.Where("(equipo_local_id = ? OR equipo_visitante_id = ?) AND estado = ?",
-		equipo.ID, equipo.ID, "finalizado").Find(&partidos).Error; err != nil {
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		EquipoID: equipo.ID,
+		Estado:   models.EstadoFinalizado,
+	})
+	if err != nil {
 		return err
 	}
 
@@ -156,38 +165,234 @@ func CalcularEstadisticas(db *gorm.DB, equipo *models.Equipo) error {
 	equipo.DG = equipo.GF - equipo.GC
 	equipo.Puntos = (equipo.PG * 3) + equipo.PE
 
-	// Obtener los últimos 5 partidos
-	var ultimosPartidos []models.Partido
-	if err := db.Where("(equipo_local_id = ? OR equipo_visitante_id = ?) AND estado = ?",
-		equipo.ID, equipo.ID, "finalizado").
-		Order("fecha_hora DESC").
-		Limit(5).
-		Find(&ultimosPartidos).Error; err != nil {
+	// Calcular forma reciente
+	forma, err := s.CalcularForma(equipo.ID, partidosForma)
+	if err != nil {
 		return err
 	}
+	equipo.Forma = forma
+	equipo.UltimosJuegos = ""
+	for _, partido := range forma {
+		equipo.UltimosJuegos += partido.Resultado
+	}
 
-	// Calcular forma reciente
-	ultimosJuegos := ""
-	for _, partido := range ultimosPartidos {
-		if partido.EquipoLocalID == equipo.ID {
-			if partido.GolesLocal > partido.GolesVisitante {
-				ultimosJuegos += "V"
-			} else if partido.GolesLocal < partido.GolesVisitante {
-				ultimosJuegos += "D"
-			} else {
-				ultimosJuegos += "E"
-			}
+	return nil
+}
+
+// CalcularForma obtiene los últimos partidos finalizados de un equipo,
+// ordenados del más antiguo al más reciente
+func (s *EquipoService) CalcularForma(equipoID uint, cantidad int) ([]models.PartidoForma, error) {
+	forma := []models.PartidoForma{}
+	if cantidad <= 0 {
+		return forma, nil
+	}
+
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		EquipoID:    equipoID,
+		Estado:      models.EstadoFinalizado,
+		Descendente: true,
+		Limite:      cantidad,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Recorrer del más antiguo al más reciente
+	var rivalIDs []uint
+	for i := len(partidos) - 1; i >= 0; i-- {
+		partido := partidos[i]
+		entrada := models.PartidoForma{
+			PartidoID:   partido.ID,
+			FechaHora:   partido.FechaHora,
+			Local:       partido.EquipoLocalID == equipoID,
+			RivalID:     partido.EquipoLocalID,
+			GolesFavor:  partido.GolesVisitante,
+			GolesContra: partido.GolesLocal,
+		}
+		if entrada.Local {
+			entrada.RivalID = partido.EquipoVisitanteID
+			entrada.GolesFavor = partido.GolesLocal
+			entrada.GolesContra = partido.GolesVisitante
+		}
+		entrada.Resultado = resultadoPartido(entrada.GolesFavor, entrada.GolesContra)
+
+		forma = append(forma, entrada)
+		rivalIDs = append(rivalIDs, entrada.RivalID)
+	}
+
+	if len(rivalIDs) == 0 {
+		return forma, nil
+	}
+
+	// Obtener los nombres de los rivales
+	rivales, err := s.Equipos.ObtenerVarios(rivalIDs)
+	if err != nil {
+		return nil, err
+	}
+	nombres := make(map[uint]string, len(rivales))
+	for _, rival := range rivales {
+		nombres[rival.ID] = rival.Nombre
+	}
+	for i := range forma {
+		forma[i].Rival = nombres[forma[i].RivalID]
+	}
+
+	return forma, nil
+}
+
+// IntervaloGoles cuenta los goles de un equipo en un tramo de 15 minutos
+type IntervaloGoles struct {
+	Desde    int `json:"desde"`
+	Hasta    int `json:"hasta"` // El último tramo incluye el tiempo añadido
+	AFavor   int `json:"aFavor"`
+	EnContra int `json:"enContra"`
+}
+
+// ResultadoDestacado describe un partido concreto, como la mayor victoria o derrota
+type ResultadoDestacado struct {
+	PartidoID   uint `json:"partidoId"`
+	RivalID     uint `json:"rivalId"`
+	Local       bool `json:"local"`
+	GolesFavor  int  `json:"golesFavor"`
+	GolesContra int  `json:"golesContra"`
+}
+
+// EstadisticasEquipo contiene las estadísticas avanzadas de un equipo
+type EstadisticasEquipo struct {
+	EquipoID          uint                `json:"equipoId"`
+	PJ                int                 `json:"pj"`
+	VallasInvictas    int                 `json:"vallasInvictas"`
+	PartidosSinMarcar int                 `json:"partidosSinMarcar"`
+	GolesPorIntervalo []IntervaloGoles    `json:"golesPorIntervalo"`
+	PuntosLocal       int                 `json:"puntosLocal"`
+	PuntosVisitante   int                 `json:"puntosVisitante"`
+	MayorVictoria     *ResultadoDestacado `json:"mayorVictoria"`
+	MayorDerrota      *ResultadoDestacado `json:"mayorDerrota"`
+	RachaVictorias    int                 `json:"rachaVictorias"` // Racha más larga de victorias consecutivas
+	RachaInvicto      int                 `json:"rachaInvicto"`   // Racha más larga sin perder
+}
+
+// GetEstadisticasEquipo calcula las estadísticas avanzadas de un equipo
+// a partir de sus partidos finalizados y las incidencias registradas en ellos
+func (s *EquipoService) GetEstadisticasEquipo(equipoID uint) (EstadisticasEquipo, error) {
+	estadisticas := EstadisticasEquipo{EquipoID: equipoID}
+	for desde := 0; desde < 90; desde += 15 {
+		estadisticas.GolesPorIntervalo = append(estadisticas.GolesPorIntervalo, IntervaloGoles{
+			Desde: desde + 1,
+			Hasta: desde + 15,
+		})
+	}
+
+	// Obtener los partidos finalizados del equipo en orden cronológico
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{
+		EquipoID: equipoID,
+		Estado:   models.EstadoFinalizado,
+	})
+	if err != nil {
+		return estadisticas, err
+	}
+
+	var rachaVictorias, rachaInvicto int
+	partidoIDs := make([]uint, 0, len(partidos))
+	for _, partido := range partidos {
+		partidoIDs = append(partidoIDs, partido.ID)
+		estadisticas.PJ++
+
+		destacado := ResultadoDestacado{
+			PartidoID:   partido.ID,
+			RivalID:     partido.EquipoLocalID,
+			Local:       partido.EquipoLocalID == equipoID,
+			GolesFavor:  partido.GolesVisitante,
+			GolesContra: partido.GolesLocal,
+		}
+		if destacado.Local {
+			destacado.RivalID = partido.EquipoVisitanteID
+			destacado.GolesFavor = partido.GolesLocal
+			destacado.GolesContra = partido.GolesVisitante
+		}
+
+		if destacado.GolesContra == 0 {
+			estadisticas.VallasInvictas++
+		}
+		if destacado.GolesFavor == 0 {
+			estadisticas.PartidosSinMarcar++
+		}
+
+		puntos := 0
+		switch resultadoPartido(destacado.GolesFavor, destacado.GolesContra) {
+		case "V":
+			puntos = 3
+			rachaVictorias++
+			rachaInvicto++
+		case "E":
+			puntos = 1
+			rachaVictorias = 0
+			rachaInvicto++
+		case "D":
+			rachaVictorias = 0
+			rachaInvicto = 0
+		}
+		if destacado.Local {
+			estadisticas.PuntosLocal += puntos
 		} else {
-			if partido.GolesVisitante > partido.GolesLocal {
-				ultimosJuegos += "V"
-			} else if partido.GolesVisitante < partido.GolesLocal {
-				ultimosJuegos += "D"
-			} else {
-				ultimosJuegos += "E"
-			}
+			estadisticas.PuntosVisitante += puntos
+		}
+		if rachaVictorias > estadisticas.RachaVictorias {
+			estadisticas.RachaVictorias = rachaVictorias
+		}
+		if rachaInvicto > estadisticas.RachaInvicto {
+			estadisticas.RachaInvicto = rachaInvicto
+		}
+
+		diferencia := destacado.GolesFavor - destacado.GolesContra
+		if diferencia > 0 && (estadisticas.MayorVictoria == nil ||
+			diferencia > estadisticas.MayorVictoria.GolesFavor-estadisticas.MayorVictoria.GolesContra) {
+			victoria := destacado
+			estadisticas.MayorVictoria = &victoria
+		}
+		if diferencia < 0 && (estadisticas.MayorDerrota == nil ||
+			diferencia < estadisticas.MayorDerrota.GolesFavor-estadisticas.MayorDerrota.GolesContra) {
+			derrota := destacado
+			estadisticas.MayorDerrota = &derrota
 		}
 	}
-	equipo.UltimosJuegos = ultimosJuegos
 
-	return nil
+	if len(partidoIDs) == 0 {
+		return estadisticas, nil
+	}
+
+	// Distribuir los goles por tramos de 15 minutos
+	goles, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{
+		PartidoIDs: partidoIDs,
+		Tipos:      []models.TipoIncidencia{models.Gol, models.GolPenal, models.GolEnContra},
+		ConJugador: true,
+	})
+	if err != nil {
+		return estadisticas, err
+	}
+
+	for _, gol := range goles {
+		intervalo := (gol.Minuto - 1) / 15
+		if intervalo < 0 {
+			intervalo = 0
+		}
+		if intervalo >= len(estadisticas.GolesPorIntervalo) {
+			intervalo = len(estadisticas.GolesPorIntervalo) - 1
+		}
+
+		// Un gol en contra cuenta para el equipo rival del autor, el equipo con el que jugaba
+		// el partido aunque después lo hayan traspasado
+		delEquipo := equipoIncidencia(gol) == equipoID
+		if gol.Tipo == models.GolEnContra {
+			delEquipo = !delEquipo
+		}
+
+		if delEquipo {
+			estadisticas.GolesPorIntervalo[intervalo].AFavor++
+		} else {
+			estadisticas.GolesPorIntervalo[intervalo].EnContra++
+		}
+	}
+
+	return estadisticas, nil
 }
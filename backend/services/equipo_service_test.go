@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+// traspasoEnMemoria crea en repositorios en memoria el partido Águilas 1-2 Búhos, finalizado,
+// con un gol de Búhos en el minuto 10, un gol en contra de un defensa de Águilas en el 50 y
+// un gol de Águilas en el 80. Después del partido el goleador de Búhos pasa a Águilas y el
+// defensa se elimina.
+func traspasoEnMemoria(t *testing.T) (repositorios.Repositorios, models.Equipo, models.Equipo, models.Partido) {
+	t.Helper()
+	repos := repositorios.NewEnMemoria()
+	aguilas := models.Equipo{Nombre: "Águilas"}
+	buhos := models.Equipo{Nombre: "Búhos"}
+	for _, equipo := range []*models.Equipo{&aguilas, &buhos} {
+		if err := repos.Equipos.Crear(equipo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delantero := models.Jugador{Nombre: "Ana", Apellido: "Ríos", EquipoID: aguilas.ID}
+	defensa := models.Jugador{Nombre: "Iván", Apellido: "Paz", EquipoID: aguilas.ID}
+	traspasado := models.Jugador{Nombre: "Luis", Apellido: "Mora", EquipoID: buhos.ID}
+	for _, jugador := range []*models.Jugador{&delantero, &defensa, &traspasado} {
+		if err := repos.Jugadores.Crear(jugador); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jornada := models.Jornada{Numero: 1}
+	if err := repos.Calendario.CrearJornada(&jornada); err != nil {
+		t.Fatal(err)
+	}
+	partido := models.Partido{
+		JornadaID:         jornada.ID,
+		EquipoLocalID:     aguilas.ID,
+		EquipoVisitanteID: buhos.ID,
+		GolesLocal:        1,
+		GolesVisitante:    2,
+		FechaHora:         time.Date(2024, 8, 3, 20, 0, 0, 0, time.UTC),
+		Estado:            models.EstadoFinalizado,
+	}
+	if err := repos.Calendario.CrearPartido(&partido); err != nil {
+		t.Fatal(err)
+	}
+	goles := []models.Incidencia{
+		{JugadorID: traspasado.ID, EquipoID: buhos.ID, Tipo: models.Gol, Minuto: 10},
+		{JugadorID: defensa.ID, EquipoID: aguilas.ID, Tipo: models.GolEnContra, Minuto: 50},
+		{JugadorID: delantero.ID, EquipoID: aguilas.ID, Tipo: models.Gol, Minuto: 80},
+	}
+	for i := range goles {
+		goles[i].PartidoID = partido.ID
+		goles[i].Secuencia = uint(i + 1)
+		if err := repos.Calendario.CrearIncidencia(&goles[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	traspasado.EquipoID = aguilas.ID
+	if err := repos.Jugadores.Actualizar(&traspasado); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Jugadores.Eliminar(defensa.ID); err != nil {
+		t.Fatal(err)
+	}
+	return repos, aguilas, buhos, partido
+}
+
+func TestGetEstadisticasEquipoDespuesDeUnTraspaso(t *testing.T) {
+	repos, aguilas, buhos, _ := traspasoEnMemoria(t)
+	service := NewEquipoServiceConRepositorios(repos)
+
+	// Tramos de 15 minutos: el 10 cae en el primero, el 50 en el cuarto y el 80 en el sexto
+	casos := []struct {
+		equipo           models.Equipo
+		aFavor, enContra [6]int
+	}{
+		{aguilas, [6]int{0, 0, 0, 0, 0, 1}, [6]int{1, 0, 0, 1, 0, 0}},
+		{buhos, [6]int{1, 0, 0, 1, 0, 0}, [6]int{0, 0, 0, 0, 0, 1}},
+	}
+	for _, caso := range casos {
+		t.Run(caso.equipo.Nombre, func(t *testing.T) {
+			estadisticas, err := service.GetEstadisticasEquipo(caso.equipo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var aFavor, enContra [6]int
+			for i, intervalo := range estadisticas.GolesPorIntervalo {
+				aFavor[i], enContra[i] = intervalo.AFavor, intervalo.EnContra
+			}
+			if aFavor != caso.aFavor || enContra != caso.enContra {
+				t.Errorf("goles a favor %v y en contra %v; se esperaba %v y %v", aFavor, enContra, caso.aFavor, caso.enContra)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"gorm.io/gorm"
+)
+
+// ocupacionEstadio es el tiempo que un partido ocupa el estadio: el partido, la preparación
+// y el desalojo. Dos partidos en el mismo estadio con menos de este tiempo entre sus horas
+// de inicio están en conflicto.
+const ocupacionEstadio = 4 * time.Hour
+
+// ErrEstadioEnUso indica que el estadio es la sede de algún equipo o partido y no se puede eliminar
+var ErrEstadioEnUso = errors.New("el estadio está asignado a equipos o partidos")
+
+// EstadioService proporciona métodos para interactuar con los estadios
+type EstadioService struct {
+	repositorios.Repositorios
+}
+
+// NewEstadioService crea una nueva instancia del servicio de estadios
+func NewEstadioService(db *gorm.DB) *EstadioService {
+	return NewEstadioServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewEstadioServiceConRepositorios crea el servicio de estadios sobre los repositorios indicados
+func NewEstadioServiceConRepositorios(repos repositorios.Repositorios) *EstadioService {
+	return &EstadioService{
+		Repositorios: repos,
+	}
+}
+
+// GetEstadios obtiene todos los estadios
+func (s *EstadioService) GetEstadios() ([]models.Estadio, error) {
+	return s.Estadios.Listar()
+}
+
+// GetEstadio obtiene un estadio por su ID
+func (s *EstadioService) GetEstadio(id uint) (models.Estadio, error) {
+	return s.Estadios.Obtener(id)
+}
+
+// CrearEstadio crea un nuevo estadio
+func (s *EstadioService) CrearEstadio(estadio models.Estadio) (models.Estadio, error) {
+	err := s.Estadios.Crear(&estadio)
+	return estadio, err
+}
+
+// ActualizarEstadio guarda los cambios de un estadio si su versión sigue siendo la que
+// conocía quien lo modifica; en caso contrario devuelve ErrVersionObsoleta
+func (s *EstadioService) ActualizarEstadio(estadio models.Estadio) (models.Estadio, error) {
+	err := s.Estadios.Actualizar(&estadio)
+	return estadio, err
+}
+
+// EliminarEstadio elimina un estadio que no es la sede de ningún equipo ni partido
+func (s *EstadioService) EliminarEstadio(id uint) error {
+	if _, err := s.Estadios.Obtener(id); err != nil {
+		return err
+	}
+
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return err
+	}
+	for _, equipo := range equipos {
+		if equipo.EstadioID != nil && *equipo.EstadioID == id {
+			return ErrEstadioEnUso
+		}
+	}
+
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		return err
+	}
+	for _, partido := range partidos {
+		if partido.EstadioID != nil && *partido.EstadioID == id {
+			return ErrEstadioEnUso
+		}
+	}
+
+	return s.Estadios.Eliminar(id)
+}
+
+// PartidoAgenda es un partido en la agenda de un estadio
+type PartidoAgenda struct {
+	models.Partido
+	Neutral    bool   `json:"neutral"`    // El estadio no es el del equipo local
+	Conflictos []uint `json:"conflictos"` // Otros partidos que ocupan el estadio al mismo tiempo
+}
+
+// AgendaEstadio contiene los partidos programados en un estadio
+type AgendaEstadio struct {
+	Estadio    models.Estadio  `json:"estadio"`
+	Partidos   []PartidoAgenda `json:"partidos"`
+	Conflictos int             `json:"conflictos"` // Cantidad de partidos con algún conflicto
+}
+
+// GetAgendaEstadio obtiene los partidos que se juegan en un estadio entre desde y hasta,
+// que pueden ser nil, e indica los que coinciden en el tiempo con otro partido en el mismo
+// estadio. Un partido se juega en el estadio asignado al partido o, si no tiene, en el del
+// equipo local. Los partidos aplazados no ocupan el estadio.
+func (s *EstadioService) GetAgendaEstadio(id uint, desde, hasta *time.Time) (AgendaEstadio, error) {
+	agenda := AgendaEstadio{Partidos: []PartidoAgenda{}}
+
+	estadio, err := s.Estadios.Obtener(id)
+	if err != nil {
+		return agenda, err
+	}
+	agenda.Estadio = estadio
+
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return agenda, err
+	}
+	sedes := sedesEquipos(equipos)
+
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{Desde: desde, Hasta: hasta})
+	if err != nil {
+		return agenda, err
+	}
+	for _, partido := range partidos {
+		if partido.Estado == models.EstadoAplazado {
+			continue
+		}
+		sede := estadioDePartido(partido, sedes)
+		if sede == nil || *sede != id {
+			continue
+		}
+		local := sedes[partido.EquipoLocalID]
+		agenda.Partidos = append(agenda.Partidos, PartidoAgenda{
+			Partido:    partido,
+			Neutral:    local == nil || *local != id,
+			Conflictos: []uint{},
+		})
+	}
+
+	// Los partidos vienen ordenados por fecha, así que basta comparar cada uno con los siguientes
+	for i := range agenda.Partidos {
+		for j := i + 1; j < len(agenda.Partidos); j++ {
+			if agenda.Partidos[j].FechaHora.Sub(agenda.Partidos[i].FechaHora) >= ocupacionEstadio {
+				break
+			}
+			agenda.Partidos[i].Conflictos = append(agenda.Partidos[i].Conflictos, agenda.Partidos[j].ID)
+			agenda.Partidos[j].Conflictos = append(agenda.Partidos[j].Conflictos, agenda.Partidos[i].ID)
+		}
+	}
+	for _, partido := range agenda.Partidos {
+		if len(partido.Conflictos) > 0 {
+			agenda.Conflictos++
+		}
+	}
+
+	return agenda, nil
+}
+
+// sedesEquipos relaciona cada equipo con su estadio local
+func sedesEquipos(equipos []models.Equipo) map[uint]*uint {
+	sedes := make(map[uint]*uint, len(equipos))
+	for _, equipo := range equipos {
+		sedes[equipo.ID] = equipo.EstadioID
+	}
+	return sedes
+}
+
+// estadioDePartido devuelve el estadio donde se juega un partido: el asignado al partido
+// o, si no tiene, el del equipo local. Devuelve nil si no hay ninguno.
+func estadioDePartido(partido models.Partido, sedes map[uint]*uint) *uint {
+	if partido.EstadioID != nil {
+		return partido.EstadioID
+	}
+	return sedes[partido.EquipoLocalID]
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/database"
+	"github.com/noisk8/torneas/backend/models"
+	"gorm.io/gorm"
+)
+
+// baseDatosSQLite abre una base SQLite en memoria con DB_DRIVER=sqlite y le aplica las migraciones
+func baseDatosSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), ".env"))
+	t.Setenv("DB_DRIVER", config.DriverSQLite)
+	t.Setenv("DB_PATH", ":memory:")
+	cfg, err := config.Cargar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrador, err := database.NewMigrador(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrador.Subir(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// crear guarda los registros en la base de datos o termina la prueba
+func crear(t *testing.T, db *gorm.DB, registros ...interface{}) {
+	t.Helper()
+	for _, registro := range registros {
+		if err := db.Create(registro).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// torneoPrueba tiene tres equipos, un jugador en cada uno y una jornada con tres partidos:
+// Águilas 2-1 Búhos y Búhos 0-0 Cóndores finalizados, y Cóndores-Águilas todavía en curso
+type torneoPrueba struct {
+	aguilas, buhos, condores models.Equipo
+	delantero, volante       models.Jugador // Delantero de Águilas y volante de Búhos
+	portero                  models.Jugador // Portero de Cóndores
+	jugados                  []models.Partido
+	enCurso                  models.Partido
+}
+
+func nuevoTorneoPrueba(t *testing.T, db *gorm.DB) torneoPrueba {
+	t.Helper()
+	var torneo torneoPrueba
+	torneo.aguilas = models.Equipo{Nombre: "Águilas", NombreCorto: "AGU", Ciudad: "Bogotá"}
+	torneo.buhos = models.Equipo{Nombre: "Búhos", NombreCorto: "BUH", Ciudad: "Cali"}
+	torneo.condores = models.Equipo{Nombre: "Cóndores", NombreCorto: "CON", Ciudad: "Medellín"}
+	crear(t, db, &torneo.aguilas, &torneo.buhos, &torneo.condores)
+
+	torneo.delantero = models.Jugador{Nombre: "Ana", Apellido: "Ríos", Posicion: "Delantero", EquipoID: torneo.aguilas.ID}
+	torneo.volante = models.Jugador{Nombre: "Luis", Apellido: "Mora", Posicion: "Mediocampista", EquipoID: torneo.buhos.ID}
+	torneo.portero = models.Jugador{Nombre: "Iván", Apellido: "Paz", Posicion: "Portero", EquipoID: torneo.condores.ID}
+	crear(t, db, &torneo.delantero, &torneo.volante, &torneo.portero)
+
+	inicio := time.Date(2024, 8, 3, 15, 0, 0, 0, time.UTC)
+	jornada := models.Jornada{Numero: 1, Fecha: inicio}
+	crear(t, db, &jornada)
+
+	partido := func(local, visitante models.Equipo, golesLocal, golesVisitante int, estado string, dia int) models.Partido {
+		return models.Partido{
+			JornadaID:         jornada.ID,
+			EquipoLocalID:     local.ID,
+			EquipoVisitanteID: visitante.ID,
+			GolesLocal:        golesLocal,
+			GolesVisitante:    golesVisitante,
+			FechaHora:         inicio.AddDate(0, 0, dia),
+			Estado:            estado,
+		}
+	}
+	torneo.jugados = []models.Partido{
+		partido(torneo.aguilas, torneo.buhos, 2, 1, models.EstadoFinalizado, 0),
+		partido(torneo.buhos, torneo.condores, 0, 0, models.EstadoFinalizado, 1),
+	}
+	torneo.enCurso = partido(torneo.condores, torneo.aguilas, 3, 0, models.EstadoEnCurso, 2)
+	crear(t, db, &torneo.jugados[0], &torneo.jugados[1], &torneo.enCurso)
+
+	incidencia := func(partido models.Partido, jugador models.Jugador, tipo models.TipoIncidencia, minuto int) *models.Incidencia {
+		return &models.Incidencia{PartidoID: partido.ID, JugadorID: jugador.ID, EquipoID: jugador.EquipoID, Tipo: tipo, Minuto: minuto}
+	}
+	crear(t, db,
+		incidencia(torneo.jugados[0], torneo.delantero, models.Gol, 10),
+		incidencia(torneo.jugados[0], torneo.delantero, models.GolPenal, 55),
+		incidencia(torneo.jugados[0], torneo.volante, models.Gol, 70),
+		incidencia(torneo.jugados[0], torneo.volante, models.TarjetaAmarilla, 80),
+		incidencia(torneo.jugados[1], torneo.volante, models.Asistencia, 30),
+		incidencia(torneo.jugados[1], torneo.volante, models.Sustitucion, 60),
+		incidencia(torneo.enCurso, torneo.delantero, models.TarjetaRoja, 20),
+	)
+	return torneo
+}
+
+func TestGetTablaPosicionesSQLite(t *testing.T) {
+	db := baseDatosSQLite(t)
+	torneo := nuevoTorneoPrueba(t, db)
+
+	tabla, err := NewEquipoService(db).GetTablaPosiciones(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperadas := []struct {
+		nombre                      string
+		pj, pg, pe, pp, gf, gc, pts int
+		ultimos                     string
+	}{
+		{torneo.aguilas.Nombre, 1, 1, 0, 0, 2, 1, 3, "V"},
+		{torneo.condores.Nombre, 1, 0, 1, 0, 0, 0, 1, "E"},
+		{torneo.buhos.Nombre, 2, 0, 1, 1, 1, 2, 1, "DE"},
+	}
+	if len(tabla) != len(esperadas) {
+		t.Fatalf("la tabla tiene %d equipos; se esperaban %d", len(tabla), len(esperadas))
+	}
+	for i, esperada := range esperadas {
+		equipo := tabla[i]
+		obtenida := []int{equipo.PJ, equipo.PG, equipo.PE, equipo.PP, equipo.GF, equipo.GC, equipo.Puntos}
+		deseada := []int{esperada.pj, esperada.pg, esperada.pe, esperada.pp, esperada.gf, esperada.gc, esperada.pts}
+		if equipo.Nombre != esperada.nombre || equipo.Posicion != i+1 || equipo.UltimosJuegos != esperada.ultimos {
+			t.Errorf("posición %d: %s (%d) con %q; se esperaba %s con %q",
+				i+1, equipo.Nombre, equipo.Posicion, equipo.UltimosJuegos, esperada.nombre, esperada.ultimos)
+		}
+		for j := range obtenida {
+			if obtenida[j] != deseada[j] {
+				t.Errorf("%s: PJ PG PE PP GF GC Pts = %v; se esperaba %v", equipo.Nombre, obtenida, deseada)
+				break
+			}
+		}
+	}
+}
+
+func TestGetTablaGoleadoresSQLite(t *testing.T) {
+	db := baseDatosSQLite(t)
+	torneo := nuevoTorneoPrueba(t, db)
+
+	goleadores, err := NewJugadorService(db).GetTablaGoleadores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goleadores) != 3 {
+		t.Fatalf("la tabla tiene %d jugadores; se esperaban 3", len(goleadores))
+	}
+	esperados := []struct {
+		id                 uint
+		goles, asistencias int
+	}{
+		{torneo.delantero.ID, 2, 0},
+		{torneo.volante.ID, 1, 1},
+		{torneo.portero.ID, 0, 0},
+	}
+	for i, esperado := range esperados {
+		jugador := goleadores[i]
+		if jugador.ID != esperado.id || jugador.Goles != esperado.goles || jugador.Asistencias != esperado.asistencias {
+			t.Errorf("puesto %d: jugador %d con %d goles y %d asistencias; se esperaba %d con %d y %d",
+				i+1, jugador.ID, jugador.Goles, jugador.Asistencias, esperado.id, esperado.goles, esperado.asistencias)
+		}
+	}
+}
+
+func TestGetEstadisticasJugadorSQLite(t *testing.T) {
+	db := baseDatosSQLite(t)
+	torneo := nuevoTorneoPrueba(t, db)
+	service := NewJugadorService(db)
+
+	volante, err := service.GetEstadisticasJugador(torneo.volante.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volante.Goles != 1 || volante.Asistencias != 1 || volante.TarjetasAmarillas != 1 || volante.PartidosJugados != 2 {
+		t.Errorf("volante: %d goles, %d asistencias, %d amarillas, %d partidos; se esperaba 1, 1, 1 y 2",
+			volante.Goles, volante.Asistencias, volante.TarjetasAmarillas, volante.PartidosJugados)
+	}
+
+	// Después de un traspaso, los partidos anteriores siguen contando para su equipo de entonces
+	if err := db.Model(&torneo.volante).Update("equipo_id", torneo.condores.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	registro, err := service.GetPartidosJugador(torneo.volante.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registro.Partidos) != 2 {
+		t.Fatalf("el volante tiene %d partidos; se esperaban 2", len(registro.Partidos))
+	}
+	visita, local := registro.Partidos[0], registro.Partidos[1]
+	if visita.Local || visita.Rival != torneo.aguilas.Nombre || visita.Resultado != "D" || visita.Minutos != 90 {
+		t.Errorf("primer partido: local=%v contra %s, %q, %d minutos; se esperaba de visita contra Águilas, D y 90",
+			visita.Local, visita.Rival, visita.Resultado, visita.Minutos)
+	}
+	if !local.Local || local.Rival != torneo.condores.Nombre || local.Resultado != "E" || local.Minutos != 60 {
+		t.Errorf("segundo partido: local=%v contra %s, %q, %d minutos; se esperaba de local contra Cóndores, E y 60",
+			local.Local, local.Rival, local.Resultado, local.Minutos)
+	}
+	if registro.Local.Minutos != 60 || registro.Visitante.Minutos != 90 {
+		t.Errorf("minutos de local y de visita = %d y %d; se esperaba 60 y 90", registro.Local.Minutos, registro.Visitante.Minutos)
+	}
+
+	// Los minutos de un partido sin terminar no cuentan
+	delantero, err := service.GetPartidosJugador(torneo.delantero.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delantero.Visitante.PJ != 1 || delantero.Visitante.Minutos != 0 || delantero.Local.Minutos != 90 {
+		t.Errorf("delantero: %d partidos de visita con %d minutos y %d minutos de local; se esperaba 1, 0 y 90",
+			delantero.Visitante.PJ, delantero.Visitante.Minutos, delantero.Local.Minutos)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+// Tipos de eventos que se emiten durante un partido
+const (
+	EventoEstado         = "estado"
+	EventoMarcador       = "marcador"
+	EventoIncidencia     = "incidencia"
+	EventoReprogramacion = "reprogramacion"
+)
+
+// EventoPartido representa un cambio ocurrido en un partido
+type EventoPartido struct {
+	Tipo           string                 `json:"tipo"`
+	PartidoID      uint                   `json:"partidoId"`
+	JornadaID      uint                   `json:"jornadaId"`
+	Secuencia      uint                   `json:"secuencia"`
+	Estado         string                 `json:"estado"`
+	Periodo        string                 `json:"periodo"`
+	Reloj          RelojPartido           `json:"reloj"`
+	GolesLocal     int                    `json:"golesLocal"`
+	GolesVisitante int                    `json:"golesVisitante"`
+	Incidencia     *models.Incidencia     `json:"incidencia,omitempty"`
+	Reprogramacion *models.Reprogramacion `json:"reprogramacion,omitempty"`
+	Timestamp      time.Time              `json:"timestamp"`
+}
+
+// NuevoEventoPartido crea un evento con el estado y marcador actuales del partido
+func NuevoEventoPartido(tipo string, partido models.Partido) EventoPartido {
+	return EventoPartido{
+		Tipo:           tipo,
+		PartidoID:      partido.ID,
+		JornadaID:      partido.JornadaID,
+		Secuencia:      partido.Secuencia,
+		Estado:         partido.Estado,
+		Periodo:        partido.Periodo,
+		Reloj:          CalcularReloj(partido, time.Now()),
+		GolesLocal:     partido.GolesLocal,
+		GolesVisitante: partido.GolesVisitante,
+		Timestamp:      time.Now(),
+	}
+}
+
+// DifusorEventos reparte los eventos de los partidos entre los suscriptores
+type DifusorEventos struct {
+	mu           sync.RWMutex
+	suscriptores map[chan EventoPartido]struct{}
+}
+
+// EventosEnVivo es el difusor que usan los servicios para publicar los eventos de los partidos
+var EventosEnVivo = NewDifusorEventos()
+
+// NewDifusorEventos crea un difusor sin suscriptores
+func NewDifusorEventos() *DifusorEventos {
+	return &DifusorEventos{
+		suscriptores: make(map[chan EventoPartido]struct{}),
+	}
+}
+
+// Suscribir registra un nuevo suscriptor y devuelve el canal por el que recibirá los eventos
+func (d *DifusorEventos) Suscribir() chan EventoPartido {
+	canal := make(chan EventoPartido, 16)
+
+	d.mu.Lock()
+	d.suscriptores[canal] = struct{}{}
+	d.mu.Unlock()
+
+	return canal
+}
+
+// Cancelar elimina un suscriptor y cierra su canal
+func (d *DifusorEventos) Cancelar(canal chan EventoPartido) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.suscriptores[canal]; ok {
+		delete(d.suscriptores, canal)
+		close(canal)
+	}
+}
+
+// Publicar envía un evento a todos los suscriptores.
+// Si un suscriptor no consume sus eventos a tiempo, el evento se descarta para él.
+func (d *DifusorEventos) Publicar(evento EventoPartido) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for canal := range d.suscriptores {
+		select {
+		case canal <- evento:
+		default:
+		}
+	}
+}
@@ -0,0 +1,543 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"gorm.io/gorm"
+)
+
+// Datos que se pueden exportar
+const (
+	ExportarEstadios    = "estadios"
+	ExportarEquipos     = "equipos"
+	ExportarJugadores   = "jugadores"
+	ExportarPartidos    = "partidos"
+	ExportarIncidencias = "incidencias"
+)
+
+// TiposExportacion son los datos exportables en el orden en que se deben volver a importar
+var TiposExportacion = []string{ExportarEstadios, ExportarEquipos, ExportarJugadores, ExportarPartidos, ExportarIncidencias}
+
+// Formatos de exportación
+const (
+	FormatoCSV    = "csv"
+	FormatoNDJSON = "ndjson" // Un objeto JSON por línea
+)
+
+var (
+	// ErrTipoExportacion indica que no se pueden exportar datos del tipo indicado
+	ErrTipoExportacion = errors.New("solo se pueden exportar estadios, equipos, jugadores, partidos o incidencias")
+	// ErrFormatoExportacion indica que el formato de exportación no es csv ni ndjson
+	ErrFormatoExportacion = errors.New("el formato de exportación debe ser csv o ndjson")
+)
+
+// Registros exportados. Las columnas usan los nombres de la importación, así que los archivos
+// exportados se pueden volver a importar en ese orden. Los IDs relacionan los registros entre
+// archivos; la importación los ignora, porque identifica estadios, equipos, jugadores y
+// partidos por sus nombres, y así los archivos se pueden cargar en una base de datos con otros IDs.
+
+// EstadioExportado es una fila de la exportación de estadios
+type EstadioExportado struct {
+	ID         uint     `json:"id"`
+	Nombre     string   `json:"nombre"`
+	Ciudad     string   `json:"ciudad"`
+	Capacidad  int      `json:"capacidad"`
+	Latitud    *float64 `json:"latitud"`
+	Longitud   *float64 `json:"longitud"`
+	Superficie string   `json:"superficie"`
+}
+
+// EquipoExportado es una fila de la exportación de equipos
+type EquipoExportado struct {
+	ID          uint   `json:"id"`
+	Nombre      string `json:"nombre"`
+	NombreCorto string `json:"nombreCorto"`
+	Ciudad      string `json:"ciudad"`
+	EstadioID   *uint  `json:"estadioId"`
+	Estadio     string `json:"estadio"`
+	Fundacion   string `json:"fundacion"`
+	Escudo      string `json:"escudo"`
+}
+
+// JugadorExportado es una fila de la exportación de jugadores
+type JugadorExportado struct {
+	ID              uint     `json:"id"`
+	EquipoID        uint     `json:"equipoId"`
+	Equipo          string   `json:"equipo"`
+	Nombre          string   `json:"nombre"`
+	Apellido        string   `json:"apellido"`
+	FechaNacimiento string   `json:"fechaNacimiento"` // Año-mes-día, vacía si no se conoce
+	Nacionalidad    string   `json:"nacionalidad"`
+	Posicion        string   `json:"posicion"`
+	Numero          *int     `json:"numero"` // Nulos si no se conocen
+	Altura          *float64 `json:"altura"`
+	Peso            *float64 `json:"peso"`
+	Foto            string   `json:"foto"`
+}
+
+// PartidoExportado es una fila de la exportación de partidos
+type PartidoExportado struct {
+	ID                uint      `json:"id"`
+	JornadaID         uint      `json:"jornadaId"`
+	Jornada           int       `json:"jornada"`
+	FechaHora         time.Time `json:"fechaHora"`
+	EquipoLocalID     uint      `json:"equipoLocalId"`
+	EquipoLocal       string    `json:"equipoLocal"`
+	EquipoVisitanteID uint      `json:"equipoVisitanteId"`
+	EquipoVisitante   string    `json:"equipoVisitante"`
+	EstadioID         *uint     `json:"estadioId"` // Solo si no se juega en el estadio del equipo local
+	Estadio           string    `json:"estadio"`
+	GolesLocal        int       `json:"golesLocal"`
+	GolesVisitante    int       `json:"golesVisitante"`
+	Estado            string    `json:"estado"`
+}
+
+// IncidenciaExportada es una fila de la exportación de incidencias
+type IncidenciaExportada struct {
+	ID              uint                  `json:"id"`
+	PartidoID       uint                  `json:"partidoId"`
+	Jornada         int                   `json:"jornada"`
+	EquipoLocal     string                `json:"equipoLocal"`
+	EquipoVisitante string                `json:"equipoVisitante"`
+	JugadorID       uint                  `json:"jugadorId"`
+	Jugador         string                `json:"jugador"`
+	Equipo          string                `json:"equipo"`
+	Tipo            models.TipoIncidencia `json:"tipo"`
+	JugadorEntra    string                `json:"jugadorEntra"` // Jugador que entra en una sustitución
+	Minuto          int                   `json:"minuto"`
+	MinutoAnadido   int                   `json:"minutoAnadido"`
+	Descripcion     string                `json:"descripcion"`
+	Timestamp       time.Time             `json:"timestamp"`
+	Secuencia       uint                  `json:"secuencia"`
+}
+
+// ExportacionService exporta los datos del torneo para analizarlos o cargarlos en otra base de datos
+type ExportacionService struct {
+	repositorios.Repositorios
+}
+
+// NewExportacionService crea una nueva instancia del servicio de exportación
+func NewExportacionService(db *gorm.DB) *ExportacionService {
+	return NewExportacionServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewExportacionServiceConRepositorios crea el servicio de exportación sobre los repositorios indicados
+func NewExportacionServiceConRepositorios(repos repositorios.Repositorios) *ExportacionService {
+	return &ExportacionService{
+		Repositorios: repos,
+	}
+}
+
+// ValidarExportacion comprueba el tipo de datos y el formato antes de empezar a escribir
+func ValidarExportacion(tipo, formato string) error {
+	if !contiene(TiposExportacion, tipo) {
+		return ErrTipoExportacion
+	}
+	if formato != FormatoCSV && formato != FormatoNDJSON {
+		return ErrFormatoExportacion
+	}
+	return nil
+}
+
+// Exportar escribe en w todos los registros del tipo indicado, ordenados por ID, en formato
+// CSV con encabezado o NDJSON. Los registros se escriben a medida que se convierten, así que
+// si falla la escritura la salida queda incompleta.
+func (s *ExportacionService) Exportar(w io.Writer, tipo, formato string) error {
+	if err := ValidarExportacion(tipo, formato); err != nil {
+		return err
+	}
+
+	var escritor escritorExportacion
+	if formato == FormatoCSV {
+		escritor = &escritorCSV{csv: csv.NewWriter(w)}
+	} else {
+		escritor = &escritorNDJSON{json: json.NewEncoder(w)}
+	}
+
+	var err error
+	switch tipo {
+	case ExportarEstadios:
+		err = s.exportarEstadios(escritor)
+	case ExportarEquipos:
+		err = s.exportarEquipos(escritor)
+	case ExportarJugadores:
+		err = s.exportarJugadores(escritor)
+	case ExportarPartidos:
+		err = s.exportarPartidos(escritor)
+	case ExportarIncidencias:
+		err = s.exportarIncidencias(escritor)
+	}
+	if err != nil {
+		return err
+	}
+	return escritor.terminar()
+}
+
+// ExtensionFormato devuelve la extensión de los archivos del formato indicado
+func ExtensionFormato(formato string) string {
+	if formato == FormatoNDJSON {
+		return ".ndjson"
+	}
+	return ".csv"
+}
+
+func (s *ExportacionService) exportarEstadios(escritor escritorExportacion) error {
+	estadios, err := s.Estadios.Listar()
+	if err != nil {
+		return err
+	}
+	sort.Slice(estadios, func(i, j int) bool { return estadios[i].ID < estadios[j].ID })
+
+	if err := escritor.encabezado(EstadioExportado{}); err != nil {
+		return err
+	}
+	for _, estadio := range estadios {
+		err := escritor.escribir(EstadioExportado{
+			ID:         estadio.ID,
+			Nombre:     estadio.Nombre,
+			Ciudad:     estadio.Ciudad,
+			Capacidad:  estadio.Capacidad,
+			Latitud:    estadio.Latitud,
+			Longitud:   estadio.Longitud,
+			Superficie: estadio.Superficie,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ExportacionService) exportarEquipos(escritor escritorExportacion) error {
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return err
+	}
+	sort.Slice(equipos, func(i, j int) bool { return equipos[i].ID < equipos[j].ID })
+
+	if err := escritor.encabezado(EquipoExportado{}); err != nil {
+		return err
+	}
+	for _, equipo := range equipos {
+		err := escritor.escribir(EquipoExportado{
+			ID:          equipo.ID,
+			Nombre:      equipo.Nombre,
+			NombreCorto: equipo.NombreCorto,
+			Ciudad:      equipo.Ciudad,
+			EstadioID:   equipo.EstadioID,
+			Estadio:     equipo.Estadio,
+			Fundacion:   equipo.Fundacion,
+			Escudo:      equipo.Escudo,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ExportacionService) exportarJugadores(escritor escritorExportacion) error {
+	nombres, err := s.nombresEquipos()
+	if err != nil {
+		return err
+	}
+	jugadores, err := s.Jugadores.Listar()
+	if err != nil {
+		return err
+	}
+	sort.Slice(jugadores, func(i, j int) bool { return jugadores[i].ID < jugadores[j].ID })
+
+	if err := escritor.encabezado(JugadorExportado{}); err != nil {
+		return err
+	}
+	for _, jugador := range jugadores {
+		fechaNacimiento := ""
+		if !jugador.FechaNacimiento.IsZero() {
+			fechaNacimiento = jugador.FechaNacimiento.Format(time.DateOnly)
+		}
+		err := escritor.escribir(JugadorExportado{
+			ID:              jugador.ID,
+			EquipoID:        jugador.EquipoID,
+			Equipo:          nombres[jugador.EquipoID],
+			Nombre:          jugador.Nombre,
+			Apellido:        jugador.Apellido,
+			FechaNacimiento: fechaNacimiento,
+			Nacionalidad:    jugador.Nacionalidad,
+			Posicion:        jugador.Posicion,
+			Numero:          valorConocido(jugador.Numero),
+			Altura:          valorConocido(jugador.Altura),
+			Peso:            valorConocido(jugador.Peso),
+			Foto:            jugador.Foto,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ExportacionService) exportarPartidos(escritor escritorExportacion) error {
+	nombres, err := s.nombresEquipos()
+	if err != nil {
+		return err
+	}
+	numeros, err := s.numerosJornadas()
+	if err != nil {
+		return err
+	}
+	estadios, err := s.Estadios.Listar()
+	if err != nil {
+		return err
+	}
+	nombresEstadios := make(map[uint]string, len(estadios))
+	for _, estadio := range estadios {
+		nombresEstadios[estadio.ID] = estadio.Nombre
+	}
+	partidos, err := s.partidosPorID()
+	if err != nil {
+		return err
+	}
+
+	if err := escritor.encabezado(PartidoExportado{}); err != nil {
+		return err
+	}
+	for _, partido := range partidos {
+		estadio := ""
+		if partido.EstadioID != nil {
+			estadio = nombresEstadios[*partido.EstadioID]
+		}
+		err := escritor.escribir(PartidoExportado{
+			ID:                partido.ID,
+			JornadaID:         partido.JornadaID,
+			Jornada:           numeros[partido.JornadaID],
+			FechaHora:         partido.FechaHora,
+			EquipoLocalID:     partido.EquipoLocalID,
+			EquipoLocal:       nombres[partido.EquipoLocalID],
+			EquipoVisitanteID: partido.EquipoVisitanteID,
+			EquipoVisitante:   nombres[partido.EquipoVisitanteID],
+			EstadioID:         partido.EstadioID,
+			Estadio:           estadio,
+			GolesLocal:        partido.GolesLocal,
+			GolesVisitante:    partido.GolesVisitante,
+			Estado:            partido.Estado,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ExportacionService) exportarIncidencias(escritor escritorExportacion) error {
+	nombres, err := s.nombresEquipos()
+	if err != nil {
+		return err
+	}
+	numeros, err := s.numerosJornadas()
+	if err != nil {
+		return err
+	}
+	partidos, err := s.partidosPorID()
+	if err != nil {
+		return err
+	}
+	porID := make(map[uint]models.Partido, len(partidos))
+	for _, partido := range partidos {
+		porID[partido.ID] = partido
+	}
+	incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{ConJugador: true})
+	if err != nil {
+		return err
+	}
+	sort.Slice(incidencias, func(i, j int) bool { return incidencias[i].ID < incidencias[j].ID })
+
+	// Los jugadores que entran en las sustituciones no vienen con la incidencia
+	jugadores, err := s.Jugadores.Listar()
+	if err != nil {
+		return err
+	}
+	nombresJugadores := make(map[uint]string, len(jugadores))
+	for _, jugador := range jugadores {
+		nombresJugadores[jugador.ID] = strings.TrimSpace(jugador.Nombre + " " + jugador.Apellido)
+	}
+
+	if err := escritor.encabezado(IncidenciaExportada{}); err != nil {
+		return err
+	}
+	for _, incidencia := range incidencias {
+		partido := porID[incidencia.PartidoID]
+		err := escritor.escribir(IncidenciaExportada{
+			ID:              incidencia.ID,
+			PartidoID:       incidencia.PartidoID,
+			Jornada:         numeros[partido.JornadaID],
+			EquipoLocal:     nombres[partido.EquipoLocalID],
+			EquipoVisitante: nombres[partido.EquipoVisitanteID],
+			JugadorID:       incidencia.JugadorID,
+			Jugador:         strings.TrimSpace(incidencia.Jugador.Nombre + " " + incidencia.Jugador.Apellido),
+			Equipo:          nombres[equipoIncidencia(incidencia)],
+			Tipo:            incidencia.Tipo,
+			JugadorEntra:    nombresJugadores[jugadorEntra(incidencia)],
+			Minuto:          incidencia.Minuto,
+			MinutoAnadido:   incidencia.MinutoAnadido,
+			Descripcion:     incidencia.Descripcion,
+			Timestamp:       incidencia.Timestamp,
+			Secuencia:       incidencia.Secuencia,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valorConocido devuelve nil si el valor es cero, que en los jugadores significa que no se conoce
+func valorConocido[T int | float64](valor T) *T {
+	if valor == 0 {
+		return nil
+	}
+	return &valor
+}
+
+// nombresEquipos devuelve el nombre de cada equipo por su ID
+func (s *ExportacionService) nombresEquipos() (map[uint]string, error) {
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	nombres := make(map[uint]string, len(equipos))
+	for _, equipo := range equipos {
+		nombres[equipo.ID] = equipo.Nombre
+	}
+	return nombres, nil
+}
+
+// equipoIncidencia devuelve el equipo con el que jugaba el protagonista de la incidencia. Las
+// incidencias registradas antes de guardar el equipo usan el equipo actual del jugador.
+func equipoIncidencia(incidencia models.Incidencia) uint {
+	if incidencia.EquipoID != 0 {
+		return incidencia.EquipoID
+	}
+	return incidencia.Jugador.EquipoID
+}
+
+// jugadorEntra devuelve el jugador que entra en una sustitución, o 0 si no se registró
+func jugadorEntra(incidencia models.Incidencia) uint {
+	if incidencia.JugadorEntraID == nil {
+		return 0
+	}
+	return *incidencia.JugadorEntraID
+}
+
+// numerosJornadas devuelve el número de cada jornada por su ID
+func (s *ExportacionService) numerosJornadas() (map[uint]int, error) {
+	jornadas, err := s.Calendario.ListarJornadas()
+	if err != nil {
+		return nil, err
+	}
+	numeros := make(map[uint]int, len(jornadas))
+	for _, jornada := range jornadas {
+		numeros[jornada.ID] = jornada.Numero
+	}
+	return numeros, nil
+}
+
+// partidosPorID devuelve todos los partidos ordenados por ID
+func (s *ExportacionService) partidosPorID() ([]models.Partido, error) {
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(partidos, func(i, j int) bool { return partidos[i].ID < partidos[j].ID })
+	return partidos, nil
+}
+
+// escritorExportacion escribe los registros exportados en un formato
+type escritorExportacion interface {
+	// encabezado recibe un registro vacío del tipo que se va a exportar
+	encabezado(registro any) error
+	escribir(registro any) error
+	terminar() error
+}
+
+// escritorCSV escribe una columna por cada campo del registro, con su nombre JSON en el encabezado
+type escritorCSV struct {
+	csv *csv.Writer
+}
+
+func (e *escritorCSV) encabezado(registro any) error {
+	tipo := reflect.TypeOf(registro)
+	columnas := make([]string, tipo.NumField())
+	for i := range columnas {
+		columnas[i] = strings.SplitN(tipo.Field(i).Tag.Get("json"), ",", 2)[0]
+	}
+	return e.csv.Write(columnas)
+}
+
+func (e *escritorCSV) escribir(registro any) error {
+	valor := reflect.ValueOf(registro)
+	celdas := make([]string, valor.NumField())
+	for i := range celdas {
+		celdas[i] = celdaCSV(valor.Field(i))
+	}
+	return e.csv.Write(celdas)
+}
+
+func (e *escritorCSV) terminar() error {
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// celdaCSV convierte un campo en texto; los punteros nulos y las fechas vacías quedan vacíos
+func celdaCSV(valor reflect.Value) string {
+	if valor.Kind() == reflect.Pointer {
+		if valor.IsNil() {
+			return ""
+		}
+		valor = valor.Elem()
+	}
+	if fecha, ok := valor.Interface().(time.Time); ok {
+		if fecha.IsZero() {
+			return ""
+		}
+		return fecha.UTC().Format(time.RFC3339)
+	}
+	switch valor.Kind() {
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(valor.Int(), 10)
+	case reflect.Uint, reflect.Uint64:
+		return strconv.FormatUint(valor.Uint(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(valor.Float(), 'f', -1, 64)
+	case reflect.String:
+		return valor.String()
+	}
+	return fmt.Sprint(valor.Interface())
+}
+
+// escritorNDJSON escribe cada registro como un objeto JSON en su propia línea
+type escritorNDJSON struct {
+	json *json.Encoder
+}
+
+func (e *escritorNDJSON) encabezado(registro any) error {
+	return nil
+}
+
+func (e *escritorNDJSON) escribir(registro any) error {
+	return e.json.Encode(registro)
+}
+
+func (e *escritorNDJSON) terminar() error {
+	return nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+func TestExportarEImportarEnOtraBaseDeDatos(t *testing.T) {
+	origen := repositorios.NewEnMemoria()
+	latitud, longitud := 4.6463, -74.0775
+	propio := models.Estadio{Nombre: "El Campín", Ciudad: "Bogotá", Capacidad: 36343, Latitud: &latitud, Longitud: &longitud, Superficie: models.SuperficieNatural}
+	neutral := models.Estadio{Nombre: "Atanasio Girardot", Ciudad: "Medellín", Capacidad: 40043}
+	for _, estadio := range []*models.Estadio{&propio, &neutral} {
+		if err := origen.Estadios.Crear(estadio); err != nil {
+			t.Fatal(err)
+		}
+	}
+	local := models.Equipo{Nombre: "Águilas", NombreCorto: "AGU", Ciudad: "Bogotá", Estadio: propio.Nombre, EstadioID: &propio.ID, Fundacion: "1950", Escudo: "aguilas.png"}
+	visitante := models.Equipo{Nombre: "Búhos", NombreCorto: "BUH", Ciudad: "Cali", Estadio: "Pascual Guerrero", Fundacion: "1960", Escudo: "buhos.png"}
+	for _, equipo := range []*models.Equipo{&local, &visitante} {
+		if err := origen.Equipos.Crear(equipo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delantero := models.Jugador{Nombre: "Ana", Apellido: "Ríos", Numero: 9, EquipoID: local.ID}
+	if err := origen.Jugadores.Crear(&delantero); err != nil {
+		t.Fatal(err)
+	}
+	jornada := models.Jornada{Numero: 1, Fecha: time.Date(2024, 8, 3, 0, 0, 0, 0, time.UTC)}
+	if err := origen.Calendario.CrearJornada(&jornada); err != nil {
+		t.Fatal(err)
+	}
+	// El partido se juega en un estadio neutral
+	partido := models.Partido{
+		JornadaID:         jornada.ID,
+		EquipoLocalID:     local.ID,
+		EquipoVisitanteID: visitante.ID,
+		EstadioID:         &neutral.ID,
+		FechaHora:         time.Date(2024, 8, 3, 20, 0, 0, 0, time.UTC),
+		GolesLocal:        1,
+		Estado:            models.EstadoFinalizado,
+	}
+	if err := origen.Calendario.CrearPartido(&partido); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCalendarioServiceConRepositorios(origen).RegistrarIncidencia(models.Incidencia{PartidoID: partido.ID, JugadorID: delantero.ID, Tipo: models.Gol, Minuto: 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cada archivo exportado se importa en una base de datos vacía en el orden de la exportación
+	destino := repositorios.NewEnMemoria()
+	exportacion := NewExportacionServiceConRepositorios(origen)
+	importacion := NewImportacionServiceConRepositorios(destino)
+	for _, tipo := range TiposExportacion {
+		var archivo bytes.Buffer
+		if err := exportacion.Exportar(&archivo, tipo, FormatoCSV); err != nil {
+			t.Fatal(err)
+		}
+		filas, err := LeerTabla(tipo+".csv", &archivo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resultado, err := importacion.Importar(tipo, filas, false)
+		if err != nil {
+			t.Fatalf("importar %s: %v %+v", tipo, err, resultado.Errores)
+		}
+		if resultado.Creados != resultado.Filas {
+			t.Errorf("importar %s: %d creados de %d filas", tipo, resultado.Creados, resultado.Filas)
+		}
+	}
+
+	estadios, err := destino.Estadios.Listar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nombresEstadios := make(map[uint]string)
+	for _, estadio := range estadios {
+		nombresEstadios[estadio.ID] = estadio.Nombre
+		if estadio.Nombre == propio.Nombre && (estadio.Capacidad != propio.Capacidad || estadio.Latitud == nil || *estadio.Latitud != latitud || estadio.Superficie != propio.Superficie) {
+			t.Errorf("estadio importado %+v; se esperaba %+v", estadio, propio)
+		}
+	}
+	equipos, err := destino.Equipos.Listar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, importado := range equipos {
+		if importado.Nombre == local.Nombre && (importado.EstadioID == nil || nombresEstadios[*importado.EstadioID] != propio.Nombre) {
+			t.Errorf("%s no quedó asociado a su estadio", importado.Nombre)
+		}
+	}
+	partidos, err := destino.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partidos) != 1 || partidos[0].EstadioID == nil || nombresEstadios[*partidos[0].EstadioID] != neutral.Nombre {
+		t.Fatalf("partidos importados %+v; se esperaba uno en %s", partidos, neutral.Nombre)
+	}
+	incidencias, err := destino.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidencias) != 1 || incidencias[0].Tipo != models.Gol || incidencias[0].Minuto != 30 {
+		t.Errorf("incidencias importadas %+v; se esperaba el gol del minuto 30", incidencias)
+	}
+}
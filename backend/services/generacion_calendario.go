@@ -0,0 +1,402 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+// Acciones que la generación del calendario aplica sobre cada jornada
+const (
+	AccionCrear     = "crear"     // La jornada no existía y se crea con sus partidos
+	AccionRegenerar = "regenerar" // La jornada no se ha jugado y sus partidos se reemplazan
+	AccionConservar = "conservar" // La jornada queda como está
+	AccionEliminar  = "eliminar"  // La jornada no se ha jugado y sobra en el nuevo calendario
+)
+
+// horaPartido es la hora del día a la que se programan los partidos generados
+const horaPartido = 15 * time.Hour
+
+// ErrEquiposInsuficientes indica que no hay equipos suficientes para generar un calendario
+var ErrEquiposInsuficientes = errors.New("se necesitan al menos dos equipos para generar el calendario")
+
+// OpcionesCalendario configura la generación del calendario
+type OpcionesCalendario struct {
+	// FechaInicio es la fecha de la primera jornada. Si es nil se mantiene la de la
+	// jornada 1 existente o, si no hay calendario, se usa el próximo sábado.
+	FechaInicio *time.Time
+	// Regenerar reemplaza las jornadas que aún no se han jugado. Sin esta opción
+	// las jornadas existentes se conservan y solo se crean las que faltan.
+	Regenerar bool
+	// Simular calcula el plan sin guardar ningún cambio
+	Simular bool
+}
+
+// JornadaPlanificada describe lo que la generación hace con una jornada
+type JornadaPlanificada struct {
+	Numero   int              `json:"numero"`
+	Fecha    time.Time        `json:"fecha"`
+	Accion   string           `json:"accion"`
+	Partidos []models.Partido `json:"partidos"`
+	jornada  *models.Jornada  // Jornada existente, si la hay
+	actuales []models.Partido // Partidos que ya tiene la jornada existente
+}
+
+// PlanCalendario es el resultado de generar el calendario. En una simulación
+// describe los cambios que se harían sin haberlos guardado.
+type PlanCalendario struct {
+	Simulacion  bool                 `json:"simulacion"`
+	Creadas     int                  `json:"creadas"`
+	Regeneradas int                  `json:"regeneradas"`
+	Conservadas int                  `json:"conservadas"`
+	Eliminadas  int                  `json:"eliminadas"`
+	Jornadas    []JornadaPlanificada `json:"jornadas"`
+}
+
+// GenerarCalendario genera un calendario de ida y vuelta con todos los equipos del torneo.
+// Todos los cambios se guardan en una sola transacción, de modo que un fallo no deja un
+// calendario a medias. Las jornadas que ya se han jugado nunca se modifican, y volver a
+// generar un calendario que no cambió no hace ninguna escritura.
+func (s *CalendarioService) GenerarCalendario(opciones OpcionesCalendario) (PlanCalendario, error) {
+	plan := PlanCalendario{Simulacion: opciones.Simular, Jornadas: []JornadaPlanificada{}}
+
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return plan, err
+	}
+	if len(equipos) < 2 {
+		return plan, ErrEquiposInsuficientes
+	}
+
+	err = s.Calendario.Transaccion(func(tx repositorios.CalendarioRepositorio) error {
+		var err error
+		if plan.Jornadas, err = planificarJornadas(tx, equipos, opciones); err != nil {
+			return err
+		}
+		if !opciones.Simular {
+			if err := aplicarPlan(tx, plan.Jornadas); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	for _, jornada := range plan.Jornadas {
+		switch jornada.Accion {
+		case AccionCrear:
+			plan.Creadas++
+		case AccionRegenerar:
+			plan.Regeneradas++
+		case AccionConservar:
+			plan.Conservadas++
+		case AccionEliminar:
+			plan.Eliminadas++
+		}
+	}
+	return plan, nil
+}
+
+// planificarJornadas compara el calendario generado con las jornadas guardadas y decide
+// qué hacer con cada una
+func planificarJornadas(repo repositorios.CalendarioRepositorio, equipos []models.Equipo, opciones OpcionesCalendario) ([]JornadaPlanificada, error) {
+	existentes, err := repo.ListarJornadas()
+	if err != nil {
+		return nil, err
+	}
+	porNumero := make(map[int]models.Jornada, len(existentes))
+	for _, jornada := range existentes {
+		porNumero[jornada.Numero] = jornada
+	}
+
+	fechaInicio := proximoSabado(time.Now())
+	if opciones.FechaInicio != nil {
+		fechaInicio = inicioDelDia(*opciones.FechaInicio)
+	} else if primera, ok := porNumero[1]; ok {
+		fechaInicio = primera.Fecha
+	}
+
+	rondas := emparejarTodosContraTodos(equipos)
+	jornadas := make([]JornadaPlanificada, 0, len(rondas))
+	var anterior time.Time // Fecha de la jornada anterior, tal como queda en el plan
+	for i, ronda := range rondas {
+		// Cada jornada una semana después. Si la anterior se conserva en una fecha posterior
+		// a la del plan, las siguientes se corren para que vayan después de ella.
+		fecha := fechaInicio.AddDate(0, 0, i*7)
+		if !anterior.IsZero() && fecha.Before(anterior.AddDate(0, 0, 7)) {
+			fecha = anterior.AddDate(0, 0, 7)
+		}
+		planificada := JornadaPlanificada{
+			Numero: i + 1,
+			Fecha:  fecha,
+			Accion: AccionCrear,
+		}
+		for _, cruce := range ronda {
+			planificada.Partidos = append(planificada.Partidos, models.Partido{
+				EquipoLocalID:     cruce[0],
+				EquipoVisitanteID: cruce[1],
+				FechaHora:         planificada.Fecha.Add(horaPartido),
+				Estado:            models.EstadoPendiente,
+			})
+		}
+
+		if existente, ok := porNumero[planificada.Numero]; ok {
+			delete(porNumero, planificada.Numero)
+			if err := compararJornada(repo, existente, &planificada, opciones.Regenerar); err != nil {
+				return nil, err
+			}
+		}
+		anterior = planificada.Fecha
+		jornadas = append(jornadas, planificada)
+	}
+
+	// Jornadas que sobran, por ejemplo porque ahora hay menos equipos
+	sobrantes := make([]models.Jornada, 0, len(porNumero))
+	for _, jornada := range porNumero {
+		sobrantes = append(sobrantes, jornada)
+	}
+	sort.Slice(sobrantes, func(i, j int) bool { return sobrantes[i].Numero < sobrantes[j].Numero })
+	for i := range sobrantes {
+		partidos, jugada, err := jornadaJugada(repo, sobrantes[i])
+		if err != nil {
+			return nil, err
+		}
+		planificada := JornadaPlanificada{
+			Numero:   sobrantes[i].Numero,
+			Fecha:    sobrantes[i].Fecha,
+			Accion:   AccionConservar,
+			Partidos: partidos,
+			jornada:  &sobrantes[i],
+		}
+		if opciones.Regenerar && !jugada {
+			planificada.Accion = AccionEliminar
+		}
+		jornadas = append(jornadas, planificada)
+	}
+
+	descartarCrucesConservados(jornadas)
+	return jornadas, nil
+}
+
+// descartarCrucesConservados quita de las jornadas que se van a crear o regenerar los cruces
+// que ya están en una jornada conservada, por ejemplo un partido reprogramado a otra jornada.
+// Si después de eso una jornada regenerada queda igual que antes, se conserva.
+func descartarCrucesConservados(jornadas []JornadaPlanificada) {
+	conservados := make(map[[2]uint]bool)
+	for _, jornada := range jornadas {
+		if jornada.Accion == AccionConservar {
+			for _, partido := range jornada.Partidos {
+				conservados[[2]uint{partido.EquipoLocalID, partido.EquipoVisitanteID}] = true
+			}
+		}
+	}
+
+	for i := range jornadas {
+		planificada := &jornadas[i]
+		if planificada.Accion != AccionCrear && planificada.Accion != AccionRegenerar {
+			continue
+		}
+
+		partidos := planificada.Partidos[:0]
+		for _, partido := range planificada.Partidos {
+			if !conservados[[2]uint{partido.EquipoLocalID, partido.EquipoVisitanteID}] {
+				partidos = append(partidos, partido)
+			}
+		}
+		planificada.Partidos = partidos
+
+		if planificada.Accion == AccionRegenerar && len(planificada.actuales) > 0 &&
+			planificada.jornada.Fecha.Equal(planificada.Fecha) && mismosPartidos(planificada.actuales, planificada.Partidos) {
+			planificada.Accion = AccionConservar
+			planificada.Partidos = planificada.actuales
+		}
+	}
+}
+
+// compararJornada decide si una jornada existente se conserva o se regenera con los
+// partidos planificados
+func compararJornada(repo repositorios.CalendarioRepositorio, existente models.Jornada, planificada *JornadaPlanificada, regenerar bool) error {
+	partidos, jugada, err := jornadaJugada(repo, existente)
+	if err != nil {
+		return err
+	}
+	planificada.jornada = &existente
+
+	// Una jornada vacía, por ejemplo de una generación anterior incompleta, siempre se completa.
+	// Si la regenerada queda igual que la actual, descartarCrucesConservados la conserva.
+	if jugada || !regenerar && len(partidos) > 0 {
+		planificada.Accion = AccionConservar
+		planificada.Fecha = existente.Fecha
+		planificada.Partidos = partidos
+		return nil
+	}
+	planificada.Accion = AccionRegenerar
+	planificada.actuales = partidos
+	return nil
+}
+
+// jornadaJugada obtiene los partidos de una jornada e indica si ya se jugó o se ajustó algo de
+// ella: una jornada completada, un partido que no está pendiente, un partido con incidencias o
+// un partido reprogramado a mano
+func jornadaJugada(repo repositorios.CalendarioRepositorio, jornada models.Jornada) ([]models.Partido, bool, error) {
+	partidos, err := repo.BuscarPartidos(repositorios.FiltroPartidos{JornadaID: jornada.ID})
+	if err != nil {
+		return nil, false, err
+	}
+	if jornada.Completada {
+		return partidos, true, nil
+	}
+
+	ids := make([]uint, 0, len(partidos))
+	for _, partido := range partidos {
+		if partido.Estado != models.EstadoPendiente {
+			return partidos, true, nil
+		}
+		ids = append(ids, partido.ID)
+	}
+	if len(ids) == 0 {
+		return partidos, false, nil
+	}
+
+	incidencias, err := repo.BuscarIncidencias(repositorios.FiltroIncidencias{PartidoIDs: ids})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(incidencias) > 0 {
+		return partidos, true, nil
+	}
+
+	reprogramaciones, err := repo.BuscarReprogramaciones(ids)
+	if err != nil {
+		return nil, false, err
+	}
+	return partidos, len(reprogramaciones) > 0, nil
+}
+
+// mismosPartidos indica si dos listas de partidos tienen los mismos cruces a la misma hora
+func mismosPartidos(a, b []models.Partido) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	pendientes := make(map[[2]uint]time.Time, len(a))
+	for _, partido := range a {
+		pendientes[[2]uint{partido.EquipoLocalID, partido.EquipoVisitanteID}] = partido.FechaHora
+	}
+	for _, partido := range b {
+		fecha, ok := pendientes[[2]uint{partido.EquipoLocalID, partido.EquipoVisitanteID}]
+		if !ok || !fecha.Equal(partido.FechaHora) {
+			return false
+		}
+	}
+	return true
+}
+
+// aplicarPlan guarda las jornadas y partidos planificados
+func aplicarPlan(repo repositorios.CalendarioRepositorio, jornadas []JornadaPlanificada) error {
+	for i := range jornadas {
+		planificada := &jornadas[i]
+		switch planificada.Accion {
+		case AccionCrear:
+			jornada := models.Jornada{Numero: planificada.Numero, Fecha: planificada.Fecha}
+			if err := repo.CrearJornada(&jornada); err != nil {
+				return err
+			}
+			planificada.jornada = &jornada
+		case AccionRegenerar:
+			if err := repo.EliminarPartidosDeJornada(planificada.jornada.ID); err != nil {
+				return err
+			}
+			if !planificada.jornada.Fecha.Equal(planificada.Fecha) {
+				planificada.jornada.Fecha = planificada.Fecha
+				if err := repo.ActualizarJornada(planificada.jornada); err != nil {
+					return err
+				}
+			}
+		case AccionEliminar:
+			if err := repo.EliminarPartidosDeJornada(planificada.jornada.ID); err != nil {
+				return err
+			}
+			if err := repo.EliminarJornada(planificada.jornada.ID); err != nil {
+				return err
+			}
+			continue
+		default:
+			continue
+		}
+
+		for j := range planificada.Partidos {
+			planificada.Partidos[j].JornadaID = planificada.jornada.ID
+			if err := repo.CrearPartido(&planificada.Partidos[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// emparejarTodosContraTodos genera las rondas de un torneo de ida y vuelta con el método
+// del círculo: cada equipo juega una vez por ronda contra todos los demás y en la segunda
+// vuelta se invierten las localías. Con un número impar de equipos, cada ronda descansa uno.
+// Devuelve por cada ronda los pares [local, visitante].
+func emparejarTodosContraTodos(equipos []models.Equipo) [][][2]uint {
+	ids := make([]uint, 0, len(equipos)+1)
+	for _, equipo := range equipos {
+		ids = append(ids, equipo.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids)%2 != 0 {
+		ids = append(ids, 0) // El rival 0 significa descanso
+	}
+
+	n := len(ids)
+	ida := make([][][2]uint, 0, n-1)
+	for ronda := 0; ronda < n-1; ronda++ {
+		cruces := make([][2]uint, 0, n/2)
+		for i := 0; i < n/2; i++ {
+			local, visitante := ids[i], ids[n-1-i]
+			// Alternar la localía del equipo fijo para que no juegue siempre en casa
+			if i == 0 && ronda%2 == 1 {
+				local, visitante = visitante, local
+			}
+			if local != 0 && visitante != 0 {
+				cruces = append(cruces, [2]uint{local, visitante})
+			}
+		}
+		ida = append(ida, cruces)
+
+		// Rotar todos los equipos menos el primero
+		ultimo := ids[n-1]
+		copy(ids[2:], ids[1:n-1])
+		ids[1] = ultimo
+	}
+
+	rondas := make([][][2]uint, 0, 2*len(ida))
+	rondas = append(rondas, ida...)
+	for _, cruces := range ida {
+		vuelta := make([][2]uint, len(cruces))
+		for i, cruce := range cruces {
+			vuelta[i] = [2]uint{cruce[1], cruce[0]}
+		}
+		rondas = append(rondas, vuelta)
+	}
+	return rondas
+}
+
+// inicioDelDia devuelve la medianoche del día de fecha en su misma zona horaria
+func inicioDelDia(fecha time.Time) time.Time {
+	return time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, fecha.Location())
+}
+
+// proximoSabado devuelve el inicio del primer sábado a partir de fecha, incluida
+func proximoSabado(fecha time.Time) time.Time {
+	fecha = inicioDelDia(fecha)
+	for fecha.Weekday() != time.Saturday {
+		fecha = fecha.AddDate(0, 0, 1)
+	}
+	return fecha
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+func TestRegenerarCalendarioDespuesDeJornadaConservada(t *testing.T) {
+	repos := repositorios.NewEnMemoria()
+	for _, nombre := range []string{"Águilas", "Búhos", "Cóndores", "Delfines"} {
+		if err := repos.Equipos.Crear(&models.Equipo{Nombre: nombre}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewCalendarioServiceConRepositorios(repos)
+	inicio := time.Date(2024, 8, 3, 0, 0, 0, 0, time.UTC)
+	if _, err := service.GenerarCalendario(OpcionesCalendario{FechaInicio: &inicio}); err != nil {
+		t.Fatal(err)
+	}
+
+	// La jornada 2 se jugó dos semanas después de lo previsto
+	jornada, err := repos.Calendario.ObtenerJornadaPorNumero(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jornada.Fecha = jornada.Fecha.AddDate(0, 0, 14)
+	jornada.Completada = true
+	if err := repos.Calendario.ActualizarJornada(&jornada); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := service.GenerarCalendario(OpcionesCalendario{FechaInicio: &inicio, Regenerar: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var anterior time.Time
+	for _, planificada := range plan.Jornadas {
+		if planificada.Numero == 2 && (planificada.Accion != AccionConservar || !planificada.Fecha.Equal(jornada.Fecha)) {
+			t.Errorf("jornada 2: %s el %s; se esperaba conservarla el %s", planificada.Accion, planificada.Fecha, jornada.Fecha)
+		}
+		if !anterior.IsZero() && planificada.Fecha.Before(anterior.AddDate(0, 0, 7)) {
+			t.Errorf("jornada %d (%s) el %s, menos de una semana después de la anterior (%s)",
+				planificada.Numero, planificada.Accion, planificada.Fecha.Format(time.DateOnly), anterior.Format(time.DateOnly))
+		}
+		anterior = planificada.Fecha
+	}
+	if plan.Regeneradas == 0 {
+		t.Error("no se regeneró ninguna jornada")
+	}
+}
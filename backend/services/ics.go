@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+// duracionEventoICS es la duración con la que se publica cada partido en el calendario:
+// dos tiempos, el descanso y un margen para el tiempo añadido
+const duracionEventoICS = 2 * time.Hour
+
+// formatoFechaICS es el formato de fecha y hora UTC de iCalendar (RFC 5545)
+const formatoFechaICS = "20060102T150405Z"
+
+// GetCalendarioICS genera el calendario completo del torneo en formato iCalendar
+func (s *CalendarioService) GetCalendarioICS() ([]byte, error) {
+	jornadas, err := s.GetCalendarioCompleto()
+	if err != nil {
+		return nil, err
+	}
+
+	var partidos []models.Partido
+	for _, jornada := range jornadas {
+		partidos = append(partidos, jornada.Partidos...)
+	}
+	return s.generarICS("TorNEA", partidos)
+}
+
+// GetCalendarioEquipoICS genera en formato iCalendar los partidos de un equipo
+func (s *CalendarioService) GetCalendarioEquipoICS(equipoID uint) ([]byte, error) {
+	equipo, err := s.Equipos.Obtener(equipoID)
+	if err != nil {
+		return nil, err
+	}
+
+	partidos, err := s.GetPartidosByEquipo(equipoID)
+	if err != nil {
+		return nil, err
+	}
+	return s.generarICS("TorNEA - "+equipo.Nombre, partidos)
+}
+
+// generarICS escribe un VCALENDAR con un VEVENT por partido. El UID de cada evento depende
+// solo del ID del partido, y SEQUENCE es la secuencia del partido, que aumenta con cada cambio
+// guardado, incluidos los cambios de fecha por PATCH y las reprogramaciones, para que los
+// clientes actualicen el evento en lugar de duplicarlo.
+func (s *CalendarioService) generarICS(nombre string, partidos []models.Partido) ([]byte, error) {
+	equipoIDs := make([]uint, 0, 2*len(partidos))
+	for _, partido := range partidos {
+		equipoIDs = append(equipoIDs, partido.EquipoLocalID, partido.EquipoVisitanteID)
+	}
+
+	equiposLista, err := s.Equipos.ObtenerVarios(equipoIDs)
+	if err != nil {
+		return nil, err
+	}
+	equipos := make(map[uint]models.Equipo, len(equiposLista))
+	for _, equipo := range equiposLista {
+		equipos[equipo.ID] = equipo
+	}
+
+	sedes := sedesEquipos(equiposLista)
+	estadioIDs := make([]uint, 0, len(partidos))
+	for _, partido := range partidos {
+		if sede := estadioDePartido(partido, sedes); sede != nil {
+			estadioIDs = append(estadioIDs, *sede)
+		}
+	}
+	estadiosLista, err := s.Estadios.ObtenerVarios(estadioIDs)
+	if err != nil {
+		return nil, err
+	}
+	estadios := make(map[uint]models.Estadio, len(estadiosLista))
+	for _, estadio := range estadiosLista {
+		estadios[estadio.ID] = estadio
+	}
+
+	jornadasLista, err := s.Calendario.ListarJornadas()
+	if err != nil {
+		return nil, err
+	}
+	jornadas := make(map[uint]int, len(jornadasLista))
+	for _, jornada := range jornadasLista {
+		jornadas[jornada.ID] = jornada.Numero
+	}
+
+	ahora := time.Now().UTC().Format(formatoFechaICS)
+	var ics strings.Builder
+	escribirLineaICS(&ics, "BEGIN:VCALENDAR")
+	escribirLineaICS(&ics, "VERSION:2.0")
+	escribirLineaICS(&ics, "PRODID:-//TorNEA//Calendario//ES")
+	escribirLineaICS(&ics, "CALSCALE:GREGORIAN")
+	escribirLineaICS(&ics, "METHOD:PUBLISH")
+	escribirLineaICS(&ics, "X-WR-CALNAME:"+escaparTextoICS(nombre))
+
+	for _, partido := range partidos {
+		local := nombreEquipoICS(equipos, partido.EquipoLocalID)
+		visitante := nombreEquipoICS(equipos, partido.EquipoVisitanteID)
+
+		descripcion := fmt.Sprintf("Jornada %d", jornadas[partido.JornadaID])
+		estado := "CONFIRMED"
+		switch partido.Estado {
+		case models.EstadoFinalizado:
+			descripcion += fmt.Sprintf("\nResultado final: %s %d - %d %s", local, partido.GolesLocal, partido.GolesVisitante, visitante)
+		case models.EstadoEnCurso:
+			descripcion += fmt.Sprintf("\nEn juego: %s %d - %d %s", local, partido.GolesLocal, partido.GolesVisitante, visitante)
+		case models.EstadoAplazado:
+			descripcion += "\nPartido aplazado, pendiente de nueva fecha"
+			estado = "TENTATIVE"
+		}
+
+		escribirLineaICS(&ics, "BEGIN:VEVENT")
+		escribirLineaICS(&ics, fmt.Sprintf("UID:partido-%d@tornea", partido.ID))
+		escribirLineaICS(&ics, fmt.Sprintf("SEQUENCE:%d", partido.Secuencia))
+		escribirLineaICS(&ics, "DTSTAMP:"+ahora)
+		if !partido.UpdatedAt.IsZero() {
+			escribirLineaICS(&ics, "LAST-MODIFIED:"+partido.UpdatedAt.UTC().Format(formatoFechaICS))
+		}
+		escribirLineaICS(&ics, "DTSTART:"+partido.FechaHora.UTC().Format(formatoFechaICS))
+		escribirLineaICS(&ics, "DTEND:"+partido.FechaHora.Add(duracionEventoICS).UTC().Format(formatoFechaICS))
+		escribirLineaICS(&ics, "SUMMARY:"+escaparTextoICS(local+" vs "+visitante))
+		if estadio := ubicacionICS(partido, equipos, sedes, estadios); estadio != "" {
+			escribirLineaICS(&ics, "LOCATION:"+escaparTextoICS(estadio))
+		}
+		escribirLineaICS(&ics, "DESCRIPTION:"+escaparTextoICS(descripcion))
+		escribirLineaICS(&ics, "STATUS:"+estado)
+		escribirLineaICS(&ics, "END:VEVENT")
+	}
+
+	escribirLineaICS(&ics, "END:VCALENDAR")
+	return []byte(ics.String()), nil
+}
+
+// nombreEquipoICS devuelve el nombre del equipo, o un texto genérico si ya no existe
+func nombreEquipoICS(equipos map[uint]models.Equipo, id uint) string {
+	if equipo, ok := equipos[id]; ok {
+		return equipo.Nombre
+	}
+	return fmt.Sprintf("Equipo %d", id)
+}
+
+// ubicacionICS devuelve el estadio donde se juega el partido con su ciudad. Si el partido no
+// tiene un estadio registrado se usa el nombre de estadio del equipo local.
+func ubicacionICS(partido models.Partido, equipos map[uint]models.Equipo, sedes map[uint]*uint, estadios map[uint]models.Estadio) string {
+	nombre, ciudad := "", ""
+	if sede := estadioDePartido(partido, sedes); sede != nil {
+		nombre, ciudad = estadios[*sede].Nombre, estadios[*sede].Ciudad
+	}
+	if local, ok := equipos[partido.EquipoLocalID]; ok && nombre == "" {
+		nombre, ciudad = local.Estadio, local.Ciudad
+	}
+
+	if nombre == "" || ciudad == "" {
+		return nombre
+	}
+	return nombre + ", " + ciudad
+}
+
+// escaparTextoICS escapa los caracteres especiales de un valor de texto de iCalendar
+func escaparTextoICS(texto string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(texto)
+}
+
+// escribirLineaICS escribe una línea terminada en CRLF, partida en líneas de hasta 75 bytes
+// sin cortar caracteres UTF-8; las líneas de continuación empiezan con un espacio
+func escribirLineaICS(ics *strings.Builder, linea string) {
+	limite := 75
+	for len(linea) > limite {
+		corte := limite
+		for corte > 0 && !utf8.RuneStart(linea[corte]) {
+			corte--
+		}
+		ics.WriteString(linea[:corte])
+		ics.WriteString("\r\n ")
+		linea = linea[corte:]
+		limite = 74 // El espacio inicial cuenta en la longitud de la línea
+	}
+	ics.WriteString(linea)
+	ics.WriteString("\r\n")
+}
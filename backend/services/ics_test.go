@@ -0,0 +1,38 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCalendarioICSAumentaSequenceAlCambiarLaFecha(t *testing.T) {
+	repos, partido, _ := partidoEnMemoria(t)
+	service := NewCalendarioServiceConRepositorios(repos)
+
+	sequence := func() uint {
+		t.Helper()
+		ics, err := service.GetCalendarioICS()
+		if err != nil {
+			t.Fatal(err)
+		}
+		guardado, err := repos.Calendario.ObtenerPartido(partido.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(ics, []byte(fmt.Sprintf("SEQUENCE:%d\r\n", guardado.Secuencia))) {
+			t.Fatalf("el calendario no tiene SEQUENCE:%d:\n%s", guardado.Secuencia, ics)
+		}
+		return guardado.Secuencia
+	}
+
+	antes := sequence()
+	partido.FechaHora = time.Date(2024, 8, 10, 18, 0, 0, 0, time.UTC)
+	if _, err := service.ActualizarPartido(partido); err != nil {
+		t.Fatal(err)
+	}
+	if despues := sequence(); despues <= antes {
+		t.Errorf("SEQUENCE pasó de %d a %d al cambiar la fecha", antes, despues)
+	}
+}
@@ -0,0 +1,1098 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// Datos que se pueden importar desde una hoja de cálculo
+const (
+	ImportarEstadios    = "estadios"
+	ImportarEquipos     = "equipos"
+	ImportarJugadores   = "jugadores"
+	ImportarPartidos    = "partidos"
+	ImportarIncidencias = "incidencias"
+)
+
+var (
+	// ErrTipoImportacion indica que no se pueden importar datos del tipo indicado
+	ErrTipoImportacion = errors.New("solo se pueden importar estadios, equipos, jugadores, partidos o incidencias")
+	// ErrImportacionInvalida indica que alguna fila tiene errores y no se guardó ninguna
+	ErrImportacionInvalida = errors.New("el archivo tiene filas con errores y no se importó ningún dato")
+)
+
+// Reglas que incumple una fila importada. Las que coinciden con las de la API usan el mismo nombre.
+const (
+	ReglaObligatorio           = "required"
+	ReglaTipo                  = "tipo"
+	ReglaMin                   = "min"
+	ReglaMax                   = "max"
+	ReglaOpciones              = "oneof"
+	ReglaFecha                 = "fecha"
+	ReglaDuplicado             = "duplicado"           // Otra fila del archivo describe el mismo registro
+	ReglaColumnaFaltante       = "columna_faltante"    // El encabezado no tiene una columna obligatoria
+	ReglaColumnaDesconocida    = "columna_desconocida" // El encabezado tiene una columna que no se importa
+	ReglaSinFilas              = "sin_filas"           // El archivo no tiene filas de datos
+	ReglaEquipoInexistente     = "equipo_inexistente"
+	ReglaEstadioInexistente    = "estadio_inexistente"
+	ReglaNumeroRepetido        = "numero_repetido"   // Otro jugador del equipo usa el mismo número
+	ReglaEquiposIguales        = "equipos_iguales"   // El local y el visitante son el mismo equipo
+	ReglaEquipoRepetidoJornada = "equipo_en_jornada" // El equipo ya juega otro partido en la jornada
+	ReglaPartidoInexistente    = "partido_inexistente"
+	ReglaJugadorInexistente    = "jugador_inexistente" // El equipo no tiene un jugador con ese nombre
+	ReglaEquipoAjeno           = "equipo_ajeno"        // El equipo no juega el partido
+	ReglaSoloSustitucion       = "solo_sustitucion"    // El valor solo aplica a las sustituciones
+)
+
+// ErrorFila describe un valor inválido de una fila del archivo importado. Parametro completa
+// el mensaje de algunas reglas, como el mínimo de "min" o las opciones de "oneof".
+type ErrorFila struct {
+	Linea     int
+	Campo     string
+	Regla     string
+	Parametro string
+	Valor     string
+}
+
+// ResultadoImportacion resume una importación o, si es una simulación, lo que se importaría
+type ResultadoImportacion struct {
+	Tipo         string      `json:"tipo"`
+	Simulacion   bool        `json:"simulacion"`
+	Filas        int         `json:"filas"`
+	Creados      int         `json:"creados"`
+	Actualizados int         `json:"actualizados"`
+	Omitidos     int         `json:"omitidos"` // Filas que ya estaban importadas y no cambian nada
+	Errores      []ErrorFila `json:"-"`
+}
+
+// columnaImportacion es una columna del archivo; se reconoce por el nombre del campo o por sus
+// alias, sin distinguir mayúsculas, tildes, espacios ni guiones bajos. Las columnas ignoradas
+// son los IDs que incluye la exportación, que cambian de una base de datos a otra.
+type columnaImportacion struct {
+	campo       string
+	alias       []string
+	obligatoria bool
+	ignorada    bool
+}
+
+var columnasImportacion = map[string][]columnaImportacion{
+	ImportarEstadios: {
+		{campo: "id", ignorada: true},
+		{campo: "nombre", obligatoria: true},
+		{campo: "ciudad"},
+		{campo: "capacidad"},
+		{campo: "latitud"},
+		{campo: "longitud"},
+		{campo: "superficie"},
+	},
+	ImportarEquipos: {
+		{campo: "id", ignorada: true},
+		{campo: "nombre", obligatoria: true},
+		{campo: "nombreCorto", obligatoria: true},
+		{campo: "ciudad", obligatoria: true},
+		{campo: "estadioId", ignorada: true},
+		{campo: "estadio", obligatoria: true},
+		{campo: "fundacion", obligatoria: true},
+		{campo: "escudo", obligatoria: true},
+	},
+	ImportarJugadores: {
+		{campo: "id", ignorada: true},
+		{campo: "equipoId", ignorada: true},
+		{campo: "equipo", obligatoria: true},
+		{campo: "nombre", obligatoria: true},
+		{campo: "apellido", obligatoria: true},
+		{campo: "fechaNacimiento"},
+		{campo: "nacionalidad"},
+		{campo: "posicion"},
+		{campo: "numero", alias: []string{"dorsal"}},
+		{campo: "altura"},
+		{campo: "peso"},
+		{campo: "foto"},
+	},
+	ImportarPartidos: {
+		{campo: "id", ignorada: true},
+		{campo: "jornadaId", ignorada: true},
+		{campo: "jornada", obligatoria: true},
+		{campo: "fechaHora", alias: []string{"fecha"}, obligatoria: true},
+		{campo: "equipoLocalId", ignorada: true},
+		{campo: "equipoLocal", alias: []string{"local"}, obligatoria: true},
+		{campo: "equipoVisitanteId", ignorada: true},
+		{campo: "equipoVisitante", alias: []string{"visitante"}, obligatoria: true},
+		{campo: "estadioId", ignorada: true},
+		{campo: "estadio"},
+		{campo: "golesLocal"},
+		{campo: "golesVisitante"},
+		{campo: "estado"},
+	},
+	ImportarIncidencias: {
+		{campo: "id", ignorada: true},
+		{campo: "partidoId", ignorada: true},
+		{campo: "jornada", obligatoria: true},
+		{campo: "equipoLocal", alias: []string{"local"}, obligatoria: true},
+		{campo: "equipoVisitante", alias: []string{"visitante"}, obligatoria: true},
+		{campo: "jugadorId", ignorada: true},
+		{campo: "jugador", obligatoria: true},
+		{campo: "equipo", obligatoria: true},
+		{campo: "tipo", obligatoria: true},
+		{campo: "jugadorEntra"},
+		{campo: "minuto"},
+		{campo: "minutoAnadido"},
+		{campo: "descripcion"},
+		{campo: "timestamp"},
+		{campo: "secuencia"},
+	},
+}
+
+// ImportacionService importa estadios, equipos, jugadores, partidos e incidencias desde hojas de cálculo
+type ImportacionService struct {
+	repositorios.Repositorios
+}
+
+// NewImportacionService crea una nueva instancia del servicio de importación
+func NewImportacionService(db *gorm.DB) *ImportacionService {
+	return NewImportacionServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewImportacionServiceConRepositorios crea el servicio de importación sobre los repositorios indicados
+func NewImportacionServiceConRepositorios(repos repositorios.Repositorios) *ImportacionService {
+	return &ImportacionService{
+		Repositorios: repos,
+	}
+}
+
+// Importar valida todas las filas de una tabla cuya primera fila es el encabezado y, si no hay
+// errores y no es una simulación, crea o actualiza los registros en una sola transacción.
+// Los estadios y los equipos se identifican por su nombre, los jugadores por su equipo, nombre y apellido, y
+// los partidos por su jornada y sus equipos. Las incidencias que ya están registradas, con el
+// mismo jugador, tipo y minuto, se omiten. Si alguna fila tiene errores devuelve
+// ErrImportacionInvalida junto con el resultado que los describe.
+func (s *ImportacionService) Importar(tipo string, filas []Fila, simular bool) (ResultadoImportacion, error) {
+	resultado := ResultadoImportacion{Tipo: tipo, Simulacion: simular, Errores: []ErrorFila{}}
+	columnas, ok := columnasImportacion[tipo]
+	if !ok {
+		return resultado, ErrTipoImportacion
+	}
+
+	err := s.Transaccion(func(repos repositorios.Repositorios) error {
+		tabla := leerEncabezado(columnas, filas, &resultado)
+		if len(resultado.Errores) > 0 {
+			return ErrImportacionInvalida
+		}
+		resultado.Filas = len(tabla)
+
+		var guardar func() error
+		var err error
+		switch tipo {
+		case ImportarEstadios:
+			guardar, err = planificarEstadios(repos, tabla, &resultado)
+		case ImportarEquipos:
+			guardar, err = planificarEquipos(repos, tabla, &resultado)
+		case ImportarJugadores:
+			guardar, err = planificarJugadores(repos, tabla, &resultado)
+		case ImportarPartidos:
+			guardar, err = planificarPartidos(repos, tabla, &resultado)
+		case ImportarIncidencias:
+			guardar, err = planificarIncidencias(repos, tabla, &resultado)
+		}
+		if err != nil {
+			return err
+		}
+		if len(resultado.Errores) > 0 {
+			return ErrImportacionInvalida
+		}
+		if simular {
+			return nil
+		}
+		return guardar()
+	})
+	return resultado, err
+}
+
+// filaImportada son los valores de una fila por campo
+type filaImportada struct {
+	linea   int
+	valores map[string]string
+}
+
+// leerEncabezado relaciona las columnas del encabezado con los campos y devuelve las filas de datos
+func leerEncabezado(columnas []columnaImportacion, filas []Fila, resultado *ResultadoImportacion) []filaImportada {
+	if len(filas) < 2 {
+		linea := 1
+		if len(filas) == 1 {
+			linea = filas[0].Linea + 1
+		}
+		resultado.Errores = append(resultado.Errores, ErrorFila{Linea: linea, Regla: ReglaSinFilas})
+		return nil
+	}
+
+	porNombre := make(map[string]string)
+	ignoradas := make(map[string]bool)
+	for _, columna := range columnas {
+		porNombre[normalizarTexto(columna.campo)] = columna.campo
+		ignoradas[columna.campo] = columna.ignorada
+		for _, alias := range columna.alias {
+			porNombre[normalizarTexto(alias)] = columna.campo
+		}
+	}
+
+	encabezado := filas[0]
+	campos := make([]string, len(encabezado.Celdas))
+	presentes := make(map[string]bool)
+	for i, celda := range encabezado.Celdas {
+		if strings.TrimSpace(celda) == "" {
+			continue
+		}
+		campo, ok := porNombre[normalizarTexto(celda)]
+		if !ok {
+			resultado.Errores = append(resultado.Errores, ErrorFila{Linea: encabezado.Linea, Campo: celda, Regla: ReglaColumnaDesconocida})
+			continue
+		}
+		if presentes[campo] {
+			resultado.Errores = append(resultado.Errores, ErrorFila{Linea: encabezado.Linea, Campo: campo, Regla: ReglaDuplicado})
+			continue
+		}
+		presentes[campo] = true
+		if !ignoradas[campo] {
+			campos[i] = campo
+		}
+	}
+	for _, columna := range columnas {
+		if columna.obligatoria && !presentes[columna.campo] {
+			resultado.Errores = append(resultado.Errores, ErrorFila{Linea: encabezado.Linea, Campo: columna.campo, Regla: ReglaColumnaFaltante})
+		}
+	}
+
+	tabla := make([]filaImportada, 0, len(filas)-1)
+	for _, fila := range filas[1:] {
+		importada := filaImportada{linea: fila.Linea, valores: make(map[string]string)}
+		for i, celda := range fila.Celdas {
+			if i < len(campos) && campos[i] != "" {
+				importada.valores[campos[i]] = strings.TrimSpace(celda)
+			}
+		}
+		tabla = append(tabla, importada)
+	}
+	return tabla
+}
+
+// validadorFila lee los valores de una fila y acumula sus errores
+type validadorFila struct {
+	fila      filaImportada
+	resultado *ResultadoImportacion
+	valida    bool
+}
+
+func nuevoValidadorFila(fila filaImportada, resultado *ResultadoImportacion) *validadorFila {
+	return &validadorFila{fila: fila, resultado: resultado, valida: true}
+}
+
+// error registra un error en un campo de la fila
+func (v *validadorFila) error(campo, regla, parametro string) {
+	v.valida = false
+	v.resultado.Errores = append(v.resultado.Errores, ErrorFila{
+		Linea:     v.fila.linea,
+		Campo:     campo,
+		Regla:     regla,
+		Parametro: parametro,
+		Valor:     v.fila.valores[campo],
+	})
+}
+
+// texto devuelve el valor de un campo, que no puede estar vacío si es obligatorio
+func (v *validadorFila) texto(campo string, obligatorio bool) string {
+	valor := v.fila.valores[campo]
+	if obligatorio && valor == "" {
+		v.error(campo, ReglaObligatorio, "")
+	}
+	return valor
+}
+
+// entero lee un número entero entre minimo y maximo; devuelve nil si el campo está vacío
+func (v *validadorFila) entero(campo string, obligatorio bool, minimo, maximo int) *int {
+	valor := v.texto(campo, obligatorio)
+	if valor == "" {
+		return nil
+	}
+	// Excel puede guardar los enteros como decimales, por ejemplo 10.0
+	decimal, err := strconv.ParseFloat(strings.Replace(valor, ",", ".", 1), 64)
+	if err != nil || decimal != math.Trunc(decimal) {
+		v.error(campo, ReglaTipo, "")
+		return nil
+	}
+	n := int(decimal)
+	if n < minimo {
+		v.error(campo, ReglaMin, strconv.Itoa(minimo))
+		return nil
+	}
+	if n > maximo {
+		v.error(campo, ReglaMax, strconv.Itoa(maximo))
+		return nil
+	}
+	return &n
+}
+
+// decimal lee un número positivo, con punto o coma decimal; devuelve 0 si el campo está vacío
+func (v *validadorFila) decimal(campo string) float64 {
+	valor := v.texto(campo, false)
+	if valor == "" {
+		return 0
+	}
+	n, err := strconv.ParseFloat(strings.Replace(valor, ",", ".", 1), 64)
+	if err != nil {
+		v.error(campo, ReglaTipo, "")
+		return 0
+	}
+	if n <= 0 {
+		v.error(campo, "gt", "0")
+		return 0
+	}
+	return n
+}
+
+// coordenada lee un número entre -limite y limite; devuelve nil si el campo está vacío
+func (v *validadorFila) coordenada(campo string, limite float64) *float64 {
+	valor := v.texto(campo, false)
+	if valor == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(strings.Replace(valor, ",", ".", 1), 64)
+	if err != nil {
+		v.error(campo, ReglaTipo, "")
+		return nil
+	}
+	if n < -limite {
+		v.error(campo, ReglaMin, strconv.FormatFloat(-limite, 'f', -1, 64))
+		return nil
+	}
+	if n > limite {
+		v.error(campo, ReglaMax, strconv.FormatFloat(limite, 'f', -1, 64))
+		return nil
+	}
+	return &n
+}
+
+// Formatos de fecha y de fecha y hora que se aceptan al importar
+var (
+	formatosFecha     = []string{time.DateOnly, "02/01/2006", "2/1/2006"}
+	formatosFechaHora = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02 15:04:05", "02/01/2006 15:04", "2/1/2006 15:04"}
+)
+
+// fecha lee una fecha en UTC como año-mes-día, día/mes/año o número de serie de Excel. Con
+// conHora también acepta la hora; si no se indica, se usa la hora de los partidos generados.
+func (v *validadorFila) fecha(campo string, obligatorio, conHora bool) *time.Time {
+	valor := v.texto(campo, obligatorio)
+	if valor == "" {
+		return nil
+	}
+
+	if serie, err := strconv.ParseFloat(valor, 64); err == nil && serie > 0 {
+		dias, fraccion := math.Modf(serie)
+		fecha := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(dias))
+		if conHora {
+			if fraccion == 0 {
+				fecha = fecha.Add(horaPartido)
+			}
+			fecha = fecha.Add(time.Duration(math.Round(fraccion*86400)) * time.Second)
+		}
+		return &fecha
+	}
+
+	if conHora {
+		for _, formato := range formatosFechaHora {
+			if fecha, err := time.ParseInLocation(formato, valor, time.UTC); err == nil {
+				return &fecha
+			}
+		}
+	}
+	for _, formato := range formatosFecha {
+		if fecha, err := time.ParseInLocation(formato, valor, time.UTC); err == nil {
+			if conHora {
+				fecha = fecha.Add(horaPartido)
+			}
+			return &fecha
+		}
+	}
+	v.error(campo, ReglaFecha, "")
+	return nil
+}
+
+// indiceEquipos busca equipos por su nombre o su nombre corto
+type indiceEquipos map[string]models.Equipo
+
+func nuevoIndiceEquipos(equipos []models.Equipo) indiceEquipos {
+	indice := make(indiceEquipos, 2*len(equipos))
+	for _, equipo := range equipos {
+		indice[normalizarTexto(equipo.NombreCorto)] = equipo
+	}
+	// El nombre completo tiene prioridad si coincide con el nombre corto de otro equipo
+	for _, equipo := range equipos {
+		indice[normalizarTexto(equipo.Nombre)] = equipo
+	}
+	return indice
+}
+
+// equipo lee el equipo indicado por nombre en un campo
+func (v *validadorFila) equipo(campo string, equipos indiceEquipos) (models.Equipo, bool) {
+	nombre := v.texto(campo, true)
+	if nombre == "" {
+		return models.Equipo{}, false
+	}
+	equipo, ok := equipos[normalizarTexto(nombre)]
+	if !ok {
+		v.error(campo, ReglaEquipoInexistente, "")
+	}
+	return equipo, ok
+}
+
+// estadio lee el estadio registrado indicado por nombre; devuelve nil si el campo está vacío
+func (v *validadorFila) estadio(campo string, estadios map[string]models.Estadio) *uint {
+	nombre := v.texto(campo, false)
+	if nombre == "" {
+		return nil
+	}
+	estadio, ok := estadios[normalizarTexto(nombre)]
+	if !ok {
+		v.error(campo, ReglaEstadioInexistente, "")
+		return nil
+	}
+	return &estadio.ID
+}
+
+// indiceEstadios busca estadios por su nombre
+func indiceEstadios(repos repositorios.Repositorios) (map[string]models.Estadio, error) {
+	estadios, err := repos.Estadios.Listar()
+	if err != nil {
+		return nil, err
+	}
+	indice := make(map[string]models.Estadio, len(estadios))
+	for _, estadio := range estadios {
+		indice[normalizarTexto(estadio.Nombre)] = estadio
+	}
+	return indice, nil
+}
+
+// superficiesEstadio son las superficies de juego que se pueden indicar al importar estadios
+var superficiesEstadio = []string{models.SuperficieNatural, models.SuperficieArtificial, models.SuperficieHibrida}
+
+// planificarEstadios valida las filas de estadios y devuelve la función que los guarda
+func planificarEstadios(repos repositorios.Repositorios, tabla []filaImportada, resultado *ResultadoImportacion) (func() error, error) {
+	porNombre, err := indiceEstadios(repos)
+	if err != nil {
+		return nil, err
+	}
+
+	var estadios []models.Estadio
+	vistos := make(map[string]bool)
+	for _, fila := range tabla {
+		v := nuevoValidadorFila(fila, resultado)
+		nombre := v.texto("nombre", true)
+		clave := normalizarTexto(nombre)
+		if nombre != "" && vistos[clave] {
+			v.error("nombre", ReglaDuplicado, "")
+		}
+		vistos[clave] = true
+
+		estadio := porNombre[clave]
+		estadio.Nombre = nombre
+		estadio.Ciudad = v.texto("ciudad", false)
+		estadio.Capacidad = 0
+		if capacidad := v.entero("capacidad", false, 0, math.MaxInt32); capacidad != nil {
+			estadio.Capacidad = *capacidad
+		}
+		estadio.Latitud = v.coordenada("latitud", 90)
+		estadio.Longitud = v.coordenada("longitud", 180)
+		estadio.Superficie = v.texto("superficie", false)
+		if estadio.Superficie != "" && !contiene(superficiesEstadio, estadio.Superficie) {
+			v.error("superficie", ReglaOpciones, strings.Join(superficiesEstadio, " "))
+		}
+		if !v.valida {
+			continue
+		}
+
+		if estadio.ID == 0 {
+			resultado.Creados++
+		} else {
+			resultado.Actualizados++
+		}
+		estadios = append(estadios, estadio)
+	}
+
+	return func() error {
+		for i := range estadios {
+			if estadios[i].ID == 0 {
+				err = repos.Estadios.Crear(&estadios[i])
+			} else {
+				err = repos.Estadios.Actualizar(&estadios[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// planificarEquipos valida las filas de equipos y devuelve la función que los guarda
+func planificarEquipos(repos repositorios.Repositorios, tabla []filaImportada, resultado *ResultadoImportacion) (func() error, error) {
+	existentes, err := repos.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	porNombre := make(map[string]models.Equipo, len(existentes))
+	for _, equipo := range existentes {
+		porNombre[normalizarTexto(equipo.Nombre)] = equipo
+	}
+	estadios, err := indiceEstadios(repos)
+	if err != nil {
+		return nil, err
+	}
+
+	var equipos []models.Equipo
+	vistos := make(map[string]bool)
+	for _, fila := range tabla {
+		v := nuevoValidadorFila(fila, resultado)
+		nombre := v.texto("nombre", true)
+		clave := normalizarTexto(nombre)
+		if nombre != "" && vistos[clave] {
+			v.error("nombre", ReglaDuplicado, "")
+		}
+		vistos[clave] = true
+
+		equipo := porNombre[clave]
+		equipo.Nombre = nombre
+		equipo.NombreCorto = v.texto("nombreCorto", true)
+		equipo.Ciudad = v.texto("ciudad", true)
+		equipo.Estadio = v.texto("estadio", true)
+		equipo.Fundacion = v.texto("fundacion", true)
+		equipo.Escudo = v.texto("escudo", true)
+		// El estadio se asocia si está registrado; si no, el equipo conserva solo su nombre
+		if estadio, ok := estadios[normalizarTexto(equipo.Estadio)]; ok {
+			equipo.EstadioID = &estadio.ID
+		}
+		if !v.valida {
+			continue
+		}
+
+		if equipo.ID == 0 {
+			resultado.Creados++
+		} else {
+			resultado.Actualizados++
+		}
+		equipos = append(equipos, equipo)
+	}
+
+	return func() error {
+		for i := range equipos {
+			if equipos[i].ID == 0 {
+				err = repos.Equipos.Crear(&equipos[i])
+			} else {
+				err = repos.Equipos.Actualizar(&equipos[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// planificarJugadores valida las filas de jugadores y devuelve la función que los guarda
+func planificarJugadores(repos repositorios.Repositorios, tabla []filaImportada, resultado *ResultadoImportacion) (func() error, error) {
+	listaEquipos, err := repos.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	equipos := nuevoIndiceEquipos(listaEquipos)
+
+	existentes, err := repos.Jugadores.Listar()
+	if err != nil {
+		return nil, err
+	}
+	claveJugador := func(equipoID uint, nombre, apellido string) string {
+		return fmt.Sprintf("%d|%s", equipoID, normalizarTexto(nombre+" "+apellido))
+	}
+	porClave := make(map[string]models.Jugador, len(existentes))
+	for _, jugador := range existentes {
+		porClave[claveJugador(jugador.EquipoID, jugador.Nombre, jugador.Apellido)] = jugador
+	}
+
+	// Número de cada jugador por equipo: primero los de los jugadores que ya están registrados
+	numeros := make(map[uint]map[int]uint)
+	for _, jugador := range existentes {
+		if jugador.Numero > 0 {
+			if numeros[jugador.EquipoID] == nil {
+				numeros[jugador.EquipoID] = make(map[int]uint)
+			}
+			numeros[jugador.EquipoID][jugador.Numero] = jugador.ID
+		}
+	}
+	for _, fila := range tabla {
+		// Un jugador que está en el archivo deja libre su número actual
+		equipo, ok := equipos[normalizarTexto(fila.valores["equipo"])]
+		if !ok {
+			continue
+		}
+		if jugador, ok := porClave[claveJugador(equipo.ID, fila.valores["nombre"], fila.valores["apellido"])]; ok {
+			if actual, ok := numeros[jugador.EquipoID][jugador.Numero]; ok && actual == jugador.ID {
+				delete(numeros[jugador.EquipoID], jugador.Numero)
+			}
+		}
+	}
+
+	var jugadores []models.Jugador
+	vistos := make(map[string]bool)
+	for _, fila := range tabla {
+		v := nuevoValidadorFila(fila, resultado)
+		equipo, equipoValido := v.equipo("equipo", equipos)
+		nombre := v.texto("nombre", true)
+		apellido := v.texto("apellido", true)
+
+		jugador := models.Jugador{}
+		if equipoValido && nombre != "" && apellido != "" {
+			clave := claveJugador(equipo.ID, nombre, apellido)
+			if vistos[clave] {
+				v.error("apellido", ReglaDuplicado, "")
+			}
+			vistos[clave] = true
+			jugador = porClave[clave]
+		}
+		jugador.EquipoID = equipo.ID
+		jugador.Nombre = nombre
+		jugador.Apellido = apellido
+		jugador.Nacionalidad = v.texto("nacionalidad", false)
+		jugador.Posicion = v.texto("posicion", false)
+		jugador.Foto = v.texto("foto", false)
+		jugador.Altura = v.decimal("altura")
+		jugador.Peso = v.decimal("peso")
+		jugador.FechaNacimiento = time.Time{}
+		if fecha := v.fecha("fechaNacimiento", false, false); fecha != nil {
+			jugador.FechaNacimiento = *fecha
+		}
+		jugador.Numero = 0
+		if numero := v.entero("numero", false, 1, 99); numero != nil {
+			jugador.Numero = *numero
+			if equipoValido {
+				if numeros[equipo.ID] == nil {
+					numeros[equipo.ID] = make(map[int]uint)
+				}
+				if _, ok := numeros[equipo.ID][*numero]; ok {
+					v.error("numero", ReglaNumeroRepetido, "")
+				} else {
+					numeros[equipo.ID][*numero] = jugador.ID
+				}
+			}
+		}
+		if !v.valida {
+			continue
+		}
+
+		if jugador.ID == 0 {
+			resultado.Creados++
+		} else {
+			resultado.Actualizados++
+		}
+		jugadores = append(jugadores, jugador)
+	}
+
+	return func() error {
+		for i := range jugadores {
+			if jugadores[i].ID == 0 {
+				err = repos.Jugadores.Crear(&jugadores[i])
+			} else {
+				err = repos.Jugadores.Actualizar(&jugadores[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// estadosImportables son los estados que se pueden indicar al importar partidos
+var estadosImportables = []string{models.EstadoPendiente, models.EstadoEnCurso, models.EstadoFinalizado, models.EstadoAplazado}
+
+// planificarPartidos valida las filas de partidos y devuelve la función que los guarda. Las
+// jornadas que no existen se crean con la fecha del primero de sus partidos. Un partido con
+// los dos marcadores y sin estado se importa como finalizado.
+func planificarPartidos(repos repositorios.Repositorios, tabla []filaImportada, resultado *ResultadoImportacion) (func() error, error) {
+	listaEquipos, err := repos.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	equipos := nuevoIndiceEquipos(listaEquipos)
+	estadios, err := indiceEstadios(repos)
+	if err != nil {
+		return nil, err
+	}
+
+	listaJornadas, err := repos.Calendario.ListarJornadas()
+	if err != nil {
+		return nil, err
+	}
+	jornadas := make(map[int]models.Jornada, len(listaJornadas))
+	numeroJornada := make(map[uint]int, len(listaJornadas))
+	for _, jornada := range listaJornadas {
+		jornadas[jornada.Numero] = jornada
+		numeroJornada[jornada.ID] = jornada.Numero
+	}
+
+	// Partidos existentes por jornada y cruce, y equipos que ya juegan en cada jornada
+	type cruce struct {
+		jornada, local, visitante uint
+	}
+	existentes, err := repos.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		return nil, err
+	}
+	porCruce := make(map[cruce]models.Partido, len(existentes))
+	ocupados := make(map[[2]uint]bool) // [número de jornada, equipo]
+	for _, partido := range existentes {
+		numero := uint(numeroJornada[partido.JornadaID])
+		porCruce[cruce{numero, partido.EquipoLocalID, partido.EquipoVisitanteID}] = partido
+		ocupados[[2]uint{numero, partido.EquipoLocalID}] = true
+		ocupados[[2]uint{numero, partido.EquipoVisitanteID}] = true
+	}
+	for _, fila := range tabla {
+		// Un partido que está en el archivo deja libres a sus equipos en su jornada
+		numero, errNumero := strconv.Atoi(fila.valores["jornada"])
+		local, okLocal := equipos[normalizarTexto(fila.valores["equipoLocal"])]
+		visitante, okVisitante := equipos[normalizarTexto(fila.valores["equipoVisitante"])]
+		if errNumero != nil || !okLocal || !okVisitante {
+			continue
+		}
+		if _, ok := porCruce[cruce{uint(numero), local.ID, visitante.ID}]; ok {
+			delete(ocupados, [2]uint{uint(numero), local.ID})
+			delete(ocupados, [2]uint{uint(numero), visitante.ID})
+		}
+	}
+
+	var partidos []models.Partido
+	partidosJornada := make([]int, 0, len(tabla))
+	nuevasJornadas := make(map[int]time.Time)
+	vistos := make(map[cruce]bool)
+	for _, fila := range tabla {
+		v := nuevoValidadorFila(fila, resultado)
+		numero := v.entero("jornada", true, 1, math.MaxInt32)
+		fechaHora := v.fecha("fechaHora", true, true)
+		local, okLocal := v.equipo("equipoLocal", equipos)
+		visitante, okVisitante := v.equipo("equipoVisitante", equipos)
+		estadioID := v.estadio("estadio", estadios)
+		golesLocal := v.entero("golesLocal", false, 0, math.MaxInt32)
+		golesVisitante := v.entero("golesVisitante", false, 0, math.MaxInt32)
+		estado := v.texto("estado", false)
+		if estado != "" && !contiene(estadosImportables, estado) {
+			v.error("estado", ReglaOpciones, strings.Join(estadosImportables, " "))
+		}
+		if okLocal && okVisitante && local.ID == visitante.ID {
+			v.error("equipoVisitante", ReglaEquiposIguales, "")
+		}
+
+		var partido models.Partido
+		if numero != nil && okLocal && okVisitante && local.ID != visitante.ID {
+			clave := cruce{uint(*numero), local.ID, visitante.ID}
+			if vistos[clave] {
+				v.error("equipoVisitante", ReglaDuplicado, "")
+			}
+			vistos[clave] = true
+			partido = porCruce[clave]
+
+			for campo, equipo := range map[string]uint{"equipoLocal": local.ID, "equipoVisitante": visitante.ID} {
+				if ocupados[[2]uint{uint(*numero), equipo}] {
+					v.error(campo, ReglaEquipoRepetidoJornada, "")
+				} else {
+					ocupados[[2]uint{uint(*numero), equipo}] = true
+				}
+			}
+		}
+		if !v.valida {
+			continue
+		}
+
+		partido.EquipoLocalID = local.ID
+		partido.EquipoVisitanteID = visitante.ID
+		partido.FechaHora = *fechaHora
+		partido.EstadioID = estadioID
+		if golesLocal != nil {
+			partido.GolesLocal = *golesLocal
+		}
+		if golesVisitante != nil {
+			partido.GolesVisitante = *golesVisitante
+		}
+		switch {
+		case estado != "":
+			partido.Estado = estado
+		case golesLocal != nil && golesVisitante != nil:
+			partido.Estado = models.EstadoFinalizado
+		case partido.Estado == "":
+			partido.Estado = models.EstadoPendiente
+		}
+
+		if _, ok := jornadas[*numero]; !ok {
+			if fecha, ok := nuevasJornadas[*numero]; !ok || fechaHora.Before(fecha) {
+				nuevasJornadas[*numero] = *fechaHora
+			}
+		}
+		if partido.ID == 0 {
+			resultado.Creados++
+		} else {
+			resultado.Actualizados++
+		}
+		partidos = append(partidos, partido)
+		partidosJornada = append(partidosJornada, *numero)
+	}
+
+	return func() error {
+		for numero, fecha := range nuevasJornadas {
+			jornada := models.Jornada{Numero: numero, Fecha: inicioDelDia(fecha)}
+			if err := repos.Calendario.CrearJornada(&jornada); err != nil {
+				return err
+			}
+			jornadas[numero] = jornada
+		}
+		for i := range partidos {
+			partidos[i].JornadaID = jornadas[partidosJornada[i]].ID
+			if partidos[i].ID == 0 {
+				err = repos.Calendario.CrearPartido(&partidos[i])
+			} else {
+				err = guardarPartido(repos.Calendario, &partidos[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// tiposIncidencia son los tipos de incidencia que se pueden importar
+var tiposIncidencia = []string{
+	string(models.Gol), string(models.GolPenal), string(models.PenalFallado), string(models.GolEnContra),
+	string(models.TarjetaAmarilla), string(models.TarjetaRoja), string(models.Sustitucion), string(models.Asistencia),
+}
+
+// planificarIncidencias valida las filas de incidencias y devuelve la función que las guarda.
+// Cada partido se identifica por su jornada y sus equipos, y cada jugador por su equipo y su
+// nombre completo. Cada incidencia nueva avanza la secuencia del partido, como las que
+// registran los operadores; el marcador no cambia, porque se importa con los partidos.
+func planificarIncidencias(repos repositorios.Repositorios, tabla []filaImportada, resultado *ResultadoImportacion) (func() error, error) {
+	listaEquipos, err := repos.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	equipos := nuevoIndiceEquipos(listaEquipos)
+
+	listaJornadas, err := repos.Calendario.ListarJornadas()
+	if err != nil {
+		return nil, err
+	}
+	numeroJornada := make(map[uint]int, len(listaJornadas))
+	for _, jornada := range listaJornadas {
+		numeroJornada[jornada.ID] = jornada.Numero
+	}
+
+	type cruce struct {
+		jornada, local, visitante uint
+	}
+	partidos, err := repos.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		return nil, err
+	}
+	porCruce := make(map[cruce]models.Partido, len(partidos))
+	porID := make(map[uint]models.Partido, len(partidos))
+	for _, partido := range partidos {
+		porCruce[cruce{uint(numeroJornada[partido.JornadaID]), partido.EquipoLocalID, partido.EquipoVisitanteID}] = partido
+		porID[partido.ID] = partido
+	}
+
+	jugadores, err := repos.Jugadores.Listar()
+	if err != nil {
+		return nil, err
+	}
+	claveJugador := func(equipoID uint, nombre string) string {
+		return fmt.Sprintf("%d|%s", equipoID, normalizarTexto(nombre))
+	}
+	porNombre := make(map[string]models.Jugador, len(jugadores))
+	homonimos := make(map[string][]models.Jugador)
+	for _, jugador := range jugadores {
+		porNombre[claveJugador(jugador.EquipoID, jugador.Nombre+" "+jugador.Apellido)] = jugador
+		clave := claveJugador(0, jugador.Nombre+" "+jugador.Apellido)
+		homonimos[clave] = append(homonimos[clave], jugador)
+	}
+	// buscarJugador busca al jugador en el equipo de la incidencia. Si fue traspasado después
+	// del partido ya no está en ese equipo, y se acepta si nadie más en la liga tiene su nombre.
+	buscarJugador := func(equipoID uint, nombre string) (models.Jugador, bool) {
+		if jugador, ok := porNombre[claveJugador(equipoID, nombre)]; ok {
+			return jugador, true
+		}
+		if candidatos := homonimos[claveJugador(0, nombre)]; len(candidatos) == 1 {
+			return candidatos[0], true
+		}
+		return models.Jugador{}, false
+	}
+
+	claveIncidencia := func(incidencia models.Incidencia) string {
+		return fmt.Sprintf("%d|%d|%s|%d|%d", incidencia.PartidoID, incidencia.JugadorID, incidencia.Tipo, incidencia.Minuto, incidencia.MinutoAnadido)
+	}
+	existentes, err := repos.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{})
+	if err != nil {
+		return nil, err
+	}
+	// Un jugador puede repetir una incidencia en el mismo minuto, como dos goles o la amarilla y
+	// la roja por doble amarilla, así que se cuenta cuántas hay de cada clase. Al reimportar un
+	// archivo se omiten tantas filas de cada clase como ya estén registradas.
+	registradas := make(map[string]int, len(existentes))
+	for _, incidencia := range existentes {
+		registradas[claveIncidencia(incidencia)]++
+	}
+
+	var incidencias []models.Incidencia
+	importadas := make(map[string]int)
+	// La secuencia de la exportación identifica cada incidencia de un partido; dos filas con la
+	// misma son la misma incidencia repetida en el archivo
+	secuencias := make(map[string]bool)
+	for _, fila := range tabla {
+		v := nuevoValidadorFila(fila, resultado)
+		numero := v.entero("jornada", true, 1, math.MaxInt32)
+		local, okLocal := v.equipo("equipoLocal", equipos)
+		visitante, okVisitante := v.equipo("equipoVisitante", equipos)
+		equipo, okEquipo := v.equipo("equipo", equipos)
+		nombreJugador := v.texto("jugador", true)
+		tipo := models.TipoIncidencia(v.texto("tipo", true))
+		if tipo != "" && !tipo.Valido() {
+			v.error("tipo", ReglaOpciones, strings.Join(tiposIncidencia, " "))
+		}
+		nombreEntra := v.texto("jugadorEntra", false)
+		if nombreEntra != "" && tipo != models.Sustitucion {
+			v.error("jugadorEntra", ReglaSoloSustitucion, "")
+		}
+		minuto := v.entero("minuto", false, 0, math.MaxInt32)
+		minutoAnadido := v.entero("minutoAnadido", false, 0, math.MaxInt32)
+		descripcion := v.texto("descripcion", false)
+		if len([]rune(descripcion)) > 255 {
+			v.error("descripcion", "max_texto", "255")
+		}
+		timestamp := v.fecha("timestamp", false, true)
+		secuencia := v.entero("secuencia", false, 0, math.MaxInt32)
+
+		var partido models.Partido
+		okPartido := false
+		if numero != nil && okLocal && okVisitante {
+			if partido, okPartido = porCruce[cruce{uint(*numero), local.ID, visitante.ID}]; !okPartido {
+				v.error("equipoVisitante", ReglaPartidoInexistente, "")
+			}
+		}
+		var jugador, entra models.Jugador
+		if okEquipo {
+			if okPartido && equipo.ID != local.ID && equipo.ID != visitante.ID {
+				v.error("equipo", ReglaEquipoAjeno, "")
+			}
+			if nombreJugador != "" {
+				var ok bool
+				if jugador, ok = buscarJugador(equipo.ID, nombreJugador); !ok {
+					v.error("jugador", ReglaJugadorInexistente, "")
+				}
+			}
+			if nombreEntra != "" {
+				var ok bool
+				if entra, ok = buscarJugador(equipo.ID, nombreEntra); !ok {
+					v.error("jugadorEntra", ReglaJugadorInexistente, "")
+				}
+			}
+		}
+		if !v.valida {
+			continue
+		}
+
+		incidencia := models.Incidencia{
+			PartidoID:   partido.ID,
+			JugadorID:   jugador.ID,
+			EquipoID:    equipo.ID,
+			Tipo:        tipo,
+			Descripcion: descripcion,
+			Timestamp:   time.Now(),
+		}
+		if nombreEntra != "" {
+			incidencia.JugadorEntraID = &entra.ID
+		}
+		if minuto != nil {
+			incidencia.Minuto = *minuto
+		}
+		if minutoAnadido != nil {
+			incidencia.MinutoAnadido = *minutoAnadido
+		}
+		if timestamp != nil {
+			incidencia.Timestamp = *timestamp
+		}
+
+		if secuencia != nil && *secuencia > 0 {
+			claveSecuencia := fmt.Sprintf("%d|%d", partido.ID, *secuencia)
+			if secuencias[claveSecuencia] {
+				v.error("secuencia", ReglaDuplicado, "")
+				continue
+			}
+			secuencias[claveSecuencia] = true
+		}
+		clave := claveIncidencia(incidencia)
+		importadas[clave]++
+		if importadas[clave] <= registradas[clave] {
+			resultado.Omitidos++
+			continue
+		}
+		resultado.Creados++
+		incidencias = append(incidencias, incidencia)
+	}
+
+	return func() error {
+		for i := range incidencias {
+			partido := porID[incidencias[i].PartidoID]
+			if err := guardarPartido(repos.Calendario, &partido); err != nil {
+				return err
+			}
+			porID[partido.ID] = partido
+			incidencias[i].Secuencia = partido.Secuencia
+			if err := repos.Calendario.CrearIncidencia(&incidencias[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// contiene indica si un valor está en la lista
+func contiene(lista []string, valor string) bool {
+	for _, elemento := range lista {
+		if elemento == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizarTexto pasa un texto a minúsculas sin tildes y quita lo que no son letras ni números,
+// para comparar nombres escritos de distinta forma
+func normalizarTexto(texto string) string {
+	sinTildes, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), texto)
+	if err != nil {
+		sinTildes = texto
+	}
+	var normalizado strings.Builder
+	for _, r := range strings.ToLower(sinTildes) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			normalizado.WriteRune(r)
+		}
+	}
+	return normalizado.String()
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+// filasCSV convierte líneas separadas por comas en filas numeradas desde la 1
+func filasCSV(lineas ...string) []Fila {
+	filas := make([]Fila, len(lineas))
+	for i, linea := range lineas {
+		filas[i] = Fila{Linea: i + 1, Celdas: strings.Split(linea, ",")}
+	}
+	return filas
+}
+
+func TestImportarJugadoresValidaEquiposYNumeros(t *testing.T) {
+	casos := []struct {
+		nombre                string
+		filas                 []string
+		creados, actualizados int
+		linea                 int    // línea del error esperado, 0 si la importación es válida
+		campo                 string // campo del error esperado
+		regla                 string
+	}{
+		{
+			nombre:  "jugadores validos",
+			filas:   []string{"Águilas,Iván,Paz,4", "Búhos,Luis,Mora,9"},
+			creados: 2,
+		},
+		{
+			nombre:       "el jugador del archivo conserva su numero",
+			filas:        []string{"Águilas,Ana,Ríos,9", "Águilas,Iván,Paz,4"},
+			creados:      1,
+			actualizados: 1,
+		},
+		{
+			nombre:       "un jugador del archivo deja libre su numero",
+			filas:        []string{"Águilas,Ana,Ríos,10", "Águilas,Iván,Paz,9"},
+			creados:      1,
+			actualizados: 1,
+		},
+		{
+			nombre: "numero repetido en el archivo",
+			filas:  []string{"Águilas,Iván,Paz,4", "Águilas,Eva,Gil,4"},
+			linea:  3,
+			campo:  "numero",
+			regla:  ReglaNumeroRepetido,
+		},
+		{
+			nombre: "numero de otro jugador del equipo",
+			filas:  []string{"Águilas,Iván,Paz,9"},
+			linea:  2,
+			campo:  "numero",
+			regla:  ReglaNumeroRepetido,
+		},
+		{
+			nombre: "equipo inexistente",
+			filas:  []string{"Águilas,Iván,Paz,4", "Cóndores,Eva,Gil,5"},
+			linea:  3,
+			campo:  "equipo",
+			regla:  ReglaEquipoInexistente,
+		},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			repos := repositorios.NewEnMemoria()
+			aguilas := models.Equipo{Nombre: "Águilas"}
+			buhos := models.Equipo{Nombre: "Búhos"}
+			for _, equipo := range []*models.Equipo{&aguilas, &buhos} {
+				if err := repos.Equipos.Crear(equipo); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := repos.Jugadores.Crear(&models.Jugador{Nombre: "Ana", Apellido: "Ríos", Numero: 9, EquipoID: aguilas.ID}); err != nil {
+				t.Fatal(err)
+			}
+
+			filas := filasCSV(append([]string{"equipo,nombre,apellido,numero"}, caso.filas...)...)
+			resultado, err := NewImportacionServiceConRepositorios(repos).Importar(ImportarJugadores, filas, false)
+			jugadores, errListar := repos.Jugadores.Listar()
+			if errListar != nil {
+				t.Fatal(errListar)
+			}
+
+			if caso.regla == "" {
+				if err != nil {
+					t.Fatalf("error = %v %+v; se esperaba una importación válida", err, resultado.Errores)
+				}
+				if resultado.Creados != caso.creados || resultado.Actualizados != caso.actualizados {
+					t.Errorf("%d creados y %d actualizados; se esperaban %d y %d", resultado.Creados, resultado.Actualizados, caso.creados, caso.actualizados)
+				}
+				if len(jugadores) != 1+caso.creados {
+					t.Errorf("%d jugadores guardados; se esperaban %d", len(jugadores), 1+caso.creados)
+				}
+				return
+			}
+			if !errors.Is(err, ErrImportacionInvalida) {
+				t.Fatalf("error = %v; se esperaba ErrImportacionInvalida", err)
+			}
+			if len(resultado.Errores) != 1 {
+				t.Fatalf("errores %+v; se esperaba solo %s", resultado.Errores, caso.regla)
+			}
+			if e := resultado.Errores[0]; e.Linea != caso.linea || e.Campo != caso.campo || e.Regla != caso.regla {
+				t.Errorf("error en la línea %d, campo %s, regla %s; se esperaba línea %d, campo %s, regla %s", e.Linea, e.Campo, e.Regla, caso.linea, caso.campo, caso.regla)
+			}
+			if len(jugadores) != 1 {
+				t.Errorf("%d jugadores guardados en una importación rechazada; se esperaba 1", len(jugadores))
+			}
+		})
+	}
+}
@@ -2,97 +2,97 @@ package services
 
 import (
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/noisk8/torneas/backend/database"
 	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
 	"gorm.io/gorm"
 )
 
 // JugadorService proporciona métodos para interactuar con los jugadores
 type JugadorService struct {
-	DB *gorm.DB
+	repositorios.Repositorios
 }
 
 // NewJugadorService crea una nueva instancia del servicio de jugadores
-func NewJugadorService() *JugadorService {
+func NewJugadorService(db *gorm.DB) *JugadorService {
+	return NewJugadorServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewJugadorServiceConRepositorios crea el servicio de jugadores sobre los repositorios indicados
+func NewJugadorServiceConRepositorios(repos repositorios.Repositorios) *JugadorService {
 	return &JugadorService{
-		DB: database.GetDB(),
+		Repositorios: repos,
 	}
 }
 
 // GetAllJugadores obtiene todos los jugadores
 func (s *JugadorService) GetAllJugadores() ([]models.Jugador, error) {
-	var jugadores []models.Jugador
-	result := s.DB.Find(&jugadores)
-	return jugadores, result.Error
+	return s.Jugadores.Listar()
 }
 
 // GetJugadorByID obtiene un jugador por su ID
 func (s *JugadorService) GetJugadorByID(id uint) (models.Jugador, error) {
-	var jugador models.Jugador
-	result := s.DB.First(&jugador, id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	jugador, err := s.Jugadores.Obtener(id)
+	if err != nil {
+		if errors.Is(err, repositorios.ErrNoEncontrado) {
 			return jugador, errors.New("jugador no encontrado")
 		}
-		return jugador, result.Error
+		return jugador, err
 	}
 	return jugador, nil
 }
 
 // CreateJugador crea un nuevo jugador
 func (s *JugadorService) CreateJugador(jugador models.Jugador) (models.Jugador, error) {
-	result := s.DB.Create(&jugador)
-	return jugador, result.Error
+	err := s.Jugadores.Crear(&jugador)
+	return jugador, err
 }
 
-// UpdateJugador actualiza un jugador existente
+// UpdateJugador actualiza un jugador existente si su versión sigue siendo la que
+// conocía quien lo modifica; en caso contrario devuelve ErrVersionObsoleta
 func (s *JugadorService) UpdateJugador(jugador models.Jugador) (models.Jugador, error) {
-	result := s.DB.Save(&jugador)
-	return jugador, result.Error
+	err := s.Jugadores.Actualizar(&jugador)
+	return jugador, err
 }
 
 // DeleteJugador elimina un jugador por su ID
 func (s *JugadorService) DeleteJugador(id uint) error {
-	result := s.DB.Delete(&models.Jugador{}, id)
-	return result.Error
+	return s.Jugadores.Eliminar(id)
 }
 
-// GetTablaGoleadores obtiene la tabla de goleadores
+// limiteGoleadores es la cantidad de jugadores que se incluyen en la tabla de goleadores
+const limiteGoleadores = 50
+
+// GetTablaGoleadores obtiene la tabla de goleadores, ordenada por goles y luego por asistencias
 func (s *JugadorService) GetTablaGoleadores() ([]models.Jugador, error) {
-	// Estructura para almacenar jugadores con sus estadísticas
-	type JugadorStats struct {
-		models.Jugador
-		EquipoNombre string
+	jugadores, err := s.Jugadores.Listar()
+	if err != nil {
+		return nil, err
 	}
-	
-	var jugadoresStats []JugadorStats
-	
-	// Consulta para obtener jugadores, sus equipos y contar sus goles
-	query := `
-		SELECT j.*, e.nombre as equipo_nombre, 
-		COUNT(CASE WHEN i.tipo = 'GOL' OR i.tipo = 'GOL_PENAL' THEN 1 END) as goles,
-		COUNT(CASE WHEN i.tipo = 'GOL_PENAL' THEN 1 END) as penales,
-		COUNT(CASE WHEN i.tipo = 'ASISTENCIA' THEN 1 END) as asistencias,
-		COUNT(DISTINCT p.id) as partidos_jugados
-		FROM jugadores j
-		JOIN equipos e ON j.equipo_id = e.id
-		LEFT JOIN incidencias i ON j.id = i.jugador_id
-		LEFT JOIN partidos p ON i.partido_id = p.id
-		GROUP BY j.id, e.nombre
-		ORDER BY goles DESC, asistencias DESC
-		LIMIT 50
-	`
-	
-	result := s.DB.Raw(query).Scan(&jugadoresStats)
-	if result.Error != nil {
-		return nil, result.Error
+	incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{})
+	if err != nil {
+		return nil, err
 	}
-	
-	// Convertir a slice de Jugador
-	jugadores := make([]models.Jugador, len(jugadoresStats))
-	for i, js := range jugadoresStats {
-		jugadores[i] = js.Jugador
+
+	porJugador := make(map[uint][]models.Incidencia)
+	for _, incidencia := range incidencias {
+		porJugador[incidencia.JugadorID] = append(porJugador[incidencia.JugadorID], incidencia)
+	}
+	for i := range jugadores {
+		acumularEstadisticas(&jugadores[i], porJugador[jugadores[i].ID])
+	}
+
+	sort.SliceStable(jugadores, func(i, j int) bool {
+		if jugadores[i].Goles != jugadores[j].Goles {
+			return jugadores[i].Goles > jugadores[j].Goles
+		}
+		return jugadores[i].Asistencias > jugadores[j].Asistencias
+	})
+	if len(jugadores) > limiteGoleadores {
+		jugadores = jugadores[:limiteGoleadores]
 	}
 	
 	return jugadores, nil
@@ -100,65 +100,273 @@ func (s *JugadorService) GetTablaGoleadores() ([]models.Jugador, error) {
 
 // GetJugadoresByEquipo obtiene los jugadores de un equipo específico
 func (s *JugadorService) GetJugadoresByEquipo(equipoID uint) ([]models.Jugador, error) {
-	var jugadores []models.Jugador
-	result := s.DB.Where("equipo_id = ?", equipoID).Find(&jugadores)
-	return jugadores, result.Error
+	return s.Jugadores.ListarPorEquipo(equipoID)
 }
 
 // GetEstadisticasJugador obtiene las estadísticas detalladas de un jugador
 func (s *JugadorService) GetEstadisticasJugador(jugadorID uint) (models.Jugador, error) {
-	var jugador models.Jugador
-	
 	// Obtener datos básicos del jugador
-	if err := s.DB.First(&jugador, jugadorID).Error; err != nil {
+	jugador, err := s.Jugadores.Obtener(jugadorID)
+	if err != nil {
 		return jugador, err
 	}
 	
-	// Obtener goles
-	var goles int64
-	s.DB.Model(&models.Incidencia{}).
-		Where("jugador_id = ? AND (tipo = ? OR tipo = ?)", jugadorID, "GOL", "GOL_PENAL").
-		Count(&goles)
-	jugador.Goles = int(goles)
-	
-	// Obtener goles de penal
-	var penales int64
-	s.DB.Model(&models.Incidencia{}).
-		Where("jugador_id = ? AND tipo = ?", jugadorID, "GOL_PENAL").
-		Count(&penales)
-	
-	// Obtener asistencias
-	var asistencias int64
-	s.DB.Model(&models.Incidencia{}).
-		Where("jugador_id = ? AND tipo = ?", jugadorID, "ASISTENCIA").
-		Count(&asistencias)
-	jugador.Asistencias = int(asistencias)
-	
-	// Obtener tarjetas amarillas
-	var amarillas int64
-	s.DB.Model(&models.Incidencia{}).
-		Where("jugador_id = ? AND tipo = ?", jugadorID, "TARJETA_AMARILLA").
-		Count(&amarillas)
-	jugador.TarjetasAmarillas = int(amarillas)
-	
-	// Obtener tarjetas rojas
-	var rojas int64
-	s.DB.Model(&models.Incidencia{}).
-		Where("jugador_id = ? AND tipo = ?", jugadorID, "TARJETA_ROJA").
-		Count(&rojas)
-	jugador.TarjetasRojas = int(rojas)
-	
-	// Obtener partidos jugados (contar partidos distintos donde el jugador tuvo alguna incidencia)
-	type Result struct {
-		Count int
-	}
-	var result Result
-	s.DB.Raw(`
-		SELECT COUNT(DISTINCT partido_id) as count 
-		FROM incidencias 
-		WHERE jugador_id = ?
-	`, jugadorID).Scan(&result)
-	jugador.PartidosJugados = result.Count
+	incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{JugadorID: jugadorID})
+	if err != nil {
+		return jugador, err
+	}
+	acumularEstadisticas(&jugador, incidencias)
 	
 	return jugador, nil
 }
+
+// acumularEstadisticas cuenta los goles, asistencias y tarjetas de un jugador a partir de sus
+// incidencias. Se considera que jugó todos los partidos en los que tiene alguna incidencia.
+func acumularEstadisticas(jugador *models.Jugador, incidencias []models.Incidencia) {
+	partidos := make(map[uint]bool)
+	for _, incidencia := range incidencias {
+		partidos[incidencia.PartidoID] = true
+		switch incidencia.Tipo {
+		case models.Gol, models.GolPenal:
+			jugador.Goles++
+		case models.Asistencia:
+			jugador.Asistencias++
+		case models.TarjetaAmarilla:
+			jugador.TarjetasAmarillas++
+		case models.TarjetaRoja:
+			jugador.TarjetasRojas++
+		}
+	}
+	jugador.PartidosJugados = len(partidos)
+}
+
+// minutosPartido es la duración reglamentaria de un partido, sin el tiempo añadido
+const minutosPartido = 90
+
+// PartidoJugador resume la participación de un jugador en un partido
+type PartidoJugador struct {
+	PartidoID         uint      `json:"partidoId"`
+	JornadaID         uint      `json:"jornadaId"`
+	FechaHora         time.Time `json:"fechaHora"`
+	Temporada         int       `json:"temporada"`
+	RivalID           uint      `json:"rivalId"`
+	Rival             string    `json:"rival"`
+	Local             bool      `json:"local"`
+	GolesFavor        int       `json:"golesFavor"`
+	GolesContra       int       `json:"golesContra"`
+	Resultado         string    `json:"resultado"` // V, E, D o vacío si el partido no ha finalizado
+	Minutos           int       `json:"minutos"` // 0 si el partido no ha finalizado
+	Goles             int       `json:"goles"`
+	Asistencias       int       `json:"asistencias"`
+	TarjetasAmarillas int       `json:"tarjetasAmarillas"`
+	TarjetasRojas     int       `json:"tarjetasRojas"`
+}
+
+// SplitJugador acumula las estadísticas de un jugador en un subconjunto de partidos
+type SplitJugador struct {
+	PJ                int `json:"pj"`
+	PG                int `json:"pg"`
+	PE                int `json:"pe"`
+	PP                int `json:"pp"`
+	Minutos           int `json:"minutos"`
+	Goles             int `json:"goles"`
+	Asistencias       int `json:"asistencias"`
+	TarjetasAmarillas int `json:"tarjetasAmarillas"`
+	TarjetasRojas     int `json:"tarjetasRojas"`
+}
+
+// SplitTemporada agrupa las estadísticas de un jugador por torneo.
+// Mientras no exista un modelo de torneo, cada torneo corresponde al año del partido.
+type SplitTemporada struct {
+	Temporada int `json:"temporada"`
+	SplitJugador
+}
+
+// RegistroPartidosJugador contiene el historial de partidos de un jugador y sus splits
+type RegistroPartidosJugador struct {
+	Jugador   models.Jugador   `json:"jugador"`
+	Partidos  []PartidoJugador `json:"partidos"`
+	Local     SplitJugador     `json:"local"`
+	Visitante SplitJugador     `json:"visitante"`
+	PorTorneo []SplitTemporada `json:"porTorneo"`
+}
+
+// GetPartidosJugador obtiene todos los partidos en los que participó un jugador,
+// junto con sus estadísticas separadas por condición de local/visitante y por torneo.
+// Las alineaciones no se registran, así que los partidos y los minutos salen solo de las
+// incidencias: un titular que jugó sin ninguna incidencia no aparece en el historial.
+func (s *JugadorService) GetPartidosJugador(jugadorID uint) (RegistroPartidosJugador, error) {
+	var registro RegistroPartidosJugador
+
+	jugador, err := s.GetJugadorByID(jugadorID)
+	if err != nil {
+		return registro, err
+	}
+	registro.Jugador = jugador
+
+	// Un jugador participó en un partido si tiene al menos una incidencia en él o si entró
+	// al campo en una sustitución
+	incidencias, err := s.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{Participante: jugadorID})
+	if err != nil {
+		return registro, err
+	}
+	sort.SliceStable(incidencias, func(i, j int) bool {
+		if incidencias[i].PartidoID != incidencias[j].PartidoID {
+			return incidencias[i].PartidoID < incidencias[j].PartidoID
+		}
+		return incidencias[i].Minuto < incidencias[j].Minuto
+	})
+
+	incidenciasPorPartido := make(map[uint][]models.Incidencia)
+	var partidoIDs []uint
+	for _, incidencia := range incidencias {
+		if _, ok := incidenciasPorPartido[incidencia.PartidoID]; !ok {
+			partidoIDs = append(partidoIDs, incidencia.PartidoID)
+		}
+		incidenciasPorPartido[incidencia.PartidoID] = append(incidenciasPorPartido[incidencia.PartidoID], incidencia)
+	}
+
+	registro.Partidos = []PartidoJugador{}
+	registro.PorTorneo = []SplitTemporada{}
+	if len(partidoIDs) == 0 {
+		return registro, nil
+	}
+
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{IDs: partidoIDs})
+	if err != nil {
+		return registro, err
+	}
+
+	// El lado de la cancha sale del equipo guardado en las incidencias, que no cambia si el
+	// jugador es traspasado. Las incidencias sin equipo usan el equipo actual del jugador.
+	locales := make(map[uint]bool, len(partidos))
+	var rivalIDs []uint
+	for _, partido := range partidos {
+		equipoID := jugador.EquipoID
+		for _, incidencia := range incidenciasPorPartido[partido.ID] {
+			if incidencia.EquipoID != 0 {
+				equipoID = incidencia.EquipoID
+				break
+			}
+		}
+		locales[partido.ID] = partido.EquipoLocalID == equipoID
+		if locales[partido.ID] {
+			rivalIDs = append(rivalIDs, partido.EquipoVisitanteID)
+		} else {
+			rivalIDs = append(rivalIDs, partido.EquipoLocalID)
+		}
+	}
+	rivales, err := s.Equipos.ObtenerVarios(rivalIDs)
+	if err != nil {
+		return registro, err
+	}
+	nombres := make(map[uint]string, len(rivales))
+	for _, rival := range rivales {
+		nombres[rival.ID] = rival.Nombre
+	}
+
+	temporadas := make(map[int]*SplitTemporada)
+	for _, partido := range partidos {
+		entrada := PartidoJugador{
+			PartidoID: partido.ID,
+			JornadaID: partido.JornadaID,
+			FechaHora: partido.FechaHora,
+			Temporada: partido.FechaHora.Year(),
+			Local:     locales[partido.ID],
+		}
+
+		if entrada.Local {
+			entrada.RivalID = partido.EquipoVisitanteID
+			entrada.GolesFavor = partido.GolesLocal
+			entrada.GolesContra = partido.GolesVisitante
+		} else {
+			entrada.RivalID = partido.EquipoLocalID
+			entrada.GolesFavor = partido.GolesVisitante
+			entrada.GolesContra = partido.GolesLocal
+		}
+		entrada.Rival = nombres[entrada.RivalID]
+
+		// El jugador está en el campo desde el inicio o desde que entra en una sustitución,
+		// y hasta el final, su expulsión o su salida en otra sustitución
+		entra, sale := 0, minutosPartido
+		for _, incidencia := range incidenciasPorPartido[partido.ID] {
+			if incidencia.JugadorID != jugadorID {
+				entra = incidencia.Minuto
+				continue
+			}
+			switch incidencia.Tipo {
+			case models.Gol, models.GolPenal:
+				entrada.Goles++
+			case models.Asistencia:
+				entrada.Asistencias++
+			case models.TarjetaAmarilla:
+				entrada.TarjetasAmarillas++
+			case models.TarjetaRoja:
+				entrada.TarjetasRojas++
+				sale = incidencia.Minuto
+			case models.Sustitucion:
+				sale = incidencia.Minuto
+			}
+		}
+
+		// Los minutos solo se cuentan cuando el partido terminó
+		if strings.EqualFold(partido.Estado, "finalizado") {
+			entrada.Resultado = resultadoPartido(entrada.GolesFavor, entrada.GolesContra)
+			entrada.Minutos = max(min(sale, minutosPartido)-entra, 0)
+		}
+
+		registro.Partidos = append(registro.Partidos, entrada)
+
+		if entrada.Local {
+			registro.Local.agregar(entrada)
+		} else {
+			registro.Visitante.agregar(entrada)
+		}
+
+		split, ok := temporadas[entrada.Temporada]
+		if !ok {
+			split = &SplitTemporada{Temporada: entrada.Temporada}
+			temporadas[entrada.Temporada] = split
+		}
+		split.agregar(entrada)
+	}
+
+	for _, split := range temporadas {
+		registro.PorTorneo = append(registro.PorTorneo, *split)
+	}
+	sort.Slice(registro.PorTorneo, func(i, j int) bool {
+		return registro.PorTorneo[i].Temporada < registro.PorTorneo[j].Temporada
+	})
+
+	return registro, nil
+}
+
+// agregar suma la participación en un partido al split
+func (s *SplitJugador) agregar(partido PartidoJugador) {
+	s.PJ++
+	switch partido.Resultado {
+	case "V":
+		s.PG++
+	case "E":
+		s.PE++
+	case "D":
+		s.PP++
+	}
+	if partido.Resultado != "" {
+		s.Minutos += partido.Minutos
+	}
+	s.Goles += partido.Goles
+	s.Asistencias += partido.Asistencias
+	s.TarjetasAmarillas += partido.TarjetasAmarillas
+	s.TarjetasRojas += partido.TarjetasRojas
+}
+
+// resultadoPartido devuelve V, E o D según los goles a favor y en contra
+func resultadoPartido(golesFavor, golesContra int) string {
+	if golesFavor > golesContra {
+		return "V"
+	} else if golesFavor < golesContra {
+		return "D"
+	}
+	return "E"
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+// partidoEnMemoria crea en repositorios en memoria dos equipos con un jugador cada uno y un
+// partido pendiente entre ellos
+func partidoEnMemoria(t *testing.T) (repositorios.Repositorios, models.Partido, models.Jugador) {
+	t.Helper()
+	repos := repositorios.NewEnMemoria()
+	local := models.Equipo{Nombre: "Águilas"}
+	visitante := models.Equipo{Nombre: "Búhos"}
+	for _, equipo := range []*models.Equipo{&local, &visitante} {
+		if err := repos.Equipos.Crear(equipo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jugador := models.Jugador{Nombre: "Ana", Apellido: "Ríos", EquipoID: visitante.ID}
+	if err := repos.Jugadores.Crear(&jugador); err != nil {
+		t.Fatal(err)
+	}
+	jornada := models.Jornada{Numero: 1}
+	if err := repos.Calendario.CrearJornada(&jornada); err != nil {
+		t.Fatal(err)
+	}
+	partido := models.Partido{
+		JornadaID:         jornada.ID,
+		EquipoLocalID:     local.ID,
+		EquipoVisitanteID: visitante.ID,
+		Estado:            models.EstadoPendiente,
+	}
+	if err := repos.Calendario.CrearPartido(&partido); err != nil {
+		t.Fatal(err)
+	}
+	return repos, partido, jugador
+}
+
+func TestRegistrarIncidenciaEnMemoria(t *testing.T) {
+	repos, partido, jugador := partidoEnMemoria(t)
+	service := NewCalendarioServiceConRepositorios(repos)
+
+	incidencia, err := service.RegistrarIncidencia(models.Incidencia{PartidoID: partido.ID, JugadorID: jugador.ID, Tipo: models.Gol, Minuto: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if incidencia.EquipoID != jugador.EquipoID || incidencia.Secuencia != 1 {
+		t.Errorf("incidencia con equipo %d y secuencia %d; se esperaba %d y 1", incidencia.EquipoID, incidencia.Secuencia, jugador.EquipoID)
+	}
+
+	// Un jugador inexistente no deja rastro en el partido
+	if _, err := service.RegistrarIncidencia(models.Incidencia{PartidoID: partido.ID, JugadorID: 99, Tipo: models.Gol}); !errors.Is(err, ErrJugadorIncidencia) {
+		t.Errorf("error = %v; se esperaba ErrJugadorIncidencia", err)
+	}
+	guardado, err := repos.Calendario.ObtenerPartido(partido.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incidencias, err := repos.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{PartidoIDs: []uint{partido.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guardado.Secuencia != 1 || len(incidencias) != 1 {
+		t.Errorf("partido en secuencia %d con %d incidencias; se esperaba 1 y 1", guardado.Secuencia, len(incidencias))
+	}
+}
+
+func TestRegistrarIncidenciaDeJugadorAjeno(t *testing.T) {
+	repos, partido, jugador := partidoEnMemoria(t)
+	ajeno := models.Equipo{Nombre: "Cóndores"}
+	if err := repos.Equipos.Crear(&ajeno); err != nil {
+		t.Fatal(err)
+	}
+	suplente := models.Jugador{Nombre: "Eva", Apellido: "Gil", EquipoID: jugador.EquipoID}
+	local := models.Jugador{Nombre: "Iván", Apellido: "Paz", EquipoID: partido.EquipoLocalID}
+	otro := models.Jugador{Nombre: "Luis", Apellido: "Mora", EquipoID: ajeno.ID}
+	for _, j := range []*models.Jugador{&suplente, &local, &otro} {
+		if err := repos.Jugadores.Crear(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	casos := []struct {
+		nombre         string
+		jugadorID      uint
+		jugadorEntraID uint
+		err            error
+	}{
+		{"jugador del partido", jugador.ID, 0, nil},
+		{"jugador de otro equipo", otro.ID, 0, ErrJugadorAjeno},
+		{"sustitucion del mismo equipo", jugador.ID, suplente.ID, nil},
+		{"entra un jugador de otro equipo", jugador.ID, otro.ID, ErrJugadorAjeno},
+		{"entra un jugador del rival", jugador.ID, local.ID, ErrJugadorAjeno},
+		{"entra un jugador inexistente", jugador.ID, 99, ErrJugadorIncidencia},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			incidencia := models.Incidencia{PartidoID: partido.ID, JugadorID: caso.jugadorID, Tipo: models.TarjetaAmarilla}
+			if caso.jugadorEntraID != 0 {
+				incidencia.Tipo, incidencia.JugadorEntraID = models.Sustitucion, &caso.jugadorEntraID
+			}
+			antes, err := repos.Calendario.ObtenerPartido(partido.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = NewCalendarioServiceConRepositorios(repos).RegistrarIncidencia(incidencia)
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("error = %v; se esperaba %v", err, caso.err)
+			}
+			despues, err := repos.Calendario.ObtenerPartido(partido.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if caso.err != nil && despues.Secuencia != antes.Secuencia {
+				t.Errorf("el partido pasó de la secuencia %d a la %d con una incidencia rechazada", antes.Secuencia, despues.Secuencia)
+			}
+		})
+	}
+}
+
+func TestRegistrarIncidenciaEnSecuenciaEnMemoria(t *testing.T) {
+	repos, partido, jugador := partidoEnMemoria(t)
+	service := NewCalendarioServiceConRepositorios(repos)
+
+	// Dos operadores que conocían el partido en la secuencia 0 registran la misma amarilla
+	amarilla := models.Incidencia{PartidoID: partido.ID, JugadorID: jugador.ID, Tipo: models.TarjetaAmarilla, Minuto: 30}
+	if _, err := service.RegistrarIncidenciaEnSecuencia(amarilla, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.RegistrarIncidenciaEnSecuencia(amarilla, 0); !errors.Is(err, ErrConflictoPartido) {
+		t.Errorf("error = %v; se esperaba ErrConflictoPartido", err)
+	}
+
+	// Una incidencia de otro tipo no es un duplicado
+	gol := models.Incidencia{PartidoID: partido.ID, JugadorID: jugador.ID, Tipo: models.Gol, Minuto: 31}
+	if _, err := service.RegistrarIncidenciaEnSecuencia(gol, 0); err != nil {
+		t.Errorf("error = %v; se esperaba que se registrara el gol", err)
+	}
+}
+
+func TestSembrarDemoEnMemoria(t *testing.T) {
+	ahora := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	sembrar := func() repositorios.Repositorios {
+		repos := repositorios.NewEnMemoria()
+		if _, err := NewSemillaServiceConRepositorios(repos).Sembrar("demo", ahora); err != nil {
+			t.Fatal(err)
+		}
+		return repos
+	}
+	primera, segunda := sembrar(), sembrar()
+
+	incidencias, err := primera.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repetidas, err := segunda.Calendario.BuscarIncidencias(repositorios.FiltroIncidencias{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidencias) == 0 || !reflect.DeepEqual(incidencias, repetidas) {
+		t.Fatalf("la simulación de la semilla no es reproducible: %d y %d incidencias", len(incidencias), len(repetidas))
+	}
+
+	// El marcador de cada partido jugado coincide con sus goles registrados
+	jugados, err := primera.Calendario.BuscarPartidos(repositorios.FiltroPartidos{Estado: models.EstadoFinalizado})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jugados) == 0 {
+		t.Fatal("la semilla no simuló ningún partido")
+	}
+	goles := make(map[uint][2]int)
+	for _, incidencia := range incidencias {
+		marcador := goles[incidencia.PartidoID]
+		switch incidencia.Tipo {
+		case models.Gol, models.GolPenal:
+			if partido, _ := primera.Calendario.ObtenerPartido(incidencia.PartidoID); partido.EquipoLocalID == incidencia.EquipoID {
+				marcador[0]++
+			} else {
+				marcador[1]++
+			}
+		case models.GolEnContra:
+			if partido, _ := primera.Calendario.ObtenerPartido(incidencia.PartidoID); partido.EquipoLocalID == incidencia.EquipoID {
+				marcador[1]++
+			} else {
+				marcador[0]++
+			}
+		}
+		goles[incidencia.PartidoID] = marcador
+	}
+	for _, partido := range jugados {
+		if marcador := goles[partido.ID]; marcador != [2]int{partido.GolesLocal, partido.GolesVisitante} {
+			t.Errorf("partido %d terminó %d-%d pero sus goles suman %d-%d",
+				partido.ID, partido.GolesLocal, partido.GolesVisitante, marcador[0], marcador[1])
+		}
+	}
+
+	// Cada equipo juega en un estadio registrado y los partidos jugados tienen público
+	equipos, err := primera.Equipos.Listar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, equipo := range equipos {
+		if equipo.EstadioID == nil {
+			t.Errorf("%s no tiene estadio", equipo.Nombre)
+		}
+	}
+	for _, partido := range jugados {
+		if partido.Espectadores == nil || *partido.Espectadores <= 0 {
+			t.Errorf("el partido %d no tiene público", partido.ID)
+		}
+	}
+	asistencia, err := NewCalendarioServiceConRepositorios(primera).GetAsistenciaEstadios()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asistencia) == 0 || asistencia[0].Promedio == 0 {
+		t.Errorf("asistencia por estadio %+v; se esperaba la de los partidos jugados", asistencia)
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+func TestIniciarPeriodoSegunElEstado(t *testing.T) {
+	casos := []struct {
+		estado, periodo string
+		err             error
+		siguiente       string
+	}{
+		{models.EstadoPendiente, "", nil, models.PeriodoPrimerTiempo},
+		{models.EstadoEnCurso, models.PeriodoDescanso, nil, models.PeriodoSegundoTiempo},
+		{models.EstadoEnCurso, models.PeriodoPrimerTiempo, ErrTransicionPeriodo, ""},
+		{models.EstadoEnCurso, "", ErrTransicionPeriodo, ""},
+		{models.EstadoFinalizado, "", ErrTransicionPeriodo, ""},
+		{models.EstadoAplazado, "", ErrTransicionPeriodo, ""},
+	}
+	for _, caso := range casos {
+		t.Run(caso.estado+"/"+caso.periodo, func(t *testing.T) {
+			repos, partido, _ := partidoEnMemoria(t)
+			partido.Estado, partido.Periodo = caso.estado, caso.periodo
+			if err := repos.Calendario.GuardarPartido(&partido); err != nil {
+				t.Fatal(err)
+			}
+
+			iniciado, err := NewCalendarioServiceConRepositorios(repos).IniciarPeriodo(partido.ID)
+			if !errors.Is(err, caso.err) {
+				t.Fatalf("error = %v; se esperaba %v", err, caso.err)
+			}
+			guardado, err := repos.Calendario.ObtenerPartido(partido.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if caso.err != nil {
+				if guardado.Estado != caso.estado || guardado.Periodo != caso.periodo {
+					t.Errorf("el partido quedó %s en %q; se esperaba que no cambiara", guardado.Estado, guardado.Periodo)
+				}
+				return
+			}
+			if iniciado.Periodo != caso.siguiente || guardado.Estado != models.EstadoEnCurso {
+				t.Errorf("el partido quedó %s en %q; se esperaba en curso en %q", guardado.Estado, iniciado.Periodo, caso.siguiente)
+			}
+		})
+	}
+}
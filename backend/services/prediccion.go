@@ -0,0 +1,449 @@
+package services
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"gorm.io/gorm"
+)
+
+const (
+	// partidosPrevios son los partidos de media del torneo que se suman a los de cada equipo
+	// al calcular su fuerza, para que pocos resultados no den valoraciones extremas
+	partidosPrevios = 5
+	// golesMaximosPrediccion es el marcador más alto de cada equipo que se considera al
+	// calcular las probabilidades de un partido
+	golesMaximosPrediccion = 10
+
+	// SimulacionesPorDefecto es la cantidad de temporadas que se simulan en la proyección
+	SimulacionesPorDefecto = 10000
+	// SimulacionesMaximas limita las temporadas que se pueden pedir en la proyección, que es
+	// pública y se calcula en cada petición que no encuentra en proyeccionesCalculadas
+	SimulacionesMaximas = 10000
+	// ClasificadosPorDefecto son los primeros puestos de la tabla que clasifican a la fase final
+	ClasificadosPorDefecto = 8
+	// proyeccionesGuardadas es la cantidad de proyecciones que se conservan ya calculadas
+	proyeccionesGuardadas = 64
+)
+
+// ErrOpcionesProyeccion indica que la cantidad de simulaciones o de clasificados no es válida
+var ErrOpcionesProyeccion = errors.New("opciones de proyección inválidas")
+
+// PrediccionPartido estima el resultado de un partido con el modelo de Poisson
+type PrediccionPartido struct {
+	PartidoID               uint      `json:"partidoId"`
+	JornadaID               uint      `json:"jornadaId"`
+	FechaHora               time.Time `json:"fechaHora"`
+	EquipoLocalID           uint      `json:"equipoLocalId"`
+	EquipoLocal             string    `json:"equipoLocal"`
+	EquipoVisitanteID       uint      `json:"equipoVisitanteId"`
+	EquipoVisitante         string    `json:"equipoVisitante"`
+	GolesEsperadosLocal     float64   `json:"golesEsperadosLocal"`
+	GolesEsperadosVisitante float64   `json:"golesEsperadosVisitante"`
+	ProbabilidadLocal       float64   `json:"probabilidadLocal"`
+	ProbabilidadEmpate      float64   `json:"probabilidadEmpate"`
+	ProbabilidadVisitante   float64   `json:"probabilidadVisitante"`
+	MarcadorProbable        string    `json:"marcadorProbable"` // Ej: "1-0"
+}
+
+// OpcionesProyeccion configura la proyección de la tabla final
+type OpcionesProyeccion struct {
+	Simulaciones int   // Temporadas que se simulan; 0 usa SimulacionesPorDefecto
+	Clasificados int   // Puestos que clasifican; 0 usa ClasificadosPorDefecto
+	Semilla      int64 // La misma semilla con los mismos resultados da la misma proyección
+}
+
+// ProyeccionEquipo resume las posiciones finales de un equipo en las temporadas simuladas
+type ProyeccionEquipo struct {
+	EquipoID                  uint      `json:"equipoId"`
+	Nombre                    string    `json:"nombre"`
+	PosicionActual            int       `json:"posicionActual"`
+	Puntos                    int       `json:"puntos"`
+	PuntosEsperados           float64   `json:"puntosEsperados"`
+	PosicionMedia             float64   `json:"posicionMedia"`
+	ProbabilidadClasificacion float64   `json:"probabilidadClasificacion"`
+	ProbabilidadPosiciones    []float64 `json:"probabilidadPosiciones"` // El primer valor es el del primer puesto
+}
+
+// ProyeccionTemporada es la proyección de la tabla final, ordenada por la tabla actual
+type ProyeccionTemporada struct {
+	Simulaciones      int                `json:"simulaciones"`
+	Clasificados      int                `json:"clasificados"`
+	Semilla           int64              `json:"semilla"`
+	PartidosRestantes int                `json:"partidosRestantes"`
+	Equipos           []ProyeccionEquipo `json:"equipos"`
+}
+
+// proyeccionesCalculadas guarda las últimas proyecciones calculadas. La clave resume la tabla,
+// las medias de goles de los partidos restantes y las opciones, así que una proyección deja de
+// usarse en cuanto cambia un resultado y la misma semilla sigue dando la misma proyección.
+var proyeccionesCalculadas = struct {
+	sync.Mutex
+	porClave map[[sha256.Size]byte]ProyeccionTemporada
+}{porClave: make(map[[sha256.Size]byte]ProyeccionTemporada)}
+
+// copiar duplica los equipos de la proyección para que quien la recibe no modifique la guardada
+func (p ProyeccionTemporada) copiar() ProyeccionTemporada {
+	equipos := make([]ProyeccionEquipo, len(p.Equipos))
+	for i, equipo := range p.Equipos {
+		equipo.ProbabilidadPosiciones = append([]float64(nil), equipo.ProbabilidadPosiciones...)
+		equipos[i] = equipo
+	}
+	p.Equipos = equipos
+	return p
+}
+
+// PrediccionService predice partidos y proyecta la tabla final a partir de los resultados.
+// Cada equipo tiene una fuerza de ataque y de defensa según los goles que marcó y recibió
+// comparados con la media del torneo, y los goles de un partido siguen una distribución de
+// Poisson con la media que dan esas fuerzas y la ventaja de jugar en casa.
+type PrediccionService struct {
+	repositorios.Repositorios
+}
+
+// NewPrediccionService crea una nueva instancia del servicio de predicciones
+func NewPrediccionService(db *gorm.DB) *PrediccionService {
+	return NewPrediccionServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewPrediccionServiceConRepositorios crea el servicio de predicciones sobre los repositorios indicados
+func NewPrediccionServiceConRepositorios(repos repositorios.Repositorios) *PrediccionService {
+	return &PrediccionService{
+		Repositorios: repos,
+	}
+}
+
+// modeloPrediccion tiene las medias de goles del torneo y la fuerza de cada equipo
+type modeloPrediccion struct {
+	mediaLocal     float64
+	mediaVisitante float64
+	fuerzas        map[uint]fuerzaEquipo
+	tabla          []models.Equipo // Tabla de posiciones actual
+}
+
+// cargarModelo calcula la fuerza de cada equipo con sus estadísticas en la tabla de posiciones
+func (s *PrediccionService) cargarModelo() (modeloPrediccion, error) {
+	var modelo modeloPrediccion
+	tabla, err := NewEquipoServiceConRepositorios(s.Repositorios).GetTablaPosiciones(0)
+	if err != nil {
+		return modelo, err
+	}
+	finalizados, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{Estado: models.EstadoFinalizado})
+	if err != nil {
+		return modelo, err
+	}
+
+	// Las medias del torneo parten de las del simulador y se ajustan con los resultados reales
+	golesLocal, golesVisitante := 0, 0
+	for _, partido := range finalizados {
+		golesLocal += partido.GolesLocal
+		golesVisitante += partido.GolesVisitante
+	}
+	jugados := float64(len(finalizados))
+	modelo.mediaLocal = (float64(golesLocal) + partidosPrevios*mediaGolesLocal) / (jugados + partidosPrevios)
+	modelo.mediaVisitante = (float64(golesVisitante) + partidosPrevios*mediaGolesVisitante) / (jugados + partidosPrevios)
+	modelo.tabla = tabla
+
+	media := (modelo.mediaLocal + modelo.mediaVisitante) / 2
+	modelo.fuerzas = make(map[uint]fuerzaEquipo, len(tabla))
+	for _, equipo := range tabla {
+		previos := partidosPrevios * media
+		partidos := float64(equipo.PJ+partidosPrevios) * media
+		modelo.fuerzas[equipo.ID] = fuerzaEquipo{
+			ataque:  (float64(equipo.GF) + previos) / partidos,
+			defensa: (float64(equipo.GC) + previos) / partidos,
+		}
+	}
+	return modelo, nil
+}
+
+// golesEsperados devuelve la media de goles del local y del visitante en un partido
+func (m modeloPrediccion) golesEsperados(partido models.Partido) (float64, float64) {
+	local, visitante := m.fuerza(partido.EquipoLocalID), m.fuerza(partido.EquipoVisitanteID)
+	return m.mediaLocal * local.ataque * visitante.defensa, m.mediaVisitante * visitante.ataque * local.defensa
+}
+
+// fuerza devuelve la fuerza de un equipo; un equipo sin estadísticas es un equipo medio
+func (m modeloPrediccion) fuerza(equipoID uint) fuerzaEquipo {
+	if fuerza, ok := m.fuerzas[equipoID]; ok {
+		return fuerza
+	}
+	return fuerzaEquipo{ataque: 1, defensa: 1}
+}
+
+// predecir calcula las probabilidades de cada resultado de un partido
+func (m modeloPrediccion) predecir(partido models.Partido, nombres map[uint]string) PrediccionPartido {
+	mediaLocal, mediaVisitante := m.golesEsperados(partido)
+	prediccion := PrediccionPartido{
+		PartidoID:               partido.ID,
+		JornadaID:               partido.JornadaID,
+		FechaHora:               partido.FechaHora,
+		EquipoLocalID:           partido.EquipoLocalID,
+		EquipoLocal:             nombres[partido.EquipoLocalID],
+		EquipoVisitanteID:       partido.EquipoVisitanteID,
+		EquipoVisitante:         nombres[partido.EquipoVisitanteID],
+		GolesEsperadosLocal:     redondearProbabilidad(mediaLocal),
+		GolesEsperadosVisitante: redondearProbabilidad(mediaVisitante),
+	}
+
+	local := probabilidadesPoisson(mediaLocal)
+	visitante := probabilidadesPoisson(mediaVisitante)
+	total, mejor := 0.0, 0.0
+	var gana, empata, pierde float64
+	for gl, pl := range local {
+		for gv, pv := range visitante {
+			p := pl * pv
+			total += p
+			switch {
+			case gl > gv:
+				gana += p
+			case gl == gv:
+				empata += p
+			default:
+				pierde += p
+			}
+			if p > mejor {
+				mejor = p
+				prediccion.MarcadorProbable = fmt.Sprintf("%d-%d", gl, gv)
+			}
+		}
+	}
+	// Los marcadores que no se consideran se reparten en proporción
+	prediccion.ProbabilidadLocal = redondearProbabilidad(gana / total)
+	prediccion.ProbabilidadEmpate = redondearProbabilidad(empata / total)
+	prediccion.ProbabilidadVisitante = redondearProbabilidad(pierde / total)
+	return prediccion
+}
+
+// probabilidadesPoisson devuelve la probabilidad de marcar de 0 a golesMaximosPrediccion goles
+func probabilidadesPoisson(media float64) []float64 {
+	probabilidades := make([]float64, golesMaximosPrediccion+1)
+	probabilidades[0] = math.Exp(-media)
+	for goles := 1; goles <= golesMaximosPrediccion; goles++ {
+		probabilidades[goles] = probabilidades[goles-1] * media / float64(goles)
+	}
+	return probabilidades
+}
+
+// redondearProbabilidad deja cuatro decimales
+func redondearProbabilidad(valor float64) float64 {
+	return math.Round(valor*10000) / 10000
+}
+
+// nombresEquipos devuelve el nombre de cada equipo de la tabla por su ID
+func (m modeloPrediccion) nombresEquipos() map[uint]string {
+	nombres := make(map[uint]string, len(m.tabla))
+	for _, equipo := range m.tabla {
+		nombres[equipo.ID] = equipo.Nombre
+	}
+	return nombres
+}
+
+// PredecirPartidos predice los partidos que faltan por jugar, en orden de fecha. Si se indica
+// el número de una jornada solo se predicen los de esa jornada.
+func (s *PrediccionService) PredecirPartidos(numeroJornada int) ([]PrediccionPartido, error) {
+	filtro := repositorios.FiltroPartidos{}
+	if numeroJornada > 0 {
+		jornada, err := s.Calendario.ObtenerJornadaPorNumero(numeroJornada)
+		if err != nil {
+			return nil, err
+		}
+		filtro.JornadaID = jornada.ID
+	}
+	partidos, err := s.Calendario.BuscarPartidos(filtro)
+	if err != nil {
+		return nil, err
+	}
+	modelo, err := s.cargarModelo()
+	if err != nil {
+		return nil, err
+	}
+
+	nombres := modelo.nombresEquipos()
+	predicciones := []PrediccionPartido{}
+	for _, partido := range partidos {
+		if partido.Estado == models.EstadoFinalizado {
+			continue
+		}
+		predicciones = append(predicciones, modelo.predecir(partido, nombres))
+	}
+	return predicciones, nil
+}
+
+// PredecirPartido predice un partido. También se puede pedir la de un partido finalizado,
+// con la fuerza que tienen los equipos ahora.
+func (s *PrediccionService) PredecirPartido(id uint) (PrediccionPartido, error) {
+	partido, err := s.Calendario.ObtenerPartido(id)
+	if err != nil {
+		return PrediccionPartido{}, err
+	}
+	modelo, err := s.cargarModelo()
+	if err != nil {
+		return PrediccionPartido{}, err
+	}
+	return modelo.predecir(partido, modelo.nombresEquipos()), nil
+}
+
+// ProyectarTemporada simula con el método de Monte Carlo los partidos que faltan por jugar y
+// calcula la probabilidad de que cada equipo termine en cada posición y de que clasifique. Las
+// posiciones se ordenan como en la tabla, por puntos, diferencia de goles y goles a favor, y
+// los empates que quedan se deciden al azar.
+func (s *PrediccionService) ProyectarTemporada(opciones OpcionesProyeccion) (ProyeccionTemporada, error) {
+	if opciones.Simulaciones == 0 {
+		opciones.Simulaciones = SimulacionesPorDefecto
+	}
+	if opciones.Clasificados == 0 {
+		opciones.Clasificados = ClasificadosPorDefecto
+	}
+	if opciones.Simulaciones < 0 || opciones.Simulaciones > SimulacionesMaximas || opciones.Clasificados < 0 {
+		return ProyeccionTemporada{}, ErrOpcionesProyeccion
+	}
+
+	modelo, err := s.cargarModelo()
+	if err != nil {
+		return ProyeccionTemporada{}, err
+	}
+	partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		return ProyeccionTemporada{}, err
+	}
+
+	// Cada equipo se identifica por su posición en la tabla actual
+	cantidad := len(modelo.tabla)
+	indices := make(map[uint]int, cantidad)
+	for i, equipo := range modelo.tabla {
+		indices[equipo.ID] = i
+	}
+	type partidoRestante struct {
+		local, visitante           int
+		mediaLocal, mediaVisitante float64
+	}
+	var restantes []partidoRestante
+	for _, partido := range partidos {
+		local, okLocal := indices[partido.EquipoLocalID]
+		visitante, okVisitante := indices[partido.EquipoVisitanteID]
+		if partido.Estado == models.EstadoFinalizado || !okLocal || !okVisitante {
+			continue
+		}
+		mediaLocal, mediaVisitante := modelo.golesEsperados(partido)
+		restantes = append(restantes, partidoRestante{local, visitante, mediaLocal, mediaVisitante})
+	}
+
+	proyeccion := ProyeccionTemporada{
+		Simulaciones:      opciones.Simulaciones,
+		Clasificados:      min(opciones.Clasificados, cantidad),
+		Semilla:           opciones.Semilla,
+		PartidosRestantes: len(restantes),
+		Equipos:           make([]ProyeccionEquipo, cantidad),
+	}
+	if cantidad == 0 {
+		return proyeccion, nil
+	}
+
+	// La simulación solo depende de la tabla, de los partidos restantes y de las opciones
+	huella := sha256.New()
+	fmt.Fprintln(huella, opciones.Simulaciones, proyeccion.Clasificados, opciones.Semilla)
+	for _, equipo := range modelo.tabla {
+		fmt.Fprintln(huella, equipo.ID, equipo.Nombre, equipo.Posicion, equipo.Puntos, equipo.DG, equipo.GF)
+	}
+	for _, partido := range restantes {
+		fmt.Fprintln(huella, partido.local, partido.visitante, partido.mediaLocal, partido.mediaVisitante)
+	}
+	var clave [sha256.Size]byte
+	huella.Sum(clave[:0])
+	proyeccionesCalculadas.Lock()
+	guardada, ok := proyeccionesCalculadas.porClave[clave]
+	proyeccionesCalculadas.Unlock()
+	if ok {
+		return guardada.copiar(), nil
+	}
+
+	r := rand.New(rand.NewSource(opciones.Semilla))
+	posiciones := make([][]int, cantidad) // Veces que cada equipo terminó en cada posición
+	for i := range posiciones {
+		posiciones[i] = make([]int, cantidad)
+	}
+	puntosTotales := make([]int, cantidad)
+	puntos, diferencia, golesFavor, desempate := make([]int, cantidad), make([]int, cantidad), make([]int, cantidad), make([]int, cantidad)
+	orden := make([]int, cantidad)
+
+	for simulacion := 0; simulacion < opciones.Simulaciones; simulacion++ {
+		for i, equipo := range modelo.tabla {
+			puntos[i], diferencia[i], golesFavor[i] = equipo.Puntos, equipo.DG, equipo.GF
+			desempate[i] = r.Int()
+			orden[i] = i
+		}
+		for _, partido := range restantes {
+			gl, gv := poisson(r, partido.mediaLocal), poisson(r, partido.mediaVisitante)
+			golesFavor[partido.local] += gl
+			golesFavor[partido.visitante] += gv
+			diferencia[partido.local] += gl - gv
+			diferencia[partido.visitante] += gv - gl
+			switch {
+			case gl > gv:
+				puntos[partido.local] += 3
+			case gl < gv:
+				puntos[partido.visitante] += 3
+			default:
+				puntos[partido.local]++
+				puntos[partido.visitante]++
+			}
+		}
+		sort.Slice(orden, func(a, b int) bool {
+			i, j := orden[a], orden[b]
+			if puntos[i] != puntos[j] {
+				return puntos[i] > puntos[j]
+			}
+			if diferencia[i] != diferencia[j] {
+				return diferencia[i] > diferencia[j]
+			}
+			if golesFavor[i] != golesFavor[j] {
+				return golesFavor[i] > golesFavor[j]
+			}
+			return desempate[i] > desempate[j]
+		})
+		for posicion, i := range orden {
+			posiciones[i][posicion]++
+			puntosTotales[i] += puntos[i]
+		}
+	}
+
+	total := float64(opciones.Simulaciones)
+	for i, equipo := range modelo.tabla {
+		resumen := ProyeccionEquipo{
+			EquipoID:               equipo.ID,
+			Nombre:                 equipo.Nombre,
+			PosicionActual:         equipo.Posicion,
+			Puntos:                 equipo.Puntos,
+			PuntosEsperados:        redondearProbabilidad(float64(puntosTotales[i]) / total),
+			ProbabilidadPosiciones: make([]float64, cantidad),
+		}
+		posicionMedia, clasificaciones := 0.0, 0
+		for posicion, veces := range posiciones[i] {
+			resumen.ProbabilidadPosiciones[posicion] = redondearProbabilidad(float64(veces) / total)
+			posicionMedia += float64((posicion+1)*veces) / total
+			if posicion < proyeccion.Clasificados {
+				clasificaciones += veces
+			}
+		}
+		resumen.PosicionMedia = redondearProbabilidad(posicionMedia)
+		resumen.ProbabilidadClasificacion = redondearProbabilidad(float64(clasificaciones) / total)
+		proyeccion.Equipos[i] = resumen
+	}
+
+	proyeccionesCalculadas.Lock()
+	if len(proyeccionesCalculadas.porClave) >= proyeccionesGuardadas {
+		clear(proyeccionesCalculadas.porClave)
+	}
+	proyeccionesCalculadas.porClave[clave] = proyeccion.copiar()
+	proyeccionesCalculadas.Unlock()
+	return proyeccion, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+func TestProyectarTemporadaGuardaLaProyeccion(t *testing.T) {
+	repos := repositorios.NewEnMemoria()
+	nombres := []string{"Águilas", "Búhos", "Cóndores", "Delfines"}
+	for _, nombre := range nombres {
+		if err := repos.Equipos.Crear(&models.Equipo{Nombre: nombre}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewCalendarioServiceConRepositorios(repos)
+	inicio := time.Date(2024, 8, 3, 0, 0, 0, 0, time.UTC)
+	if _, err := service.GenerarCalendario(OpcionesCalendario{FechaInicio: &inicio}); err != nil {
+		t.Fatal(err)
+	}
+	partidos, err := repos.Calendario.BuscarPartidos(repositorios.FiltroPartidos{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jugado := partidos[0]
+	jugado.Estado, jugado.GolesLocal, jugado.GolesVisitante = models.EstadoFinalizado, 2, 0
+	if err := repos.Calendario.GuardarPartido(&jugado); err != nil {
+		t.Fatal(err)
+	}
+
+	prediccion := NewPrediccionServiceConRepositorios(repos)
+	opciones := OpcionesProyeccion{Simulaciones: 500, Semilla: 7}
+	primera, err := prediccion.ProyectarTemporada(opciones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperada := primera.copiar()
+	primera.Equipos[0].ProbabilidadPosiciones[0] = -1 // No debe alterar la proyección guardada
+
+	guardadas := len(proyeccionesCalculadas.porClave)
+	segunda, err := prediccion.ProyectarTemporada(opciones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(segunda, esperada) || len(proyeccionesCalculadas.porClave) != guardadas {
+		t.Error("la misma tabla con las mismas opciones no reutilizó la proyección guardada")
+	}
+
+	// Otro resultado cambia la tabla y obliga a simular de nuevo
+	jugado.GolesLocal, jugado.GolesVisitante = 0, 2
+	if err := repos.Calendario.GuardarPartido(&jugado); err != nil {
+		t.Fatal(err)
+	}
+	tercera, err := prediccion.ProyectarTemporada(opciones)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(tercera, esperada) {
+		t.Error("la proyección no cambió después de cambiar un resultado")
+	}
+
+	if _, err := prediccion.ProyectarTemporada(OpcionesProyeccion{Simulaciones: SimulacionesMaximas + 1}); err != ErrOpcionesProyeccion {
+		t.Errorf("error = %v; se esperaba ErrOpcionesProyeccion", err)
+	}
+}
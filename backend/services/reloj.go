@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+// RelojPartido representa el minuto de juego de un partido en un instante dado
+type RelojPartido struct {
+	PartidoID     uint   `json:"partidoId"`
+	Periodo       string `json:"periodo"`
+	EnJuego       bool   `json:"enJuego"`
+	Minuto        int    `json:"minuto"`
+	MinutoAnadido int    `json:"minutoAnadido"`
+	TiempoAnadido int    `json:"tiempoAnadido"`
+	Texto         string `json:"texto"` // Ej: "45+2'"
+}
+
+// CalcularReloj calcula el minuto de juego de un partido a partir del inicio del periodo actual
+func CalcularReloj(partido models.Partido, ahora time.Time) RelojPartido {
+	reloj := RelojPartido{
+		PartidoID:     partido.ID,
+		Periodo:       partido.Periodo,
+		TiempoAnadido: partido.TiempoAnadido,
+	}
+
+	switch partido.Periodo {
+	case models.PeriodoPrimerTiempo, models.PeriodoSegundoTiempo:
+		reloj.EnJuego = true
+		base := 0
+		if partido.Periodo == models.PeriodoSegundoTiempo {
+			base = models.DuracionPeriodo
+		}
+
+		transcurrido := 0
+		if partido.InicioPeriodo != nil && ahora.After(*partido.InicioPeriodo) {
+			transcurrido = int(ahora.Sub(*partido.InicioPeriodo) / time.Minute)
+		}
+
+		// El minuto en curso se cuenta desde 1, como en las retransmisiones
+		minuto := transcurrido + 1
+		if minuto > models.DuracionPeriodo {
+			reloj.MinutoAnadido = minuto - models.DuracionPeriodo
+			minuto = models.DuracionPeriodo
+		}
+		reloj.Minuto = base + minuto
+	case models.PeriodoDescanso:
+		reloj.Minuto = models.DuracionPeriodo
+	case models.PeriodoFinal:
+		reloj.Minuto = models.DuracionPeriodo * 2
+	}
+
+	reloj.Texto = fmt.Sprintf("%d'", reloj.Minuto)
+	if reloj.MinutoAnadido > 0 {
+		reloj.Texto = fmt.Sprintf("%d+%d'", reloj.Minuto, reloj.MinutoAnadido)
+	}
+
+	return reloj
+}
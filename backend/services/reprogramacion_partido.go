@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+var (
+	// ErrPartidoNoReprogramable indica que el partido ya empezó o terminó y no se puede aplazar ni cambiar de fecha
+	ErrPartidoNoReprogramable = errors.New("solo se pueden aplazar o reprogramar partidos que no se han jugado")
+	// ErrJornadaInexistente indica que la jornada de destino no existe
+	ErrJornadaInexistente = errors.New("la jornada indicada no existe")
+)
+
+// ErrConflictoFecha indica que alguno de los equipos ya tiene otro partido el mismo día
+type ErrConflictoFecha struct {
+	EquipoID  uint
+	PartidoID uint // Partido con el que coincide
+}
+
+func (e *ErrConflictoFecha) Error() string {
+	return "uno de los equipos ya juega otro partido ese día"
+}
+
+// Reprogramacion son los datos de un cambio de fecha de un partido
+type Reprogramacion struct {
+	FechaHora time.Time
+	JornadaID uint // 0 para mantener la jornada actual
+	Motivo    string
+	Usuario   string
+}
+
+// AplazarPartido deja un partido pendiente en estado aplazado hasta que se le asigne una nueva fecha.
+// Si se indica la secuencia esperada y el partido ya cambió, devuelve ErrConflictoPartido.
+func (s *CalendarioService) AplazarPartido(partidoID uint, secuencia *uint, motivo, usuario string) (models.Partido, models.Reprogramacion, error) {
+	var partido models.Partido
+	var registro models.Reprogramacion
+	err := s.Calendario.Transaccion(func(tx repositorios.CalendarioRepositorio) error {
+		var err error
+		if partido, err = tx.ObtenerPartido(partidoID); err != nil {
+			return err
+		}
+		if secuencia != nil && partido.Secuencia != *secuencia {
+			return ErrConflictoPartido
+		}
+		if partido.Estado != models.EstadoPendiente {
+			return ErrPartidoNoReprogramable
+		}
+
+		registro = models.Reprogramacion{
+			PartidoID:         partido.ID,
+			EstadoAnterior:    partido.Estado,
+			EstadoNuevo:       models.EstadoAplazado,
+			FechaAnterior:     partido.FechaHora,
+			JornadaAnteriorID: partido.JornadaID,
+			JornadaNuevaID:    partido.JornadaID,
+			Motivo:            motivo,
+			Usuario:           usuario,
+		}
+
+		partido.Estado = models.EstadoAplazado
+		if err := guardarPartido(tx, &partido); err != nil {
+			return err
+		}
+		return tx.CrearReprogramacion(&registro)
+	})
+	if err != nil {
+		return partido, registro, err
+	}
+
+	publicarReprogramacion(partido, registro)
+	return partido, registro, nil
+}
+
+// ReprogramarPartido asigna una nueva fecha y hora, y opcionalmente otra jornada, a un partido
+// pendiente o aplazado, que vuelve a quedar pendiente. Ninguno de los dos equipos puede tener
+// otro partido ese mismo día; si lo tiene devuelve *ErrConflictoFecha.
+// Si se indica la secuencia esperada y el partido ya cambió, devuelve ErrConflictoPartido.
+func (s *CalendarioService) ReprogramarPartido(partidoID uint, secuencia *uint, cambio Reprogramacion) (models.Partido, models.Reprogramacion, error) {
+	var partido models.Partido
+	var registro models.Reprogramacion
+	err := s.Calendario.Transaccion(func(tx repositorios.CalendarioRepositorio) error {
+		var err error
+		if partido, err = tx.ObtenerPartido(partidoID); err != nil {
+			return err
+		}
+		if secuencia != nil && partido.Secuencia != *secuencia {
+			return ErrConflictoPartido
+		}
+		if partido.Estado != models.EstadoPendiente && partido.Estado != models.EstadoAplazado {
+			return ErrPartidoNoReprogramable
+		}
+
+		jornadaID := partido.JornadaID
+		if cambio.JornadaID != 0 {
+			if _, err := tx.ObtenerJornada(cambio.JornadaID); err != nil {
+				if errors.Is(err, repositorios.ErrNoEncontrado) {
+					return ErrJornadaInexistente
+				}
+				return err
+			}
+			jornadaID = cambio.JornadaID
+		}
+
+		if err := verificarDiaLibre(tx, partido, cambio.FechaHora, s.ZonaHoraria); err != nil {
+			return err
+		}
+
+		fechaNueva := cambio.FechaHora
+		registro = models.Reprogramacion{
+			PartidoID:         partido.ID,
+			EstadoAnterior:    partido.Estado,
+			EstadoNuevo:       models.EstadoPendiente,
+			FechaAnterior:     partido.FechaHora,
+			FechaNueva:        &fechaNueva,
+			JornadaAnteriorID: partido.JornadaID,
+			JornadaNuevaID:    jornadaID,
+			Motivo:            cambio.Motivo,
+			Usuario:           cambio.Usuario,
+		}
+
+		partido.Estado = models.EstadoPendiente
+		partido.FechaHora = cambio.FechaHora
+		partido.JornadaID = jornadaID
+		if err := guardarPartido(tx, &partido); err != nil {
+			return err
+		}
+		return tx.CrearReprogramacion(&registro)
+	})
+	if err != nil {
+		return partido, registro, err
+	}
+
+	publicarReprogramacion(partido, registro)
+	return partido, registro, nil
+}
+
+// GetReprogramaciones obtiene el historial de aplazamientos y cambios de fecha de un partido
+func (s *CalendarioService) GetReprogramaciones(partidoID uint) ([]models.Reprogramacion, error) {
+	if _, err := s.Calendario.ObtenerPartido(partidoID); err != nil {
+		return nil, err
+	}
+	return s.Calendario.BuscarReprogramaciones([]uint{partidoID})
+}
+
+// verificarDiaLibre comprueba que ninguno de los equipos del partido juegue otro partido el día
+// de fecha en la zona horaria de la liga, sin importar la zona con la que se envió la fecha.
+// Los partidos aplazados no ocupan su fecha original.
+func verificarDiaLibre(repo repositorios.CalendarioRepositorio, partido models.Partido, fecha time.Time, zona *time.Location) error {
+	if zona != nil {
+		fecha = fecha.In(zona)
+	}
+	desde := inicioDelDia(fecha)
+	hasta := desde.AddDate(0, 0, 1)
+
+	for _, equipoID := range []uint{partido.EquipoLocalID, partido.EquipoVisitanteID} {
+		partidos, err := repo.BuscarPartidos(repositorios.FiltroPartidos{
+			EquipoID: equipoID,
+			Desde:    &desde,
+			Hasta:    &hasta,
+		})
+		if err != nil {
+			return err
+		}
+		for _, otro := range partidos {
+			if otro.ID != partido.ID && otro.Estado != models.EstadoAplazado {
+				return &ErrConflictoFecha{EquipoID: equipoID, PartidoID: otro.ID}
+			}
+		}
+	}
+	return nil
+}
+
+// publicarReprogramacion avisa a los suscriptores del nuevo estado y fecha del partido
+func publicarReprogramacion(partido models.Partido, registro models.Reprogramacion) {
+	evento := NuevoEventoPartido(EventoReprogramacion, partido)
+	evento.Reprogramacion = &registro
+	EventosEnVivo.Publicar(evento)
+}
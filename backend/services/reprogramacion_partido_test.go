@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+)
+
+func TestReprogramarPartidoDiaDeLaLiga(t *testing.T) {
+	bogota, err := time.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := repositorios.NewEnMemoria()
+	var equipos [3]models.Equipo
+	for i := range equipos {
+		if err := repos.Equipos.Crear(&equipos[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jornada := models.Jornada{Numero: 1}
+	if err := repos.Calendario.CrearJornada(&jornada); err != nil {
+		t.Fatal(err)
+	}
+	// El primer equipo juega el sábado a las 8 de la noche en Colombia, que ya es domingo en UTC
+	jugado := models.Partido{
+		JornadaID:         jornada.ID,
+		EquipoLocalID:     equipos[0].ID,
+		EquipoVisitanteID: equipos[1].ID,
+		FechaHora:         time.Date(2024, 8, 10, 20, 0, 0, 0, bogota),
+		Estado:            models.EstadoPendiente,
+	}
+	aplazado := models.Partido{
+		JornadaID:         jornada.ID,
+		EquipoLocalID:     equipos[2].ID,
+		EquipoVisitanteID: equipos[0].ID,
+		Estado:            models.EstadoAplazado,
+	}
+	for _, partido := range []*models.Partido{&jugado, &aplazado} {
+		if err := repos.Calendario.CrearPartido(partido); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Las 11 de la noche del sábado en UTC son las 6 de la tarde del mismo sábado en Colombia
+	service := NewCalendarioServiceConRepositorios(repos)
+	service.ZonaHoraria = bogota
+	_, _, err = service.ReprogramarPartido(aplazado.ID, nil, Reprogramacion{FechaHora: time.Date(2024, 8, 10, 23, 0, 0, 0, time.UTC)})
+	var conflicto *ErrConflictoFecha
+	if !errors.As(err, &conflicto) || conflicto.PartidoID != jugado.ID {
+		t.Fatalf("error = %v; se esperaba un conflicto con el partido %d", err, jugado.ID)
+	}
+
+	// El domingo en Colombia el equipo está libre, aunque en UTC sea el mismo día del otro partido
+	domingo := time.Date(2024, 8, 11, 15, 0, 0, 0, bogota)
+	if _, _, err := service.ReprogramarPartido(aplazado.ID, nil, Reprogramacion{FechaHora: domingo}); err != nil {
+		t.Errorf("error = %v; se esperaba reprogramar el partido al domingo", err)
+	}
+}
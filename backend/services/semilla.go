@@ -0,0 +1,377 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"gorm.io/gorm"
+)
+
+// Conjuntos de datos de ejemplo que se pueden sembrar
+const (
+	SemillaEquipos = "equipos" // Solo los equipos del torneo
+	SemillaDemo    = "demo"    // Temporada de demostración: equipos, plantillas, calendario y resultados
+)
+
+// ConjuntosSemilla son los conjuntos de datos que se pueden sembrar
+var ConjuntosSemilla = []string{SemillaEquipos, SemillaDemo}
+
+// ErrConjuntoSemilla indica que no existe el conjunto de datos indicado
+var ErrConjuntoSemilla = errors.New("el conjunto de datos debe ser equipos o demo")
+
+// jugadoresPorEquipo es el tamaño de las plantillas de la temporada de demostración
+const jugadoresPorEquipo = 22
+
+// ResultadoSemilla cuenta los registros que creó o actualizó la siembra. Volver a sembrar
+// los mismos datos no cambia nada, así que todos los contadores quedan en cero.
+type ResultadoSemilla struct {
+	Conjunto              string `json:"conjunto"`
+	EstadiosCreados       int    `json:"estadiosCreados"`
+	EstadiosActualizados  int    `json:"estadiosActualizados"`
+	EquiposCreados        int    `json:"equiposCreados"`
+	EquiposActualizados   int    `json:"equiposActualizados"`
+	JugadoresCreados      int    `json:"jugadoresCreados"`
+	JugadoresActualizados int    `json:"jugadoresActualizados"`
+	JornadasCreadas       int    `json:"jornadasCreadas"`
+	ResultadosSimulados   int    `json:"resultadosSimulados"`
+}
+
+// estadiosSemilla son los estadios de los equipos del torneo
+var estadiosSemilla = []models.Estadio{
+	{Nombre: "Hernán Ramírez Villegas", Ciudad: "Pereira", Capacidad: 30297, Superficie: models.SuperficieNatural},
+	{Nombre: "Atanasio Girardot", Ciudad: "Medellín", Capacidad: 40043, Superficie: models.SuperficieNatural},
+	{Nombre: "El Campín", Ciudad: "Bogotá", Capacidad: 36343, Superficie: models.SuperficieHibrida},
+	{Nombre: "Pascual Guerrero", Ciudad: "Cali", Capacidad: 35405, Superficie: models.SuperficieNatural},
+	{Nombre: "Deportivo Cali", Ciudad: "Palmira", Capacidad: 42000, Superficie: models.SuperficieNatural},
+	{Nombre: "Metropolitano", Ciudad: "Barranquilla", Capacidad: 46692, Superficie: models.SuperficieNatural},
+	{Nombre: "Palogrande", Ciudad: "Manizales", Capacidad: 31611, Superficie: models.SuperficieNatural},
+	{Nombre: "Alfonso López", Ciudad: "Bucaramanga", Capacidad: 25000, Superficie: models.SuperficieNatural},
+	{Nombre: "Metropolitano de Techo", Ciudad: "Bogotá", Capacidad: 10000, Superficie: models.SuperficieArtificial},
+	{Nombre: "Polideportivo Sur", Ciudad: "Envigado", Capacidad: 11000, Superficie: models.SuperficieNatural},
+}
+
+// equiposSemilla son los equipos del torneo
+var equiposSemilla = []models.Equipo{
+	{Nombre: "Deportivo Pereira", NombreCorto: "Pereira", Ciudad: "Pereira", Estadio: "Hernán Ramírez Villegas", Fundacion: "1944", Escudo: "/escudos/pereira.png"},
+	{Nombre: "Atlético Nacional", NombreCorto: "Nacional", Ciudad: "Medellín", Estadio: "Atanasio Girardot", Fundacion: "1947", Escudo: "/escudos/nacional.png"},
+	{Nombre: "Millonarios FC", NombreCorto: "Millonarios", Ciudad: "Bogotá", Estadio: "El Campín", Fundacion: "1946", Escudo: "/escudos/millonarios.png"},
+	{Nombre: "América de Cali", NombreCorto: "América", Ciudad: "Cali", Estadio: "Pascual Guerrero", Fundacion: "1927", Escudo: "/escudos/america.png"},
+	{Nombre: "Independiente Santa Fe", NombreCorto: "Santa Fe", Ciudad: "Bogotá", Estadio: "El Campín", Fundacion: "1941", Escudo: "/escudos/santafe.png"},
+	{Nombre: "Deportivo Cali", NombreCorto: "Cali", Ciudad: "Cali", Estadio: "Deportivo Cali", Fundacion: "1912", Escudo: "/escudos/cali.png"},
+	{Nombre: "Junior FC", NombreCorto: "Junior", Ciudad: "Barranquilla", Estadio: "Metropolitano", Fundacion: "1924", Escudo: "/escudos/junior.png"},
+	{Nombre: "Independiente Medellín", NombreCorto: "Medellín", Ciudad: "Medellín", Estadio: "Atanasio Girardot", Fundacion: "1913", Escudo: "/escudos/medellin.png"},
+	{Nombre: "Once Caldas", NombreCorto: "Once Caldas", Ciudad: "Manizales", Estadio: "Palogrande", Fundacion: "1961", Escudo: "/escudos/oncecaldas.png"},
+	{Nombre: "Atlético Bucaramanga", NombreCorto: "Bucaramanga", Ciudad: "Bucaramanga", Estadio: "Alfonso López", Fundacion: "1949", Escudo: "/escudos/bucaramanga.png"},
+	{Nombre: "La Equidad", NombreCorto: "Equidad", Ciudad: "Bogotá", Estadio: "Metropolitano de Techo", Fundacion: "1982", Escudo: "/escudos/equidad.png"},
+	{Nombre: "Envigado FC", NombreCorto: "Envigado", Ciudad: "Envigado", Estadio: "Polideportivo Sur", Fundacion: "1989", Escudo: "/escudos/envigado.png"},
+}
+
+// Nombres con los que se generan los jugadores de la temporada de demostración
+var (
+	nombresSemilla = []string{
+		"Andrés", "Carlos", "Daniel", "David", "Diego", "Felipe", "Jhon", "Jorge", "José", "Juan",
+		"Julián", "Kevin", "Luis", "Mateo", "Miguel", "Nicolás", "Óscar", "Santiago", "Sebastián", "Yerry",
+	}
+	apellidosSemilla = []string{
+		"Arias", "Borja", "Castro", "Córdoba", "Díaz", "Gómez", "Hernández", "Lerma", "López", "Mina",
+		"Moreno", "Murillo", "Ospina", "Quintero", "Restrepo", "Ríos", "Rodríguez", "Sánchez", "Uribe", "Valencia",
+	}
+)
+
+// posicionesSemilla reparte las plantillas: 3 porteros, 7 defensas, 7 mediocampistas y 5 delanteros
+var posicionesSemilla = []struct {
+	posicion string
+	cantidad int
+}{
+	{"Portero", 3},
+	{"Defensa", 7},
+	{"Mediocampista", 7},
+	{"Delantero", 5},
+}
+
+// SemillaService siembra datos de ejemplo para desarrollo y demostraciones
+type SemillaService struct {
+	repositorios.Repositorios
+}
+
+// NewSemillaService crea una nueva instancia del servicio de siembra
+func NewSemillaService(db *gorm.DB) *SemillaService {
+	return NewSemillaServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewSemillaServiceConRepositorios crea el servicio de siembra sobre los repositorios indicados
+func NewSemillaServiceConRepositorios(repos repositorios.Repositorios) *SemillaService {
+	return &SemillaService{
+		Repositorios: repos,
+	}
+}
+
+// Sembrar crea o actualiza los datos del conjunto indicado. Los estadios y los equipos se
+// identifican por su nombre y los jugadores por su equipo, nombre y apellido, así que sembrar
+// varias veces no duplica registros. Los datos generados son siempre los mismos para que la temporada de
+// demostración se pueda reproducir; ahora solo decide qué partidos ya se jugaron.
+func (s *SemillaService) Sembrar(conjunto string, ahora time.Time) (ResultadoSemilla, error) {
+	resultado := ResultadoSemilla{Conjunto: conjunto}
+	if !contiene(ConjuntosSemilla, conjunto) {
+		return resultado, ErrConjuntoSemilla
+	}
+
+	estadios, err := s.sembrarEstadios(&resultado)
+	if err != nil {
+		return resultado, err
+	}
+	equipos, err := s.sembrarEquipos(estadios, &resultado)
+	if err != nil {
+		return resultado, err
+	}
+	if conjunto == SemillaEquipos {
+		return resultado, nil
+	}
+
+	if err := s.sembrarJugadores(equipos, &resultado); err != nil {
+		return resultado, err
+	}
+	if err := s.sembrarCalendario(len(equipos), ahora, &resultado); err != nil {
+		return resultado, err
+	}
+	if err := s.simularResultados(ahora, &resultado); err != nil {
+		return resultado, err
+	}
+	if err := s.sembrarAsistencia(equipos, estadios); err != nil {
+		return resultado, err
+	}
+	return resultado, nil
+}
+
+// sembrarEstadios crea los estadios que faltan, actualiza los que cambiaron y devuelve todos
+// los estadios registrados por su nombre normalizado
+func (s *SemillaService) sembrarEstadios(resultado *ResultadoSemilla) (map[string]models.Estadio, error) {
+	porNombre, err := indiceEstadios(s.Repositorios)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, semilla := range estadiosSemilla {
+		clave := normalizarTexto(semilla.Nombre)
+		estadio, ok := porNombre[clave]
+		if !ok {
+			estadio = semilla
+			if err := s.Estadios.Crear(&estadio); err != nil {
+				return nil, fmt.Errorf("error al crear el estadio %s: %w", semilla.Nombre, err)
+			}
+			resultado.EstadiosCreados++
+		} else if estadio.Ciudad != semilla.Ciudad || estadio.Capacidad != semilla.Capacidad || estadio.Superficie != semilla.Superficie {
+			estadio.Ciudad = semilla.Ciudad
+			estadio.Capacidad = semilla.Capacidad
+			estadio.Superficie = semilla.Superficie
+			if err := s.Estadios.Actualizar(&estadio); err != nil {
+				return nil, fmt.Errorf("error al actualizar el estadio %s: %w", semilla.Nombre, err)
+			}
+			resultado.EstadiosActualizados++
+		}
+		porNombre[clave] = estadio
+	}
+	return porNombre, nil
+}
+
+// sembrarEquipos crea los equipos que faltan y actualiza los que cambiaron. Cada equipo queda
+// asociado al estadio registrado con el nombre de su estadio.
+func (s *SemillaService) sembrarEquipos(estadios map[string]models.Estadio, resultado *ResultadoSemilla) ([]models.Equipo, error) {
+	existentes, err := s.Equipos.Listar()
+	if err != nil {
+		return nil, err
+	}
+	porNombre := make(map[string]models.Equipo, len(existentes))
+	for _, equipo := range existentes {
+		porNombre[normalizarTexto(equipo.Nombre)] = equipo
+	}
+
+	equipos := make([]models.Equipo, 0, len(equiposSemilla))
+	for _, semilla := range equiposSemilla {
+		if estadio, ok := estadios[normalizarTexto(semilla.Estadio)]; ok {
+			semilla.EstadioID = &estadio.ID
+		}
+		equipo, ok := porNombre[normalizarTexto(semilla.Nombre)]
+		if !ok {
+			equipo = semilla
+			if err := s.Equipos.Crear(&equipo); err != nil {
+				return nil, fmt.Errorf("error al crear el equipo %s: %w", semilla.Nombre, err)
+			}
+			resultado.EquiposCreados++
+		} else if equipo.NombreCorto != semilla.NombreCorto || equipo.Ciudad != semilla.Ciudad ||
+			equipo.Estadio != semilla.Estadio || !mismoEstadio(equipo.EstadioID, semilla.EstadioID) ||
+			equipo.Fundacion != semilla.Fundacion || equipo.Escudo != semilla.Escudo {
+			equipo.NombreCorto = semilla.NombreCorto
+			equipo.Ciudad = semilla.Ciudad
+			equipo.Estadio = semilla.Estadio
+			equipo.EstadioID = semilla.EstadioID
+			equipo.Fundacion = semilla.Fundacion
+			equipo.Escudo = semilla.Escudo
+			if err := s.Equipos.Actualizar(&equipo); err != nil {
+				return nil, fmt.Errorf("error al actualizar el equipo %s: %w", semilla.Nombre, err)
+			}
+			resultado.EquiposActualizados++
+		}
+		equipos = append(equipos, equipo)
+	}
+	return equipos, nil
+}
+
+// mismoEstadio indica si dos referencias a estadios apuntan al mismo estadio o a ninguno
+func mismoEstadio(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// generadorSemilla devuelve un generador de números aleatorios que siempre da la misma
+// secuencia para la misma clave
+func generadorSemilla(clave string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(clave))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// plantillaSemilla genera la plantilla de un equipo, que siempre es la misma para el mismo equipo
+func plantillaSemilla(equipo models.Equipo) []models.Jugador {
+	r := generadorSemilla("plantilla|" + equipo.Nombre)
+	usados := make(map[string]bool)
+	jugadores := make([]models.Jugador, 0, jugadoresPorEquipo)
+	for _, grupo := range posicionesSemilla {
+		for i := 0; i < grupo.cantidad; i++ {
+			jugador := models.Jugador{
+				EquipoID:        equipo.ID,
+				FechaNacimiento: time.Date(1990+r.Intn(16), time.Month(1+r.Intn(12)), 1+r.Intn(28), 0, 0, 0, 0, time.UTC),
+				Nacionalidad:    "Colombia",
+				Posicion:        grupo.posicion,
+				Numero:          len(jugadores) + 1,
+				Altura:          float64(165+r.Intn(31)) / 100,
+				Peso:            float64(60 + r.Intn(31)),
+			}
+			for {
+				jugador.Nombre = nombresSemilla[r.Intn(len(nombresSemilla))]
+				jugador.Apellido = apellidosSemilla[r.Intn(len(apellidosSemilla))]
+				if !usados[jugador.Nombre+" "+jugador.Apellido] {
+					break
+				}
+			}
+			usados[jugador.Nombre+" "+jugador.Apellido] = true
+			jugadores = append(jugadores, jugador)
+		}
+	}
+	return jugadores
+}
+
+// sembrarJugadores crea las plantillas de los equipos y actualiza los jugadores que cambiaron
+func (s *SemillaService) sembrarJugadores(equipos []models.Equipo, resultado *ResultadoSemilla) error {
+	for _, equipo := range equipos {
+		existentes, err := s.Jugadores.ListarPorEquipo(equipo.ID)
+		if err != nil {
+			return err
+		}
+		porNombre := make(map[string]models.Jugador, len(existentes))
+		for _, jugador := range existentes {
+			porNombre[normalizarTexto(jugador.Nombre+" "+jugador.Apellido)] = jugador
+		}
+
+		for _, semilla := range plantillaSemilla(equipo) {
+			jugador, ok := porNombre[normalizarTexto(semilla.Nombre+" "+semilla.Apellido)]
+			if !ok {
+				jugador = semilla
+				if err := s.Jugadores.Crear(&jugador); err != nil {
+					return fmt.Errorf("error al crear el jugador %s %s: %w", semilla.Nombre, semilla.Apellido, err)
+				}
+				resultado.JugadoresCreados++
+			} else if jugador.Posicion != semilla.Posicion || jugador.Numero != semilla.Numero ||
+				!jugador.FechaNacimiento.Equal(semilla.FechaNacimiento) || jugador.Altura != semilla.Altura || jugador.Peso != semilla.Peso {
+				jugador.Posicion = semilla.Posicion
+				jugador.Numero = semilla.Numero
+				jugador.FechaNacimiento = semilla.FechaNacimiento
+				jugador.Altura = semilla.Altura
+				jugador.Peso = semilla.Peso
+				if err := s.Jugadores.Actualizar(&jugador); err != nil {
+					return fmt.Errorf("error al actualizar el jugador %s %s: %w", semilla.Nombre, semilla.Apellido, err)
+				}
+				resultado.JugadoresActualizados++
+			}
+		}
+	}
+	return nil
+}
+
+// sembrarCalendario genera las jornadas que faltan. La primera vez la temporada empieza de
+// modo que la mitad de las jornadas ya se jugaron; después se conserva la fecha de la jornada 1.
+func (s *SemillaService) sembrarCalendario(cantidadEquipos int, ahora time.Time, resultado *ResultadoSemilla) error {
+	calendario := NewCalendarioServiceConRepositorios(s.Repositorios)
+	opciones := OpcionesCalendario{}
+	existentes, err := s.Calendario.ListarJornadas()
+	if err != nil {
+		return err
+	}
+	if len(existentes) == 0 {
+		jornadas := 2 * (cantidadEquipos - 1 + cantidadEquipos%2)
+		inicio := proximoSabado(ahora).AddDate(0, 0, -7*(jornadas/2))
+		opciones.FechaInicio = &inicio
+	}
+
+	plan, err := calendario.GenerarCalendario(opciones)
+	if err != nil {
+		return err
+	}
+	resultado.JornadasCreadas = plan.Creadas
+	return nil
+}
+
+// semillaSimulacionDemo fija los resultados de la temporada de demostración
+const semillaSimulacionDemo = 2024
+
+// simularResultados juega con el simulador los partidos pendientes cuya fecha ya pasó. Con la
+// misma semilla los marcadores e incidencias son siempre los mismos para la misma jornada y
+// los mismos equipos.
+func (s *SemillaService) simularResultados(ahora time.Time, resultado *ResultadoSemilla) error {
+	simulador := NewSimuladorServiceConRepositorios(s.Repositorios)
+	simulacion, err := simulador.Simular(OpcionesSimulacion{Semilla: semillaSimulacionDemo, Hasta: &ahora})
+	if err != nil {
+		return err
+	}
+	resultado.ResultadosSimulados = simulacion.Partidos
+	return nil
+}
+
+// sembrarAsistencia completa el público de los partidos jugados que no lo tienen, entre el 40 %
+// y el 95 % de la capacidad de su estadio, siempre el mismo para el mismo partido
+func (s *SemillaService) sembrarAsistencia(equipos []models.Equipo, estadios map[string]models.Estadio) error {
+	capacidades := make(map[uint]int, len(estadios))
+	for _, estadio := range estadios {
+		capacidades[estadio.ID] = estadio.Capacidad
+	}
+	nombres := make(map[uint]string, len(equipos))
+	for _, equipo := range equipos {
+		nombres[equipo.ID] = equipo.Nombre
+	}
+	sedes := sedesEquipos(equipos)
+
+	jugados, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{Estado: models.EstadoFinalizado})
+	if err != nil {
+		return err
+	}
+	for _, partido := range jugados {
+		sede := estadioDePartido(partido, sedes)
+		if partido.Espectadores != nil || sede == nil || capacidades[*sede] == 0 {
+			continue
+		}
+		r := generadorSemilla(fmt.Sprintf("asistencia|%s|%s", nombres[partido.EquipoLocalID], nombres[partido.EquipoVisitanteID]))
+		espectadores := int(float64(capacidades[*sede]) * (0.4 + 0.55*r.Float64()))
+		partido.Espectadores = &espectadores
+		if err := guardarPartido(s.Calendario, &partido); err != nil {
+			return err
+		}
+	}
+	return nil
+}
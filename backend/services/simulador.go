@@ -0,0 +1,552 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/noisk8/torneas/backend/models"
+	"github.com/noisk8/torneas/backend/repositorios"
+	"gorm.io/gorm"
+)
+
+// Parámetros del modelo de simulación. Los goles de cada equipo siguen una distribución de
+// Poisson cuya media depende del ataque propio, la defensa rival y la ventaja de jugar en casa.
+const (
+	mediaGolesLocal          = 1.45
+	mediaGolesVisitante      = 1.10
+	mediaAmarillasPorEquipo  = 1.9
+	probabilidadPenal        = 0.08 // De que un gol sea de penal
+	probabilidadAutogol      = 0.03 // De que un gol sea en contra
+	probabilidadAsistencia   = 0.7  // De que un gol de jugada tenga asistencia
+	probabilidadPenalFallado = 0.05 // De que un equipo falle un penal en el partido
+	probabilidadRojaDirecta  = 0.03 // De que un equipo reciba una roja directa en el partido
+	cambiosMinimos           = 3
+	cambiosMaximos           = 5
+)
+
+// ErrSinPartidosSimulables indica que no hay partidos pendientes que simular
+var ErrSinPartidosSimulables = errors.New("no hay partidos pendientes; genere antes el calendario")
+
+// OpcionesSimulacion configura la simulación de una temporada
+type OpcionesSimulacion struct {
+	// Semilla determina los resultados: la misma semilla con los mismos equipos, jugadores y
+	// calendario produce siempre los mismos marcadores e incidencias
+	Semilla int64
+	// Hasta limita la simulación a los partidos anteriores a esta fecha y hora. Si es nil se
+	// simulan todos los partidos pendientes de la temporada.
+	Hasta *time.Time
+}
+
+// ResultadoSimulacion resume lo que registró la simulación
+type ResultadoSimulacion struct {
+	Semilla             int64 `json:"semilla"`
+	Partidos            int   `json:"partidos"`
+	Goles               int   `json:"goles"`
+	Incidencias         int   `json:"incidencias"`
+	JornadasCompletadas int   `json:"jornadasCompletadas"`
+}
+
+// SimuladorService simula resultados e incidencias verosímiles para demostraciones y pruebas
+// de carga. Registra todo con los mismos servicios que usan los operadores de los partidos.
+type SimuladorService struct {
+	repositorios.Repositorios
+}
+
+// NewSimuladorService crea una nueva instancia del simulador
+func NewSimuladorService(db *gorm.DB) *SimuladorService {
+	return NewSimuladorServiceConRepositorios(repositorios.NewGORM(db))
+}
+
+// NewSimuladorServiceConRepositorios crea el simulador sobre los repositorios indicados
+func NewSimuladorServiceConRepositorios(repos repositorios.Repositorios) *SimuladorService {
+	return &SimuladorService{
+		Repositorios: repos,
+	}
+}
+
+// Simular juega los partidos pendientes del calendario en orden de fecha: inicia cada
+// partido, registra sus incidencias minuto a minuto, fija el marcador y lo finaliza. Las
+// jornadas que quedan con todos sus partidos finalizados se dan por completadas. Cada partido
+// depende solo de la semilla, de su jornada y de sus equipos, así que el resultado no cambia
+// con los IDs de la base de datos ni con el orden en que se simulan los partidos.
+func (s *SimuladorService) Simular(opciones OpcionesSimulacion) (ResultadoSimulacion, error) {
+	resultado := ResultadoSimulacion{Semilla: opciones.Semilla}
+	calendario := NewCalendarioServiceConRepositorios(s.Repositorios)
+
+	filtro := repositorios.FiltroPartidos{Estado: models.EstadoPendiente, Hasta: opciones.Hasta}
+	pendientes, err := s.Calendario.BuscarPartidos(filtro)
+	if err != nil {
+		return resultado, err
+	}
+	if len(pendientes) == 0 {
+		// Con un límite de fecha puede que simplemente no haya partidos jugados todavía
+		if opciones.Hasta != nil {
+			return resultado, nil
+		}
+		return resultado, ErrSinPartidosSimulables
+	}
+
+	equipos, err := s.Equipos.Listar()
+	if err != nil {
+		return resultado, err
+	}
+	nombres := make(map[uint]string, len(equipos))
+	fuerzas := make(map[uint]fuerzaEquipo, len(equipos))
+	for _, equipo := range equipos {
+		nombres[equipo.ID] = equipo.Nombre
+		fuerzas[equipo.ID] = nuevaFuerzaEquipo(opciones.Semilla, equipo.Nombre)
+	}
+	plantillas, err := s.plantillas()
+	if err != nil {
+		return resultado, err
+	}
+	jornadas, err := s.Calendario.ListarJornadas()
+	if err != nil {
+		return resultado, err
+	}
+	porID := make(map[uint]models.Jornada, len(jornadas))
+	for _, jornada := range jornadas {
+		porID[jornada.ID] = jornada
+	}
+
+	for _, partido := range pendientes {
+		jornada := porID[partido.JornadaID]
+		r := generadorSemilla(fmt.Sprintf("simulacion|%d|%d|%s|%s", opciones.Semilla, jornada.Numero,
+			nombres[partido.EquipoLocalID], nombres[partido.EquipoVisitanteID]))
+		cronologia := simularCronologia(r, partido, fuerzas, plantillas)
+
+		if err := registrarCronologia(calendario, partido, cronologia); err != nil {
+			return resultado, fmt.Errorf("error al simular el partido %d: %w", partido.ID, err)
+		}
+		resultado.Partidos++
+		resultado.Goles += cronologia.golesLocal + cronologia.golesVisitante
+		resultado.Incidencias += len(cronologia.incidencias)
+	}
+
+	// Completar las jornadas que ya no tienen partidos por jugar
+	for _, jornada := range jornadas {
+		if jornada.Completada {
+			continue
+		}
+		partidos, err := s.Calendario.BuscarPartidos(repositorios.FiltroPartidos{JornadaID: jornada.ID})
+		if err != nil {
+			return resultado, err
+		}
+		completada := len(partidos) > 0
+		for _, partido := range partidos {
+			if partido.Estado != models.EstadoFinalizado {
+				completada = false
+				break
+			}
+		}
+		if completada {
+			jornada.Completada = true
+			if _, err := calendario.ActualizarJornada(jornada); err != nil {
+				return resultado, err
+			}
+			resultado.JornadasCompletadas++
+		}
+	}
+	return resultado, nil
+}
+
+// plantillas devuelve los jugadores de cada equipo en un orden que no depende de sus IDs
+func (s *SimuladorService) plantillas() (map[uint][]models.Jugador, error) {
+	jugadores, err := s.Jugadores.Listar()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(jugadores, func(i, j int) bool {
+		a, b := jugadores[i], jugadores[j]
+		if a.Numero != b.Numero {
+			return a.Numero < b.Numero
+		}
+		if a.Apellido != b.Apellido {
+			return a.Apellido < b.Apellido
+		}
+		return a.Nombre < b.Nombre
+	})
+	plantillas := make(map[uint][]models.Jugador)
+	for _, jugador := range jugadores {
+		plantillas[jugador.EquipoID] = append(plantillas[jugador.EquipoID], jugador)
+	}
+	return plantillas, nil
+}
+
+// fuerzaEquipo multiplica la media de goles que marca (ataque) y que recibe (defensa) un equipo
+type fuerzaEquipo struct {
+	ataque  float64
+	defensa float64
+}
+
+// nuevaFuerzaEquipo asigna a cada equipo, según la semilla, una fuerza entre 0,75 y 1,35
+func nuevaFuerzaEquipo(semilla int64, nombre string) fuerzaEquipo {
+	r := generadorSemilla(fmt.Sprintf("fuerza|%d|%s", semilla, nombre))
+	return fuerzaEquipo{
+		ataque:  0.75 + 0.6*r.Float64(),
+		defensa: 0.75 + 0.6*r.Float64(),
+	}
+}
+
+// poisson devuelve un valor de una distribución de Poisson con la media indicada
+func poisson(r *rand.Rand, media float64) int {
+	limite := math.Exp(-media)
+	n, producto := 0, r.Float64()
+	for producto > limite {
+		n++
+		producto *= r.Float64()
+	}
+	return n
+}
+
+// cronologiaPartido son las incidencias simuladas de un partido en orden de minuto
+type cronologiaPartido struct {
+	golesLocal     int
+	golesVisitante int
+	incidencias    []incidenciaSimulada
+}
+
+// incidenciaSimulada es una incidencia junto con el marcador que queda después de ella
+type incidenciaSimulada struct {
+	models.Incidencia
+	golesLocal     int
+	golesVisitante int
+}
+
+// eventoSimulado es una incidencia antes de saber qué jugador la protagoniza
+type eventoSimulado struct {
+	tipo          models.TipoIncidencia
+	local         bool // Equipo que protagoniza la incidencia
+	minuto        int
+	minutoAnadido int
+}
+
+// simularCronologia decide el marcador y las incidencias de un partido. Primero reparte los
+// eventos en el tiempo y después elige para cada uno un jugador que esté en el campo en ese minuto.
+func simularCronologia(r *rand.Rand, partido models.Partido, fuerzas map[uint]fuerzaEquipo, plantillas map[uint][]models.Jugador) cronologiaPartido {
+	local, visitante := fuerzas[partido.EquipoLocalID], fuerzas[partido.EquipoVisitanteID]
+	goles := [2]int{
+		poisson(r, mediaGolesLocal*local.ataque*visitante.defensa),
+		poisson(r, mediaGolesVisitante*visitante.ataque*local.defensa),
+	}
+
+	var eventos []eventoSimulado
+	for equipo, cantidad := range goles {
+		esLocal := equipo == 0
+		for i := 0; i < cantidad; i++ {
+			evento := eventoSimulado{tipo: models.Gol, local: esLocal}
+			evento.minuto, evento.minutoAnadido = minutoSimulado(r)
+			switch azar := r.Float64(); {
+			case azar < probabilidadAutogol:
+				// El autogol lo marca un jugador del rival
+				evento.tipo, evento.local = models.GolEnContra, !esLocal
+			case azar < probabilidadAutogol+probabilidadPenal:
+				evento.tipo = models.GolPenal
+			}
+			eventos = append(eventos, evento)
+		}
+		if r.Float64() < probabilidadPenalFallado {
+			evento := eventoSimulado{tipo: models.PenalFallado, local: esLocal}
+			evento.minuto, evento.minutoAnadido = minutoSimulado(r)
+			eventos = append(eventos, evento)
+		}
+		for i := poisson(r, mediaAmarillasPorEquipo); i > 0; i-- {
+			evento := eventoSimulado{tipo: models.TarjetaAmarilla, local: esLocal}
+			evento.minuto, evento.minutoAnadido = minutoSimulado(r)
+			eventos = append(eventos, evento)
+		}
+		if r.Float64() < probabilidadRojaDirecta {
+			evento := eventoSimulado{tipo: models.TarjetaRoja, local: esLocal}
+			evento.minuto, evento.minutoAnadido = minutoSimulado(r)
+			eventos = append(eventos, evento)
+		}
+		for i := cambiosMinimos + r.Intn(cambiosMaximos-cambiosMinimos+1); i > 0; i-- {
+			// Los cambios se hacen en el segundo tiempo
+			eventos = append(eventos, eventoSimulado{tipo: models.Sustitucion, local: esLocal, minuto: 46 + r.Intn(40)})
+		}
+	}
+	sort.SliceStable(eventos, func(i, j int) bool {
+		if eventos[i].minuto != eventos[j].minuto {
+			return eventos[i].minuto < eventos[j].minuto
+		}
+		return eventos[i].minutoAnadido < eventos[j].minutoAnadido
+	})
+
+	cronologia := cronologiaPartido{golesLocal: goles[0], golesVisitante: goles[1]}
+	campos := [2]*campoSimulado{
+		nuevoCampoSimulado(r, plantillas[partido.EquipoLocalID]),
+		nuevoCampoSimulado(r, plantillas[partido.EquipoVisitanteID]),
+	}
+	marcador := [2]int{}
+	for _, evento := range eventos {
+		equipo := 1
+		if evento.local {
+			equipo = 0
+		}
+		switch evento.tipo {
+		case models.Gol, models.GolPenal:
+			marcador[equipo]++
+		case models.GolEnContra:
+			marcador[1-equipo]++
+		}
+		for _, incidencia := range campos[equipo].protagonizar(r, evento) {
+			incidencia.PartidoID = partido.ID
+			incidencia.Minuto = evento.minuto
+			incidencia.MinutoAnadido = evento.minutoAnadido
+			incidencia.Timestamp = partido.FechaHora.Add(time.Duration(evento.minuto+evento.minutoAnadido) * time.Minute)
+			cronologia.incidencias = append(cronologia.incidencias, incidenciaSimulada{
+				Incidencia:     incidencia,
+				golesLocal:     marcador[0],
+				golesVisitante: marcador[1],
+			})
+		}
+	}
+	return cronologia
+}
+
+// minutoSimulado elige un minuto del partido; los últimos de cada tiempo pueden ser de tiempo añadido
+func minutoSimulado(r *rand.Rand) (int, int) {
+	minuto := 1 + r.Intn(2*models.DuracionPeriodo)
+	if (minuto == models.DuracionPeriodo || minuto == 2*models.DuracionPeriodo) && r.Intn(2) == 0 {
+		return minuto, 1 + r.Intn(4)
+	}
+	return minuto, 0
+}
+
+// campoSimulado sigue qué jugadores de un equipo están en el campo, sus tarjetas y los minutos
+// en los que ya protagonizaron una incidencia
+type campoSimulado struct {
+	enCampo   []models.Jugador
+	suplentes []models.Jugador
+	amarillas map[uint]bool
+	ocupados  map[instanteSimulado]map[uint]bool
+	instante  instanteSimulado // Minuto del evento que se está simulando
+}
+
+// instanteSimulado es un minuto del partido junto con su minuto de tiempo añadido
+type instanteSimulado struct {
+	minuto        int
+	minutoAnadido int
+}
+
+// alineacionSimulada son los jugadores por posición que salen de titulares
+var alineacionSimulada = map[string]int{"Portero": 1, "Defensa": 4, "Mediocampista": 4, "Delantero": 2}
+
+// nuevoCampoSimulado elige once titulares de la plantilla respetando, si se puede, las posiciones
+func nuevoCampoSimulado(r *rand.Rand, plantilla []models.Jugador) *campoSimulado {
+	campo := &campoSimulado{amarillas: make(map[uint]bool), ocupados: make(map[instanteSimulado]map[uint]bool)}
+	orden := r.Perm(len(plantilla))
+	cupos := make(map[string]int, len(alineacionSimulada))
+	for posicion, cantidad := range alineacionSimulada {
+		cupos[posicion] = cantidad
+	}
+	var resto []models.Jugador
+	for _, i := range orden {
+		jugador := plantilla[i]
+		if cupos[jugador.Posicion] > 0 {
+			cupos[jugador.Posicion]--
+			campo.enCampo = append(campo.enCampo, jugador)
+		} else {
+			resto = append(resto, jugador)
+		}
+	}
+	// Si faltan jugadores de alguna posición se completa el once con los demás
+	for len(campo.enCampo) < 11 && len(resto) > 0 {
+		campo.enCampo, resto = append(campo.enCampo, resto[0]), resto[1:]
+	}
+	campo.suplentes = resto
+	return campo
+}
+
+// pesoSimulado es la probabilidad relativa de que un jugador de cada posición marque o asista
+var pesoSimulado = map[string][2]int{ // {gol, asistencia}
+	"Portero":       {0, 0},
+	"Defensa":       {1, 2},
+	"Mediocampista": {3, 4},
+	"Delantero":     {6, 3},
+}
+
+// elegir devuelve la posición en el campo de un jugador elegido según su peso, o -1 si no hay
+// nadie. Con peso nil todos los jugadores de campo tienen la misma probabilidad. No se elige a
+// quien ya protagonizó otra incidencia en el mismo minuto, para que un jugador no marque dos
+// goles ni vea dos amarillas a la vez.
+func (c *campoSimulado) elegir(r *rand.Rand, peso func(models.Jugador) int, excluido uint) int {
+	total := 0
+	pesos := make([]int, len(c.enCampo))
+	for i, jugador := range c.enCampo {
+		if jugador.ID == excluido || c.ocupados[c.instante][jugador.ID] {
+			continue
+		}
+		pesos[i] = 1
+		if peso != nil {
+			pesos[i] = peso(jugador)
+		} else if jugador.Posicion == "Portero" {
+			pesos[i] = 0
+		}
+		total += pesos[i]
+	}
+	if total == 0 {
+		return -1
+	}
+	n := r.Intn(total)
+	for i, p := range pesos {
+		if n < p {
+			return i
+		}
+		n -= p
+	}
+	return -1
+}
+
+// protagonizar elige a los jugadores de un evento y devuelve sus incidencias: un gol puede
+// llevar una asistencia y una segunda amarilla lleva la roja. Sin jugadores no hay incidencias,
+// aunque el gol cuenta para el marcador.
+func (c *campoSimulado) protagonizar(r *rand.Rand, evento eventoSimulado) []models.Incidencia {
+	c.instante = instanteSimulado{evento.minuto, evento.minutoAnadido}
+	incidencias := c.incidenciasEvento(r, evento)
+	if c.ocupados[c.instante] == nil {
+		c.ocupados[c.instante] = make(map[uint]bool)
+	}
+	for _, incidencia := range incidencias {
+		c.ocupados[c.instante][incidencia.JugadorID] = true
+	}
+	return incidencias
+}
+
+// incidenciasEvento elige a los jugadores de un evento en el minuto actual del campo
+func (c *campoSimulado) incidenciasEvento(r *rand.Rand, evento eventoSimulado) []models.Incidencia {
+	golesPorPosicion := func(j models.Jugador) int { return pesoSimulado[j.Posicion][0] }
+	asistenciasPorPosicion := func(j models.Jugador) int { return pesoSimulado[j.Posicion][1] }
+
+	switch evento.tipo {
+	case models.Gol:
+		i := c.elegir(r, golesPorPosicion, 0)
+		if i < 0 {
+			return nil
+		}
+		goleador := c.enCampo[i]
+		incidencias := []models.Incidencia{{JugadorID: goleador.ID, Tipo: models.Gol}}
+		if r.Float64() < probabilidadAsistencia {
+			if j := c.elegir(r, asistenciasPorPosicion, goleador.ID); j >= 0 {
+				incidencias = append(incidencias, models.Incidencia{JugadorID: c.enCampo[j].ID, Tipo: models.Asistencia})
+			}
+		}
+		return incidencias
+
+	case models.GolPenal, models.PenalFallado:
+		i := c.elegir(r, golesPorPosicion, 0)
+		if i < 0 {
+			return nil
+		}
+		return []models.Incidencia{{JugadorID: c.enCampo[i].ID, Tipo: evento.tipo}}
+
+	case models.GolEnContra:
+		i := c.elegir(r, nil, 0)
+		if i < 0 {
+			return nil
+		}
+		return []models.Incidencia{{JugadorID: c.enCampo[i].ID, Tipo: models.GolEnContra}}
+
+	case models.TarjetaAmarilla:
+		i := c.elegir(r, nil, 0)
+		if i < 0 {
+			return nil
+		}
+		jugador := c.enCampo[i]
+		incidencias := []models.Incidencia{{JugadorID: jugador.ID, Tipo: models.TarjetaAmarilla}}
+		if c.amarillas[jugador.ID] {
+			incidencias = append(incidencias, models.Incidencia{JugadorID: jugador.ID, Tipo: models.TarjetaRoja, Descripcion: "Doble amarilla"})
+			c.expulsar(i)
+		}
+		c.amarillas[jugador.ID] = true
+		return incidencias
+
+	case models.TarjetaRoja:
+		i := c.elegir(r, nil, 0)
+		if i < 0 {
+			return nil
+		}
+		jugador := c.enCampo[i]
+		c.expulsar(i)
+		return []models.Incidencia{{JugadorID: jugador.ID, Tipo: models.TarjetaRoja}}
+
+	case models.Sustitucion:
+		i := c.elegir(r, nil, 0)
+		if i < 0 || len(c.suplentes) == 0 {
+			return nil
+		}
+		// La sustitución se registra sobre el jugador que sale, y entra un suplente de la misma posición si lo hay
+		sale := c.enCampo[i]
+		entra := 0
+		for j, suplente := range c.suplentes {
+			if suplente.Posicion == sale.Posicion {
+				entra = j
+				break
+			}
+		}
+		c.enCampo[i] = c.suplentes[entra]
+		c.suplentes = append(c.suplentes[:entra], c.suplentes[entra+1:]...)
+		descripcion := fmt.Sprintf("Entra %s %s", c.enCampo[i].Nombre, c.enCampo[i].Apellido)
+		return []models.Incidencia{{JugadorID: sale.ID, JugadorEntraID: &c.enCampo[i].ID, Tipo: models.Sustitucion, Descripcion: descripcion}}
+	}
+	return nil
+}
+
+// expulsar saca del campo al jugador en la posición indicada
+func (c *campoSimulado) expulsar(i int) {
+	c.enCampo = append(c.enCampo[:i], c.enCampo[i+1:]...)
+}
+
+// registrarCronologia juega un partido con los servicios del calendario, como lo harían los
+// operadores: inicia y termina cada tiempo, registra las incidencias y actualiza el marcador
+// después de cada gol
+func registrarCronologia(calendario *CalendarioService, partido models.Partido, cronologia cronologiaPartido) error {
+	if _, err := calendario.IniciarPeriodo(partido.ID); err != nil {
+		return err
+	}
+	segundoTiempo := false
+	golesLocal, golesVisitante := 0, 0
+	for _, incidencia := range cronologia.incidencias {
+		if !segundoTiempo && incidencia.Minuto > models.DuracionPeriodo {
+			if err := jugarDescanso(calendario, partido.ID); err != nil {
+				return err
+			}
+			segundoTiempo = true
+		}
+		if _, err := calendario.RegistrarIncidencia(incidencia.Incidencia); err != nil {
+			return err
+		}
+		if incidencia.golesLocal != golesLocal || incidencia.golesVisitante != golesVisitante {
+			golesLocal, golesVisitante = incidencia.golesLocal, incidencia.golesVisitante
+			if _, err := calendario.ActualizarMarcador(partido.ID, nil, golesLocal, golesVisitante); err != nil {
+				return err
+			}
+		}
+	}
+	if !segundoTiempo {
+		if err := jugarDescanso(calendario, partido.ID); err != nil {
+			return err
+		}
+	}
+	// Los goles sin jugador que los protagonice no tienen incidencia, pero cuentan en el marcador
+	if cronologia.golesLocal != golesLocal || cronologia.golesVisitante != golesVisitante {
+		if _, err := calendario.ActualizarMarcador(partido.ID, nil, cronologia.golesLocal, cronologia.golesVisitante); err != nil {
+			return err
+		}
+	}
+	_, err := calendario.FinalizarPeriodo(partido.ID)
+	return err
+}
+
+// jugarDescanso termina el primer tiempo e inicia el segundo
+func jugarDescanso(calendario *CalendarioService, partidoID uint) error {
+	if _, err := calendario.FinalizarPeriodo(partidoID); err != nil {
+		return err
+	}
+	_, err := calendario.IniciarPeriodo(partidoID)
+	return err
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/noisk8/torneas/backend/models"
+)
+
+// plantillaSimulada arma una plantilla de 18 jugadores con las posiciones de la alineación
+func plantillaSimulada(equipoID uint) []models.Jugador {
+	posiciones := []string{"Portero", "Portero", "Defensa", "Defensa", "Defensa", "Defensa", "Defensa", "Defensa",
+		"Mediocampista", "Mediocampista", "Mediocampista", "Mediocampista", "Mediocampista", "Mediocampista",
+		"Delantero", "Delantero", "Delantero", "Delantero"}
+	plantilla := make([]models.Jugador, len(posiciones))
+	for i, posicion := range posiciones {
+		plantilla[i] = models.Jugador{
+			ID:       equipoID*100 + uint(i) + 1,
+			Nombre:   fmt.Sprintf("Jugador %d", i+1),
+			Posicion: posicion,
+			Numero:   i + 1,
+			EquipoID: equipoID,
+		}
+	}
+	return plantilla
+}
+
+func TestSimularCronologiaMinutosDistintos(t *testing.T) {
+	partido := models.Partido{EquipoLocalID: 1, EquipoVisitanteID: 2}
+	partido.ID = 1
+	// Equipos muy ofensivos para que haya muchos goles y tarjetas en pocos minutos
+	fuerzas := map[uint]fuerzaEquipo{1: {ataque: 3, defensa: 3}, 2: {ataque: 3, defensa: 3}}
+	plantillas := map[uint][]models.Jugador{1: plantillaSimulada(1), 2: plantillaSimulada(2)}
+
+	for semilla := int64(1); semilla <= 500; semilla++ {
+		cronologia := simularCronologia(rand.New(rand.NewSource(semilla)), partido, fuerzas, plantillas)
+
+		type clave struct {
+			jugador       uint
+			minuto        int
+			minutoAnadido int
+		}
+		vistas := make(map[clave]models.TipoIncidencia)
+		for _, incidencia := range cronologia.incidencias {
+			if incidencia.Tipo == models.Sustitucion {
+				continue
+			}
+			c := clave{incidencia.JugadorID, incidencia.Minuto, incidencia.MinutoAnadido}
+			anterior, repetida := vistas[c]
+			// Solo la roja por doble amarilla comparte minuto con la amarilla que la provoca
+			dobleAmarilla := incidencia.Tipo == models.TarjetaRoja && incidencia.Descripcion == "Doble amarilla" &&
+				anterior == models.TarjetaAmarilla
+			if repetida && !dobleAmarilla {
+				t.Fatalf("semilla %d: el jugador %d tiene %s y %s en el minuto %d+%d",
+					semilla, c.jugador, anterior, incidencia.Tipo, c.minuto, c.minutoAnadido)
+			}
+			vistas[c] = incidencia.Tipo
+		}
+	}
+}
+
+func TestSimularCronologiaDeterminista(t *testing.T) {
+	partido := models.Partido{EquipoLocalID: 1, EquipoVisitanteID: 2}
+	partido.ID = 1
+	fuerzas := map[uint]fuerzaEquipo{1: nuevaFuerzaEquipo(7, "Local"), 2: nuevaFuerzaEquipo(7, "Visitante")}
+	plantillas := map[uint][]models.Jugador{1: plantillaSimulada(1), 2: plantillaSimulada(2)}
+
+	primera := simularCronologia(rand.New(rand.NewSource(7)), partido, fuerzas, plantillas)
+	segunda := simularCronologia(rand.New(rand.NewSource(7)), partido, fuerzas, plantillas)
+	if !reflect.DeepEqual(primera, segunda) {
+		t.Error("la misma semilla produjo cronologías distintas")
+	}
+}
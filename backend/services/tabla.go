@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// tamanoMaximoTabla es el tamaño máximo de un archivo de importación
+const tamanoMaximoTabla = 10 << 20
+
+// ErrFormatoTabla indica que el archivo no es un CSV o un XLSX que se pueda leer
+var ErrFormatoTabla = errors.New("el archivo no es un CSV o XLSX válido")
+
+// Fila es una fila de una hoja de cálculo con el número de línea que ve el usuario
+type Fila struct {
+	Linea  int
+	Celdas []string
+}
+
+// LeerTabla lee las filas de un archivo CSV o XLSX, según la extensión del nombre o, si no
+// la tiene, según el contenido. Las filas vacías se omiten. De un XLSX se lee la primera hoja.
+func LeerTabla(nombre string, r io.Reader) ([]Fila, error) {
+	datos, err := io.ReadAll(io.LimitReader(r, tamanoMaximoTabla+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(datos) > tamanoMaximoTabla {
+		return nil, fmt.Errorf("%w: supera los %d MB", ErrFormatoTabla, tamanoMaximoTabla>>20)
+	}
+
+	switch strings.ToLower(filepath.Ext(nombre)) {
+	case ".xlsx":
+		return leerXLSX(datos)
+	case ".csv", ".txt":
+		return leerCSV(datos)
+	}
+	// Un XLSX es un archivo ZIP, que empieza con "PK"
+	if bytes.HasPrefix(datos, []byte("PK\x03\x04")) {
+		return leerXLSX(datos)
+	}
+	return leerCSV(datos)
+}
+
+// leerCSV lee un CSV separado por comas o, como lo exporta Excel en español, por punto y coma
+func leerCSV(datos []byte) ([]Fila, error) {
+	datos = bytes.TrimPrefix(datos, []byte("\xef\xbb\xbf"))
+
+	lector := csv.NewReader(bytes.NewReader(datos))
+	lector.FieldsPerRecord = -1
+	primera, _, _ := bytes.Cut(datos, []byte("\n"))
+	if bytes.Count(primera, []byte(";")) > bytes.Count(primera, []byte(",")) {
+		lector.Comma = ';'
+	}
+
+	var filas []Fila
+	for {
+		celdas, err := lector.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFormatoTabla, err)
+		}
+		linea, _ := lector.FieldPos(0)
+		if filaVacia(celdas) {
+			continue
+		}
+		filas = append(filas, Fila{Linea: linea, Celdas: celdas})
+	}
+	return filas, nil
+}
+
+// filaVacia indica si todas las celdas de una fila están en blanco
+func filaVacia(celdas []string) bool {
+	for _, celda := range celdas {
+		if strings.TrimSpace(celda) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,6 @@
+package services
+
+import "github.com/noisk8/torneas/backend/repositorios"
+
+// ErrVersionObsoleta indica que el registro cambió desde la versión que conocía quien lo modifica
+var ErrVersionObsoleta = repositorios.ErrVersionObsoleta
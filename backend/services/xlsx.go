@@ -0,0 +1,198 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// columnasMaximasXLSX es la cantidad de columnas de una hoja de Excel, de la A a la XFD
+	columnasMaximasXLSX = 16384
+	// celdasMaximasXLSX limita las celdas que se reservan al leer una hoja, contando las
+	// vacías que quedan a la izquierda de cada valor
+	celdasMaximasXLSX = 4 << 20
+)
+
+// Estructuras mínimas de Office Open XML para leer los valores de la primera hoja de un XLSX
+
+type xlsxLibro struct {
+	Hojas []struct {
+		RelacionID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelaciones struct {
+	Relaciones []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxTextos struct {
+	Textos []xlsxTexto `xml:"si"`
+}
+
+// xlsxTexto es un texto simple o con formato, que se guarda en varios fragmentos
+type xlsxTexto struct {
+	T          string `xml:"t"`
+	Fragmentos []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxTexto) String() string {
+	if len(t.Fragmentos) == 0 {
+		return t.T
+	}
+	var texto strings.Builder
+	for _, fragmento := range t.Fragmentos {
+		texto.WriteString(fragmento.T)
+	}
+	return texto.String()
+}
+
+type xlsxHoja struct {
+	Filas []struct {
+		Numero int `xml:"r,attr"`
+		Celdas []struct {
+			Referencia string    `xml:"r,attr"`
+			Tipo       string    `xml:"t,attr"`
+			Valor      string    `xml:"v"`
+			Texto      xlsxTexto `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// leerXLSX lee los valores de la primera hoja de un libro de Excel. Las fechas se devuelven
+// como el número de serie con el que Excel las guarda.
+func leerXLSX(datos []byte) ([]Fila, error) {
+	archivo, err := zip.NewReader(bytes.NewReader(datos), int64(len(datos)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormatoTabla, err)
+	}
+
+	var libro xlsxLibro
+	if err := leerXMLDeZIP(archivo, "xl/workbook.xml", &libro); err != nil {
+		return nil, err
+	}
+	var relaciones xlsxRelaciones
+	if err := leerXMLDeZIP(archivo, "xl/_rels/workbook.xml.rels", &relaciones); err != nil {
+		return nil, err
+	}
+	if len(libro.Hojas) == 0 {
+		return nil, fmt.Errorf("%w: el libro no tiene hojas", ErrFormatoTabla)
+	}
+	rutaHoja := ""
+	for _, relacion := range relaciones.Relaciones {
+		if relacion.ID == libro.Hojas[0].RelacionID {
+			rutaHoja = relacion.Target
+		}
+	}
+	if strings.HasPrefix(rutaHoja, "/") {
+		rutaHoja = strings.TrimPrefix(rutaHoja, "/")
+	} else {
+		rutaHoja = path.Join("xl", rutaHoja)
+	}
+
+	// Los textos repetidos se guardan una sola vez; un libro sin textos no tiene este archivo
+	var textos xlsxTextos
+	if _, err := fs.Stat(archivo, "xl/sharedStrings.xml"); err == nil {
+		if err := leerXMLDeZIP(archivo, "xl/sharedStrings.xml", &textos); err != nil {
+			return nil, err
+		}
+	}
+
+	var hoja xlsxHoja
+	if err := leerXMLDeZIP(archivo, rutaHoja, &hoja); err != nil {
+		return nil, err
+	}
+
+	var filas []Fila
+	celdas := 0
+	for i, filaXML := range hoja.Filas {
+		fila := Fila{Linea: filaXML.Numero}
+		if fila.Linea == 0 {
+			fila.Linea = i + 1
+		}
+		for j, celda := range filaXML.Celdas {
+			columna := j
+			if celda.Referencia != "" {
+				if columna, err = columnaXLSX(celda.Referencia); err != nil {
+					return nil, err
+				}
+			}
+			if columna >= len(fila.Celdas) {
+				celdas += columna + 1 - len(fila.Celdas)
+				if celdas > celdasMaximasXLSX {
+					return nil, fmt.Errorf("%w: la hoja tiene demasiadas celdas", ErrFormatoTabla)
+				}
+			}
+			for len(fila.Celdas) <= columna {
+				fila.Celdas = append(fila.Celdas, "")
+			}
+
+			valor := celda.Valor
+			switch celda.Tipo {
+			case "s":
+				indice, err := strconv.Atoi(valor)
+				if err != nil || indice < 0 || indice >= len(textos.Textos) {
+					return nil, fmt.Errorf("%w: texto compartido inválido en %s", ErrFormatoTabla, celda.Referencia)
+				}
+				valor = textos.Textos[indice].String()
+			case "inlineStr":
+				valor = celda.Texto.String()
+			case "b":
+				valor = strconv.FormatBool(valor == "1")
+			case "e":
+				valor = ""
+			}
+			fila.Celdas[columna] = valor
+		}
+		if !filaVacia(fila.Celdas) {
+			filas = append(filas, fila)
+		}
+	}
+	return filas, nil
+}
+
+// leerXMLDeZIP decodifica un archivo XML dentro del XLSX
+func leerXMLDeZIP(archivo *zip.Reader, nombre string, destino interface{}) error {
+	f, err := archivo.Open(nombre)
+	if err != nil {
+		return fmt.Errorf("%w: falta %s", ErrFormatoTabla, nombre)
+	}
+	defer f.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(f, tamanoMaximoTabla*10)).Decode(destino); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrFormatoTabla, nombre, err)
+	}
+	return nil
+}
+
+// columnaXLSX convierte la referencia de una celda, como "AB12", en el índice de su columna
+// desde 0. Las letras pueden estar en minúsculas; una referencia sin letras o con una columna
+// después de XFD no es válida.
+func columnaXLSX(referencia string) (int, error) {
+	columna, letras := 0, 0
+	for _, letra := range strings.ToUpper(referencia) {
+		if letra < 'A' || letra > 'Z' {
+			break
+		}
+		columna = columna*26 + int(letra-'A'+1)
+		letras++
+		if columna > columnasMaximasXLSX {
+			return 0, fmt.Errorf("%w: la celda %s está fuera de la hoja", ErrFormatoTabla, referencia)
+		}
+	}
+	if letras == 0 {
+		return 0, fmt.Errorf("%w: referencia de celda inválida %q", ErrFormatoTabla, referencia)
+	}
+	return columna - 1, nil
+}
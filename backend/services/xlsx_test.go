@@ -0,0 +1,103 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// libroXLSX arma un XLSX mínimo cuya primera hoja tiene las filas XML indicadas
+func libroXLSX(t *testing.T, filas string) []byte {
+	t.Helper()
+	archivos := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+			xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Hoja1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>nombre</t></si><si><r><t>Atlético </t></r><r><t>Nacional</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` + filas + `</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for nombre, contenido := range archivos {
+		w, err := zw.Create(nombre)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contenido)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestColumnaXLSX(t *testing.T) {
+	casos := []struct {
+		referencia string
+		columna    int
+		valida     bool
+	}{
+		{"A1", 0, true},
+		{"Z9", 25, true},
+		{"AA10", 26, true},
+		{"ab12", 27, true},
+		{"XFD1", 16383, true},
+		{"XFE1", 0, false},
+		{"ZZZZZZZ1", 0, false},
+		{"1", 0, false},
+		{"", 0, false},
+	}
+	for _, caso := range casos {
+		columna, err := columnaXLSX(caso.referencia)
+		if caso.valida {
+			if err != nil || columna != caso.columna {
+				t.Errorf("columnaXLSX(%q) = %d, %v; se esperaba %d", caso.referencia, columna, err, caso.columna)
+			}
+		} else if !errors.Is(err, ErrFormatoTabla) {
+			t.Errorf("columnaXLSX(%q) = %d, %v; se esperaba ErrFormatoTabla", caso.referencia, columna, err)
+		}
+	}
+}
+
+func TestLeerXLSX(t *testing.T) {
+	datos := libroXLSX(t, `
+		<row r="1"><c r="A1" t="s"><v>0</v></c><c r="c1" t="inlineStr"><is><t>ciudad</t></is></c></row>
+		<row r="2"></row>
+		<row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3"><v>1947</v></c><c r="C3" t="b"><v>1</v></c></row>
+		<row r="4"><c><v>x</v></c><c><v>y</v></c></row>`)
+
+	filas, err := LeerTabla("equipos.xlsx", bytes.NewReader(datos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperadas := []Fila{
+		{Linea: 1, Celdas: []string{"nombre", "", "ciudad"}},
+		{Linea: 3, Celdas: []string{"Atlético Nacional", "1947", "true"}},
+		{Linea: 4, Celdas: []string{"x", "y"}},
+	}
+	if !reflect.DeepEqual(filas, esperadas) {
+		t.Errorf("filas = %#v; se esperaba %#v", filas, esperadas)
+	}
+}
+
+func TestLeerXLSXReferenciasInvalidas(t *testing.T) {
+	casos := map[string]string{
+		"sin letras":          `<row r="1"><c r="1"><v>1</v></c></row>`,
+		"después de XFD":      `<row r="1"><c r="ZZZZZZZ1"><v>1</v></c></row>`,
+		"texto inexistente":   `<row r="1"><c r="A1" t="s"><v>7</v></c></row>`,
+		"demasiadas columnas": strings.Repeat(`<row><c r="XFD1"><v>1</v></c></row>`, celdasMaximasXLSX/columnasMaximasXLSX+1),
+	}
+	for nombre, filas := range casos {
+		if _, err := leerXLSX(libroXLSX(t, filas)); !errors.Is(err, ErrFormatoTabla) {
+			t.Errorf("%s: error = %v; se esperaba ErrFormatoTabla", nombre, err)
+		}
+	}
+}
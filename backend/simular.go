@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/noisk8/torneas/backend/config"
+	"github.com/noisk8/torneas/backend/database"
+	"github.com/noisk8/torneas/backend/services"
+)
+
+const usoSimular = `Uso: go run . simular [--semilla N] [--hasta AAAA-MM-DD]
+
+Juega los partidos pendientes del calendario con resultados e incidencias verosímiles (goles,
+asistencias, tarjetas y cambios) registrados con los mismos servicios que usan los operadores.
+La misma semilla sobre los mismos datos produce siempre la misma temporada. Sirve para
+demostraciones y pruebas de carga, así que no se permite con APP_ENV=production.
+
+Opciones:
+  --semilla N          Semilla de la simulación (por defecto 1)
+  --hasta AAAA-MM-DD   Solo los partidos anteriores a esa fecha (por defecto toda la temporada)`
+
+// ejecutarSimular atiende el subcomando simular y devuelve el código de salida del proceso
+func ejecutarSimular(cfg *config.Config, args []string) int {
+	opciones := flag.NewFlagSet("simular", flag.ContinueOnError)
+	opciones.SetOutput(io.Discard)
+	semilla := opciones.Int64("semilla", 1, "")
+	hasta := opciones.String("hasta", "", "")
+	if err := opciones.Parse(args); err != nil || opciones.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, usoSimular)
+		return 2
+	}
+
+	simulacion := services.OpcionesSimulacion{Semilla: *semilla}
+	if *hasta != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", *hasta, time.Local)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, usoSimular)
+			return 2
+		}
+		simulacion.Hasta = &fecha
+	}
+	if cfg.EsProduccion() {
+		fmt.Fprintln(os.Stderr, "No se permite simular resultados con APP_ENV=production")
+		return 1
+	}
+
+	db, err := database.Conectar(cfg.BaseDatos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	inicio := time.Now()
+	service := services.NewSimuladorService(db)
+	resultado, err := service.Simular(simulacion)
+	if errors.Is(err, services.ErrSinPartidosSimulables) {
+		fmt.Fprintln(os.Stderr, "No hay partidos pendientes; genere antes el calendario o use go run . seed demo")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al simular la temporada: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Semilla %d: %d partidos, %d goles, %d incidencias\n", resultado.Semilla, resultado.Partidos, resultado.Goles, resultado.Incidencias)
+	fmt.Printf("Jornadas completadas: %d\n", resultado.JornadasCompletadas)
+	fmt.Printf("Tiempo: %s\n", time.Since(inicio).Round(time.Millisecond))
+	return 0
+}